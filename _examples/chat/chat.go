@@ -196,7 +196,10 @@ func (app *ChatApp) handleSubmit(value string) {
 }
 
 func (app *ChatApp) setupEditor() {
-	app.editor = components.NewEditor(app.term, app.handleSubmit)
+	app.editor = components.NewEditor(app.term, app.handleSubmit,
+		components.WithEditorAsyncAutocomplete(app.tui),
+		components.WithEditorExternalEditor(app.tui),
+	)
 
 	commands := []any{
 		components.SlashCommand{
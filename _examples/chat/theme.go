@@ -1,6 +1,9 @@
 package main
 
-import "github.com/yeeaiclub/fasttui/components"
+import (
+	"github.com/yeeaiclub/fasttui/components"
+	"github.com/yeeaiclub/fasttui/style"
+)
 
 const (
 	ansiReset = "\x1b[0m"
@@ -35,6 +38,9 @@ func ThemeFg(colorName string, text string) string {
 }
 
 func CreateDefaultMarkdownTheme() *components.MarkdownTheme {
+	if theme, err := style.LoadTheme(style.DefaultThemeName()); err == nil {
+		return components.MarkdownThemeFrom(theme)
+	}
 	return &components.MarkdownTheme{
 		Heading:         bold,
 		Link:            cyan,
@@ -58,7 +58,10 @@ func main() {
 	tui.AddChild(components.NewDynamicBorder(components.WithBorderColor(func(s string) string { return s })))
 
 	// Create select list
-	selectList := components.NewSelectList(items, 8, components.WithSelectListTheme(theme))
+	selectList := components.NewSelectList(items, 8,
+		components.WithSelectListTheme(theme),
+		components.WithSelectListFilter("> "),
+	)
 
 	// Set up callbacks
 	selectList.SetOnSelect(func(item components.SelectItem) {
@@ -85,7 +88,7 @@ func main() {
 	tui.AddChild(components.NewDynamicBorder(components.WithBorderColor(func(s string) string { return s })))
 
 	// Add help text at bottom
-	helpText := components.NewText("↑/↓: Navigate | Enter: Select | Esc: Cancel", 1, 0)
+	helpText := components.NewText("Type to filter | ↑/↓: Navigate | Enter: Select | Esc: Cancel", 1, 0)
 	tui.AddChild(helpText)
 
 	// Start TUI
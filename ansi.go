@@ -3,10 +3,16 @@ package fasttui
 import "strings"
 
 const (
-	CursorMarker = "\x1b_pi:c\x07"
-	SegmentReset = "\x1b[0m\x1b]8;;\x07"
+	CursorMarker   = "\x1b_pi:c\x07"
+	SegmentReset   = "\x1b[0m\x1b]8;;\x07"
+	HyperlinkClose = "\x1b]8;;\x07"
 )
 
+// Hyperlink wraps text in an OSC 8 hyperlink to url.
+func Hyperlink(text, url string) string {
+	return "\x1b]8;;" + url + "\x07" + text + HyperlinkClose
+}
+
 func GetCursorMarker() string {
 	return CursorMarker
 }
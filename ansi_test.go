@@ -242,3 +242,42 @@ func TestStripAnsi(t *testing.T) {
 		})
 	}
 }
+
+func TestAnsiCodeTrackerHyperlinks(t *testing.T) {
+	tracker := NewAnsiCodeTracker()
+	tracker.Process("\x1b]8;id=1;https://example.com\x1b\\")
+	tracker.Process("\x1b[1m")
+	assert.Equal(t, "\x1b[1m\x1b]8;id=1;https://example.com\x1b\\", tracker.GetActiveCodes())
+	assert.Equal(t, HyperlinkClose, tracker.GetLineEndReset())
+
+	// SGR reset leaves the link open.
+	tracker.Process("\x1b[0m")
+	assert.True(t, tracker.HasActiveCodes())
+	assert.Equal(t, "\x1b]8;id=1;https://example.com\x1b\\", tracker.GetActiveCodes())
+
+	tracker.Process(HyperlinkClose)
+	assert.False(t, tracker.HasActiveCodes())
+	assert.Equal(t, "", tracker.GetLineEndReset())
+}
+
+func TestAnsiCodeTrackerExtendedSGR(t *testing.T) {
+	tracker := NewAnsiCodeTracker()
+	tracker.Process("\x1b[4:3;58:2::255:0:0;53m")
+	assert.Equal(t, "\x1b[4:3;53;58;2;255;0;0m", tracker.GetActiveCodes())
+	assert.Equal(t, "\x1b[24;55m", tracker.GetLineEndReset())
+
+	tracker.Process("\x1b[38:5:208;48:2:1:2:3m")
+	assert.Equal(t, "\x1b[4:3;53;38;5;208;48;2;1;2;3;58;2;255;0;0m", tracker.GetActiveCodes())
+
+	// 4:0 and 24 end any underline style; 59 drops the underline color.
+	tracker.Process("\x1b[4:0;55;59;39;49m")
+	assert.False(t, tracker.HasActiveCodes())
+
+	// 21 is double underline, not bold off.
+	tracker.Process("\x1b[1;21m")
+	assert.Equal(t, "\x1b[1;4:2m", tracker.GetActiveCodes())
+	tracker.Process("\x1b[4m")
+	assert.Equal(t, "\x1b[1;4m", tracker.GetActiveCodes())
+	tracker.Process("\x1b[22;24m")
+	assert.False(t, tracker.HasActiveCodes())
+}
@@ -1,6 +1,7 @@
 package fasttui
 
 import (
+	"cmp"
 	"strconv"
 	"strings"
 )
@@ -15,18 +16,31 @@ import (
 //	tracker.Process("\x1b[1;31m")  // bold + red foreground
 //	codes := tracker.GetActiveCodes()  // returns "\x1b[1;31m"
 type AnsiCodeTracker struct {
-	bold          bool
-	dim           bool
-	italic        bool
-	underline     bool
-	blink         bool
-	inverse       bool
-	hidden        bool
-	strikethrough bool
-	fgColor       string
-	bgColor       string
+	bold           bool
+	dim            bool
+	italic         bool
+	underline      int // SGR 4:n style: 0 none, 1 single, 2 double, 3 curly, 4 dotted, 5 dashed
+	blink          bool
+	inverse        bool
+	hidden         bool
+	strikethrough  bool
+	overline       bool
+	fgColor        string
+	bgColor        string
+	underlineColor string // "58;5;n" or "58;2;r;g;b"
+	hyperlink      string // OSC 8 sequence that opened the current link
 }
 
+// Underline styles set with SGR 4:n.
+const (
+	underlineNone = iota
+	underlineSingle
+	underlineDouble
+	underlineCurly
+	underlineDotted
+	underlineDashed
+)
+
 // NewAnsiCodeTracker creates a new ANSI code tracker with all formatting disabled.
 //
 // Example:
@@ -41,15 +55,23 @@ func NewAnsiCodeTracker() *AnsiCodeTracker {
 //
 // Supported codes:
 //   - 0: Reset all attributes
-//   - 1: Bold, 2: Dim, 3: Italic, 4: Underline, 5: Blink
-//   - 7: Inverse, 8: Hidden, 9: Strikethrough
-//   - 21-29: Turn off corresponding attributes
+//   - 1: Bold, 2: Dim, 3: Italic, 4: Underline, 5 and 6: Blink
+//   - 7: Inverse, 8: Hidden, 9: Strikethrough, 53: Overline
+//   - 4:0 to 4:5: No, single, double, curly, dotted and dashed underline
+//   - 21: Double underline (not bold off, as in current terminals)
+//   - 22-29, 55: Turn off corresponding attributes
 //   - 30-37, 90-97: Foreground colors
 //   - 40-47, 100-107: Background colors
-//   - 38;5;n: 256-color foreground
-//   - 48;5;n: 256-color background
-//   - 38;2;r;g;b: RGB foreground
-//   - 48;2;r;g;b: RGB background
+//   - 38;5;n, 48;5;n, 58;5;n: 256-color foreground, background, underline
+//   - 38;2;r;g;b, 48;2;r;g;b, 58;2;r;g;b: RGB foreground, background, underline
+//   - 59: Default underline color
+//
+// Colors may also use colon sub-parameters (38:5:n, 38:2::r:g:b); they are
+// stored, and restored, in the semicolon form.
+//
+// OSC 8 hyperlinks are tracked too: "\x1b]8;;url\x07" opens a link and
+// "\x1b]8;;\x07" closes it. SGR reset leaves an open link alone, as
+// terminals do.
 //
 // Example:
 //
@@ -57,6 +79,11 @@ func NewAnsiCodeTracker() *AnsiCodeTracker {
 //	tracker.Process("\x1b[38;5;208m") // 256-color orange
 //	tracker.Process("\x1b[0m")        // reset all
 func (t *AnsiCodeTracker) Process(ansiCode string) {
+	if strings.HasPrefix(ansiCode, "\x1b]8;") {
+		t.processHyperlink(ansiCode)
+		return
+	}
+
 	// Validate ANSI code format
 	if len(ansiCode) < 3 || ansiCode[len(ansiCode)-1] != 'm' {
 		return
@@ -65,12 +92,16 @@ func (t *AnsiCodeTracker) Process(ansiCode string) {
 	// Extract parameters between ESC[ and m
 	params := ansiCode[2 : len(ansiCode)-1]
 	if params == "" || params == "0" {
-		t.Reset()
+		t.resetSGR()
 		return
 	}
 
 	parts := strings.Split(params, ";")
 	for i := 0; i < len(parts); i++ {
+		if strings.Contains(parts[i], ":") {
+			t.processSubParams(strings.Split(parts[i], ":"))
+			continue
+		}
 		code, err := strconv.Atoi(parts[i])
 		if err != nil {
 			continue // Skip invalid codes
@@ -90,42 +121,88 @@ func (t *AnsiCodeTracker) Process(ansiCode string) {
 // processExtendedColor handles 256-color and RGB color codes.
 // Returns the number of parts consumed (0 if not an extended color code).
 func (t *AnsiCodeTracker) processExtendedColor(code int, parts []string, index int) int {
-	if code != 38 && code != 48 {
+	if code != 38 && code != 48 && code != 58 {
 		return 0
 	}
 
-	isForeground := code == 38
-
-	// 256-color: 38;5;n or 48;5;n
+	// 256-color: 38;5;n, 48;5;n or 58;5;n
 	if index+2 < len(parts) && parts[index+1] == "5" {
-		colorCode := strings.Join(parts[index:index+3], ";")
-		if isForeground {
-			t.fgColor = colorCode
-		} else {
-			t.bgColor = colorCode
-		}
+		t.setExtendedColor(code, strings.Join(parts[index:index+3], ";"))
 		return 3
 	}
 
-	// RGB color: 38;2;r;g;b or 48;2;r;g;b
+	// RGB color: 38;2;r;g;b, 48;2;r;g;b or 58;2;r;g;b
 	if index+4 < len(parts) && parts[index+1] == "2" {
-		colorCode := strings.Join(parts[index:index+5], ";")
-		if isForeground {
-			t.fgColor = colorCode
-		} else {
-			t.bgColor = colorCode
-		}
+		t.setExtendedColor(code, strings.Join(parts[index:index+5], ";"))
 		return 5
 	}
 
 	return 0
 }
 
+func (t *AnsiCodeTracker) setExtendedColor(code int, colorCode string) {
+	switch code {
+	case 38:
+		t.fgColor = colorCode
+	case 48:
+		t.bgColor = colorCode
+	case 58:
+		t.underlineColor = colorCode
+	}
+}
+
+// processSubParams handles a colon-separated parameter: an underline style
+// (4:3) or a color (38:5:n, 38:2:r:g:b, or 38:2:colorspace:r:g:b).
+func (t *AnsiCodeTracker) processSubParams(sub []string) {
+	code, err := strconv.Atoi(sub[0])
+	if err != nil {
+		return
+	}
+	if code == 4 {
+		style, err := strconv.Atoi(cmp.Or(sub[1], "0"))
+		if err == nil && style >= underlineNone && style <= underlineDashed {
+			t.underline = style
+		}
+		return
+	}
+	if code != 38 && code != 48 && code != 58 {
+		return
+	}
+	var values []string
+	switch {
+	case sub[1] == "5" && len(sub) == 3:
+		values = sub[1:]
+	case sub[1] == "2" && len(sub) >= 5:
+		// The colorspace id, when present, is dropped.
+		values = append([]string{"2"}, sub[len(sub)-3:]...)
+	default:
+		return
+	}
+	for _, v := range values {
+		if _, err := strconv.Atoi(v); err != nil {
+			return
+		}
+	}
+	t.setExtendedColor(code, sub[0]+";"+strings.Join(values, ";"))
+}
+
+// processHyperlink handles an OSC 8 sequence: ESC ] 8 ; params ; URI ST.
+// An empty URI closes the link.
+func (t *AnsiCodeTracker) processHyperlink(code string) {
+	body := strings.TrimPrefix(code, "\x1b]8;")
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\x07"), "\x1b\\")
+	if _, uri, _ := strings.Cut(body, ";"); uri == "" {
+		t.hyperlink = ""
+	} else {
+		t.hyperlink = code
+	}
+}
+
 // processStandardCode handles standard SGR codes for text attributes and basic colors.
 func (t *AnsiCodeTracker) processStandardCode(code int, part string) {
 	switch code {
 	case 0:
-		t.Reset()
+		t.resetSGR()
 	case 1:
 		t.bold = true
 	case 2:
@@ -133,8 +210,8 @@ func (t *AnsiCodeTracker) processStandardCode(code int, part string) {
 	case 3:
 		t.italic = true
 	case 4:
-		t.underline = true
-	case 5:
+		t.underline = underlineSingle
+	case 5, 6:
 		t.blink = true
 	case 7:
 		t.inverse = true
@@ -143,14 +220,14 @@ func (t *AnsiCodeTracker) processStandardCode(code int, part string) {
 	case 9:
 		t.strikethrough = true
 	case 21:
-		t.bold = false
+		t.underline = underlineDouble
 	case 22:
 		t.bold = false
 		t.dim = false
 	case 23:
 		t.italic = false
 	case 24:
-		t.underline = false
+		t.underline = underlineNone
 	case 25:
 		t.blink = false
 	case 27:
@@ -163,6 +240,12 @@ func (t *AnsiCodeTracker) processStandardCode(code int, part string) {
 		t.fgColor = ""
 	case 49:
 		t.bgColor = ""
+	case 53:
+		t.overline = true
+	case 55:
+		t.overline = false
+	case 59:
+		t.underlineColor = ""
 	default:
 		t.processColorCode(code, part)
 	}
@@ -186,16 +269,24 @@ func (t *AnsiCodeTracker) processColorCode(code int, part string) {
 //	tracker.Reset()                 // clear all formatting
 //	tracker.HasActiveCodes()        // returns false
 func (t *AnsiCodeTracker) Reset() {
+	t.resetSGR()
+	t.hyperlink = ""
+}
+
+// resetSGR clears the SGR attributes, as "\x1b[0m" does.
+func (t *AnsiCodeTracker) resetSGR() {
 	t.bold = false
 	t.dim = false
 	t.italic = false
-	t.underline = false
+	t.underline = underlineNone
 	t.blink = false
 	t.inverse = false
 	t.hidden = false
 	t.strikethrough = false
+	t.overline = false
 	t.fgColor = ""
 	t.bgColor = ""
+	t.underlineColor = ""
 }
 
 // Clear is an alias for Reset. It clears all active formatting attributes.
@@ -208,7 +299,8 @@ func (t *AnsiCodeTracker) Clear() {
 }
 
 // GetActiveCodes returns an ANSI escape sequence that represents all currently active
-// formatting attributes. This is useful for continuing formatting on a new line.
+// formatting attributes, followed by the OSC 8 sequence of an open hyperlink.
+// This is useful for continuing formatting on a new line.
 // Returns an empty string if no formatting is active.
 //
 // Example:
@@ -221,8 +313,8 @@ func (t *AnsiCodeTracker) Clear() {
 //	tracker.Process("\x1b[1;31m")
 //	line2 := tracker.GetActiveCodes() + "World\x1b[0m"
 func (t *AnsiCodeTracker) GetActiveCodes() string {
-	if !t.HasActiveCodes() {
-		return ""
+	if !t.hasActiveSGR() {
+		return t.hyperlink
 	}
 
 	codes := make([]string, 0, 10)
@@ -235,8 +327,12 @@ func (t *AnsiCodeTracker) GetActiveCodes() string {
 	if t.italic {
 		codes = append(codes, "3")
 	}
-	if t.underline {
+	switch t.underline {
+	case underlineNone:
+	case underlineSingle:
 		codes = append(codes, "4")
+	default:
+		codes = append(codes, "4:"+strconv.Itoa(t.underline))
 	}
 	if t.blink {
 		codes = append(codes, "5")
@@ -250,17 +346,24 @@ func (t *AnsiCodeTracker) GetActiveCodes() string {
 	if t.strikethrough {
 		codes = append(codes, "9")
 	}
+	if t.overline {
+		codes = append(codes, "53")
+	}
 	if t.fgColor != "" {
 		codes = append(codes, t.fgColor)
 	}
 	if t.bgColor != "" {
 		codes = append(codes, t.bgColor)
 	}
+	if t.underlineColor != "" {
+		codes = append(codes, t.underlineColor)
+	}
 
 	var builder strings.Builder
 	builder.WriteString("\x1b[")
 	builder.WriteString(strings.Join(codes, ";"))
 	builder.WriteString("m")
+	builder.WriteString(t.hyperlink)
 	return builder.String()
 }
 
@@ -273,14 +376,20 @@ func (t *AnsiCodeTracker) GetActiveCodes() string {
 //	tracker.Process("\x1b[1m")      // bold
 //	tracker.HasActiveCodes()        // returns true
 func (t *AnsiCodeTracker) HasActiveCodes() bool {
-	return t.bold || t.dim || t.italic || t.underline ||
-		t.blink || t.inverse || t.hidden || t.strikethrough ||
-		t.fgColor != "" || t.bgColor != ""
+	return t.hasActiveSGR() || t.hyperlink != ""
+}
+
+func (t *AnsiCodeTracker) hasActiveSGR() bool {
+	return t.bold || t.dim || t.italic || t.underline != underlineNone ||
+		t.blink || t.inverse || t.hidden || t.strikethrough || t.overline ||
+		t.fgColor != "" || t.bgColor != "" || t.underlineColor != ""
 }
 
 // GetLineEndReset returns an ANSI code to reset underline formatting at the end of a line.
 // This is useful because underline formatting can extend beyond the text content.
-// Returns "\x1b[24m" (turn off underline) if underline is active, empty string otherwise.
+// Returns "\x1b[24m" (turn off underline, of any style) if underline is active, with 55
+// added for overline, and closes an open hyperlink so it doesn't run into the next
+// line; GetActiveCodes restores all of them.
 //
 // Example:
 //
@@ -288,10 +397,19 @@ func (t *AnsiCodeTracker) HasActiveCodes() bool {
 //	reset := tracker.GetLineEndReset()  // returns "\x1b[24m"
 //	line := "text" + reset              // prevents underline from extending
 func (t *AnsiCodeTracker) GetLineEndReset() string {
-	if t.underline {
-		return "\x1b[24m"
+	reset := ""
+	switch {
+	case t.underline != underlineNone && t.overline:
+		reset = "\x1b[24;55m"
+	case t.underline != underlineNone:
+		reset = "\x1b[24m"
+	case t.overline:
+		reset = "\x1b[55m"
+	}
+	if t.hyperlink != "" {
+		reset += HyperlinkClose
 	}
-	return ""
+	return reset
 }
 
 /*
@@ -0,0 +1,143 @@
+// Command fasttui provides developer tools for fasttui themes.
+//
+//	fasttui theme lint [-modes truecolor,256color,16color] [-strict] <theme|file.json>...
+//	fasttui theme export -format alacritty|kitty|wezterm|iterm2|vscode <theme|file.json>
+//	fasttui theme import [-name name] <scheme.yaml>
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+const usage = `usage:
+  fasttui theme lint [flags] <theme|file.json>...
+  fasttui theme export -format <format> <theme|file.json>
+  fasttui theme import [-name name] <scheme.yaml>
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 2 || args[0] != "theme" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[1] {
+	case "lint":
+		return runThemeLint(args[2:], stdout, stderr)
+	case "export":
+		return runThemeExport(args[2:], stdout, stderr)
+	case "import":
+		return runThemeImport(args[2:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown theme command %q\n%s", args[1], usage)
+		return 2
+	}
+}
+
+// runThemeLint prints every issue and exits 1 when a theme has errors, or
+// warnings with -strict.
+func runThemeLint(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("theme lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	modes := fs.String("modes", "", "comma-separated color modes to check contrast in (default truecolor,256color,16color)")
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var colorModes []style.ColorMode
+	if *modes != "" {
+		for m := range strings.SplitSeq(*modes, ",") {
+			mode := style.ColorMode(strings.TrimSpace(m))
+			switch mode {
+			case style.ColorModeTruecolor, style.ColorMode256, style.ColorMode16:
+				colorModes = append(colorModes, mode)
+			default:
+				fmt.Fprintf(stderr, "unknown color mode %q\n", m)
+				return 2
+			}
+		}
+	}
+
+	status := 0
+	for _, name := range fs.Args() {
+		issues, err := style.LintTheme(name, colorModes...)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			status = 1
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Fprintf(stdout, "%s: %s\n", name, issue)
+			if issue.Severity == style.LintError || *strict {
+				status = 1
+			}
+		}
+	}
+	return status
+}
+
+// runThemeExport writes the theme as a terminal or editor color scheme.
+func runThemeExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("theme export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "", "output format: alacritty, kitty, wezterm, iterm2 or vscode")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *format == "" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	out, err := style.ExportTheme(fs.Arg(0), style.ThemeExportFormat(*format))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	stdout.Write(out)
+	return 0
+}
+
+// runThemeImport converts a base16 or base24 YAML scheme to theme JSON.
+func runThemeImport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("theme import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("name", "", "theme name (default: the scheme name in kebab case)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	tf, err := style.ImportBase16(data, *name)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	out, err := json.MarshalIndent(tf, "", "\t")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s\n", out)
+	return 0
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeFile(t *testing.T, name, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"theme"},
+		{"palette", "lint"},
+		{"theme", "format"},
+		{"theme", "lint"},
+		{"theme", "lint", "-bogus", "dark"},
+		{"theme", "lint", "-modes", "truecolor,8color", "dark"},
+		{"theme", "export", "dark"},
+		{"theme", "export", "-format", "kitty"},
+		{"theme", "import"},
+	} {
+		if code, _, stderr := runCommand(t, args...); code != 2 || stderr == "" {
+			t.Errorf("run(%q) = %d, stderr %q; want usage error", args, code, stderr)
+		}
+	}
+}
+
+func TestRunThemeLint(t *testing.T) {
+	code, stdout, _ := runCommand(t, "theme", "lint", "-modes", "truecolor", "dark")
+	if code != 0 || !strings.Contains(stdout, "dark: warning: ") {
+		t.Errorf("lint dark = %d, stdout %q; want warnings and status 0", code, stdout)
+	}
+	if code, _, _ := runCommand(t, "theme", "lint", "-strict", "-modes", "truecolor", "dark"); code != 1 {
+		t.Errorf("lint -strict with warnings = %d, want 1", code)
+	}
+
+	bad := writeFile(t, "bad.json", `{"name": "bad", "extends": "dark", "colors": {"acent": "#ffffff"}}`)
+	code, stdout, _ = runCommand(t, "theme", "lint", bad)
+	if code != 1 || !strings.Contains(stdout, `error: colors.acent: unknown key "acent"`) {
+		t.Errorf("lint bad.json = %d, stdout %q", code, stdout)
+	}
+	if code, _, stderr := runCommand(t, "theme", "lint", "no-such-theme"); code != 1 || stderr == "" {
+		t.Errorf("lint unknown theme = %d, stderr %q", code, stderr)
+	}
+}
+
+func TestRunThemeExport(t *testing.T) {
+	code, stdout, _ := runCommand(t, "theme", "export", "-format", "kitty", "dark")
+	if code != 0 || !strings.Contains(stdout, "background #18181e") {
+		t.Errorf("export kitty = %d, stdout %q", code, stdout)
+	}
+	if code, _, stderr := runCommand(t, "theme", "export", "-format", "xterm", "dark"); code != 1 || stderr == "" {
+		t.Errorf("export unknown format = %d, stderr %q", code, stderr)
+	}
+}
+
+func TestRunThemeImport(t *testing.T) {
+	scheme := writeFile(t, "scheme.yaml", `scheme: "Tomorrow Night"
+base00: "1d1f21"
+base01: "282a2e"
+base02: "373b41"
+base03: "969896"
+base04: "b4b7b4"
+base05: "c5c8c6"
+base06: "e0e0e0"
+base07: "ffffff"
+base08: "cc6666"
+base09: "de935f"
+base0A: "f0c674"
+base0B: "b5bd68"
+base0C: "8abeb7"
+base0D: "81a2be"
+base0E: "b294bb"
+base0F: "a3685a"
+`)
+	code, stdout, stderr := runCommand(t, "theme", "import", "-name", "tomorrow", scheme)
+	if code != 0 {
+		t.Fatalf("import = %d, stderr %q", code, stderr)
+	}
+	var tf style.ThemeFile
+	if err := json.Unmarshal([]byte(stdout), &tf); err != nil {
+		t.Fatalf("import output is not theme JSON: %v\n%s", err, stdout)
+	}
+	if tf.Name != "tomorrow" {
+		t.Errorf("name = %q, want tomorrow", tf.Name)
+	}
+	for _, issue := range style.LintThemeJSON([]byte(stdout)) {
+		if issue.Severity == style.LintError {
+			t.Errorf("imported theme: %s", issue)
+		}
+	}
+
+	if code, _, _ := runCommand(t, "theme", "import", filepath.Join(t.TempDir(), "missing.yaml")); code != 1 {
+		t.Errorf("import missing file = %d, want 1", code)
+	}
+	notYAML := writeFile(t, "bad.yaml", "scheme: [")
+	if code, _, _ := runCommand(t, "theme", "import", notYAML); code != 1 {
+		t.Errorf("import invalid YAML = %d, want 1", code)
+	}
+}
@@ -1,12 +1,14 @@
 package components
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // AutocompleteItem represents a single autocomplete suggestion
@@ -35,6 +37,18 @@ type AutocompleteProvider interface {
 	ApplyCompletion(lines []string, cursorLine, cursorCol int, item AutocompleteItem, prefix string) *AutocompleteResult
 }
 
+// AsyncAutocompleteProvider is an AutocompleteProvider whose suggestions may be slow
+// (subprocesses, network). When the Editor has an event loop to report back to, it
+// calls GetSuggestionsContext off the loop after a debounce and cancels ctx as soon
+// as the request is superseded by further typing.
+type AsyncAutocompleteProvider interface {
+	AutocompleteProvider
+
+	// GetSuggestionsContext is GetSuggestions with cancellation.
+	// The result is discarded when ctx is done.
+	GetSuggestionsContext(ctx context.Context, lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions
+}
+
 // AutocompleteSuggestions contains the suggestions and the prefix being matched
 type AutocompleteSuggestions struct {
 	Items  []AutocompleteItem
@@ -50,16 +64,26 @@ type AutocompleteResult struct {
 
 // CombinedAutocompleteProvider handles both slash commands and file paths
 type CombinedAutocompleteProvider struct {
-	commands []any // Can be SlashCommand or AutocompleteItem
+	commands []any // Can be SlashCommand, *CommandSpec or AutocompleteItem
 	basePath string
 	fdPath   string
+
+	indexOnce sync.Once
+	index     *FileIndex // built-in walker used when fd is unavailable
 }
 
+var _ AsyncAutocompleteProvider = (*CombinedAutocompleteProvider)(nil)
+
 // NewCombinedAutocompleteProvider creates a new combined autocomplete provider
 func NewCombinedAutocompleteProvider(commands []any, basePath, fdPath string) *CombinedAutocompleteProvider {
 	if basePath == "" {
 		basePath, _ = os.Getwd()
 	}
+	if fdPath != "" {
+		if _, err := exec.LookPath(fdPath); err != nil {
+			fdPath = ""
+		}
+	}
 	return &CombinedAutocompleteProvider{
 		commands: commands,
 		basePath: basePath,
@@ -69,6 +93,12 @@ func NewCombinedAutocompleteProvider(commands []any, basePath, fdPath string) *C
 
 // GetSuggestions implements AutocompleteProvider
 func (p *CombinedAutocompleteProvider) GetSuggestions(lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions {
+	return p.GetSuggestionsContext(context.Background(), lines, cursorLine, cursorCol)
+}
+
+// GetSuggestionsContext implements AsyncAutocompleteProvider. Cancelling ctx kills
+// a running fd search.
+func (p *CombinedAutocompleteProvider) GetSuggestionsContext(ctx context.Context, lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions {
 	if cursorLine >= len(lines) {
 		return nil
 	}
@@ -84,7 +114,7 @@ func (p *CombinedAutocompleteProvider) GetSuggestions(lines []string, cursorLine
 	if atMatch := extractAtMatch(textBeforeCursor); atMatch != "" {
 		prefix := atMatch   // The @... part
 		query := prefix[1:] // Remove the @
-		suggestions := p.getFuzzyFileSuggestions(query)
+		suggestions := p.getFuzzyFileSuggestions(ctx, query)
 		if len(suggestions) == 0 {
 			return nil
 		}
@@ -135,6 +165,17 @@ func (p *CombinedAutocompleteProvider) GetSuggestions(lines []string, cursorLine
 				return nil
 			}
 
+			if spec, ok := command.(*CommandSpec); ok {
+				items, argPrefix := spec.completeArguments(argumentText)
+				if len(items) == 0 {
+					return nil
+				}
+				return &AutocompleteSuggestions{
+					Items:  items,
+					Prefix: argPrefix,
+				}
+			}
+
 			slashCmd, ok := command.(SlashCommand)
 			if !ok || slashCmd.GetArgumentCompletions == nil {
 				return nil
@@ -321,6 +362,12 @@ func (p *CombinedAutocompleteProvider) getCommandItems() []commandItem {
 				label:       c.Name,
 				description: c.Description,
 			})
+		case *CommandSpec:
+			items = append(items, commandItem{
+				name:        c.Name,
+				label:       c.Name,
+				description: c.Description,
+			})
 		case AutocompleteItem:
 			items = append(items, commandItem{
 				name:        c.Value,
@@ -339,6 +386,10 @@ func (p *CombinedAutocompleteProvider) findCommand(name string) any {
 			if c.Name == name {
 				return c
 			}
+		case *CommandSpec:
+			if c.matches(name) {
+				return c
+			}
 		case AutocompleteItem:
 			if c.Value == name {
 				return c
@@ -615,7 +666,7 @@ func scoreEntry(filePath, query string, isDirectory bool) int {
 }
 
 // walkDirectoryWithFd uses fd to walk directory tree
-func walkDirectoryWithFd(baseDir, fdPath, query string, maxResults int) []struct {
+func walkDirectoryWithFd(ctx context.Context, baseDir, fdPath, query string, maxResults int) []struct {
 	path        string
 	isDirectory bool
 } {
@@ -631,7 +682,7 @@ func walkDirectoryWithFd(baseDir, fdPath, query string, maxResults int) []struct
 		args = append(args, query)
 	}
 
-	cmd := exec.Command(fdPath, args...)
+	cmd := exec.CommandContext(ctx, fdPath, args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil
@@ -661,13 +712,45 @@ func walkDirectoryWithFd(baseDir, fdPath, query string, maxResults int) []struct
 	return results
 }
 
-// getFuzzyFileSuggestions performs fuzzy file search using fd
-func (p *CombinedAutocompleteProvider) getFuzzyFileSuggestions(query string) []AutocompleteItem {
-	if p.fdPath == "" {
-		return nil
+// walkDirectoryWithIndex lists the cached native index in the same shape as
+// walkDirectoryWithFd. Filtering is left to scoreEntry.
+func (p *CombinedAutocompleteProvider) walkDirectoryWithIndex(ctx context.Context) []struct {
+	path        string
+	isDirectory bool
+} {
+	p.indexOnce.Do(func() {
+		p.index = NewFileIndex(p.basePath)
+	})
+
+	indexed := p.index.Entries(ctx)
+	results := make([]struct {
+		path        string
+		isDirectory bool
+	}, 0, len(indexed))
+	for _, entry := range indexed {
+		results = append(results, struct {
+			path        string
+			isDirectory bool
+		}{
+			path:        entry.Path,
+			isDirectory: entry.IsDir,
+		})
 	}
+	return results
+}
 
-	entries := walkDirectoryWithFd(p.basePath, p.fdPath, query, 100)
+// getFuzzyFileSuggestions performs fuzzy file search using fd, or the built-in
+// index when fd is not installed
+func (p *CombinedAutocompleteProvider) getFuzzyFileSuggestions(ctx context.Context, query string) []AutocompleteItem {
+	var entries []struct {
+		path        string
+		isDirectory bool
+	}
+	if p.fdPath != "" {
+		entries = walkDirectoryWithFd(ctx, p.basePath, p.fdPath, query, 100)
+	} else {
+		entries = p.walkDirectoryWithIndex(ctx)
+	}
 
 	// Score entries
 	type scoredEntry struct {
@@ -692,7 +775,7 @@ func (p *CombinedAutocompleteProvider) getFuzzyFileSuggestions(query string) []A
 	}
 
 	// Sort by score (descending) and take top 20
-	sort.Slice(scoredEntries, func(i, j int) bool {
+	sort.SliceStable(scoredEntries, func(i, j int) bool {
 		return scoredEntries[i].score > scoredEntries[j].score
 	})
 
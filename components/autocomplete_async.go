@@ -0,0 +1,168 @@
+package components
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yeeaiclub/fasttui"
+)
+
+const defaultAutocompleteDebounce = 80 * time.Millisecond
+
+// eventPoster runs fn on the UI event loop. Implemented by *fasttui.TUI.
+type eventPoster interface {
+	Post(fn func())
+}
+
+// WithEditorAsyncAutocomplete lets the Editor query an AsyncAutocompleteProvider off
+// the event loop; results are delivered back through ui. Without it async providers
+// are called synchronously like any other provider.
+func WithEditorAsyncAutocomplete(ui *fasttui.TUI) EditorOption {
+	return func(e *Editor) {
+		if ui != nil {
+			e.autocompletePoster = ui
+			e.poster = ui
+		}
+	}
+}
+
+// WithEditorEventLoop lets the Editor update itself from timers through ui,
+// such as inserting a key sequence prefix that timed out. Without it the
+// prefix is handled together with the next key.
+func WithEditorEventLoop(ui *fasttui.TUI) EditorOption {
+	return func(e *Editor) {
+		if ui != nil {
+			e.poster = ui
+		}
+	}
+}
+
+// WithEditorAutocompleteDebounce sets how long typing must pause before an async
+// provider is queried. Defaults to 80ms.
+func WithEditorAutocompleteDebounce(d time.Duration) EditorOption {
+	return func(e *Editor) {
+		e.autocompleteDebounce = max(0, d)
+	}
+}
+
+// requestAsyncSuggestions schedules a debounced query of an async provider and
+// reports whether it did; false means the caller should query synchronously.
+// Any request still pending is cancelled first.
+func (e *Editor) requestAsyncSuggestions(debounce time.Duration) bool {
+	provider, ok := e.autocompleteProvider.(AsyncAutocompleteProvider)
+	if !ok || e.autocompletePoster == nil {
+		return false
+	}
+	e.querySuggestionsAsync(debounce, "regular", provider.GetSuggestionsContext, func(suggestions *AutocompleteSuggestions) {
+		e.showSuggestions(suggestions, "regular")
+	})
+	return true
+}
+
+// querySuggestionsAsync runs query off the event loop after debounce and hands
+// its result to deliver on the event loop, unless a newer request or a cancel
+// came first. The loading row shows meanwhile, in the given autocomplete state.
+func (e *Editor) querySuggestionsAsync(
+	debounce time.Duration,
+	state string,
+	query func(ctx context.Context, lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions,
+	deliver func(*AutocompleteSuggestions),
+) {
+	e.cancelPendingSuggestions()
+	seq := e.autocompleteSeq
+
+	lines := slices.Clone(e.state.lines)
+	cursorLine, cursorCol := e.state.cursorLine, e.state.cursorCol
+	ctx, cancel := context.WithCancel(context.Background())
+	e.autocompleteCancel = cancel
+	e.autocompleteLoading = true
+	if e.autocompleteState == "" {
+		e.autocompleteState = state
+	}
+
+	poster := e.autocompletePoster
+	e.autocompleteTimer = time.AfterFunc(debounce, func() {
+		suggestions := query(ctx, lines, cursorLine, cursorCol)
+		if ctx.Err() != nil {
+			return
+		}
+		poster.Post(func() {
+			if seq != e.autocompleteSeq {
+				return // superseded by a newer request
+			}
+			// The cursor moved without triggering a new request; ask again for where it is now
+			if e.state.cursorLine != cursorLine || e.state.cursorCol != cursorCol ||
+				!slices.Equal(e.state.lines, lines) {
+				e.querySuggestionsAsync(0, state, query, deliver)
+				return
+			}
+			e.autocompleteLoading = false
+			e.autocompleteCancel = nil
+			e.autocompleteTimer = nil
+			cancel()
+			deliver(suggestions)
+		})
+	})
+}
+
+// cancelPendingSuggestions stops a debounced or running async query and
+// invalidates results that are already queued on the event loop.
+func (e *Editor) cancelPendingSuggestions() {
+	e.autocompleteSeq++
+	if e.autocompleteTimer != nil {
+		e.autocompleteTimer.Stop()
+		e.autocompleteTimer = nil
+	}
+	if e.autocompleteCancel != nil {
+		e.autocompleteCancel()
+		e.autocompleteCancel = nil
+	}
+	e.autocompleteLoading = false
+}
+
+// showSuggestions displays suggestions in the autocomplete list, or hides the list
+// when there are none.
+func (e *Editor) showSuggestions(suggestions *AutocompleteSuggestions, state string) {
+	if suggestions == nil || len(suggestions.Items) == 0 {
+		e.cancelAutocomplete()
+		return
+	}
+
+	e.autocompletePrefix = suggestions.Prefix
+	items := make([]SelectItem, len(suggestions.Items))
+	for i, it := range suggestions.Items {
+		items[i] = SelectItem{
+			Label:       it.Label,
+			Value:       it.Value,
+			Description: it.Description,
+		}
+	}
+	e.autocompleteList = NewSelectList(items, e.autocompleteMaxVisible,
+		WithSelectListTheme(e.autocompleteSelectTheme),
+		WithSelectListHighlight(autocompleteHighlightQuery(suggestions.Prefix)),
+	)
+	e.autocompleteState = state
+}
+
+// autocompleteHighlightQuery derives the text to highlight in suggestion labels
+// from the completion prefix. Labels show the last path segment, so only the
+// part after the last "/" is used ("@src/ed" highlights "ed", "/mo" "mo").
+func autocompleteHighlightQuery(prefix string) string {
+	prefix = strings.TrimPrefix(prefix, "@")
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefix = prefix[i+1:]
+	}
+	return prefix
+}
+
+// renderAutocompleteLoading returns the placeholder row shown until the first
+// async results arrive.
+func (e *Editor) renderAutocompleteLoading() string {
+	text := "  loading…"
+	if e.autocompleteSelectTheme.ScrollInfo != nil {
+		return e.autocompleteSelectTheme.ScrollInfo(text)
+	}
+	return text
+}
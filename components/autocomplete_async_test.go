@@ -0,0 +1,164 @@
+package components
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yeeaiclub/fasttui"
+)
+
+type queuePoster struct{ ch chan func() }
+
+func (q *queuePoster) Post(fn func()) { q.ch <- fn }
+
+// pump runs posted callbacks on the test goroutine, standing in for the event loop.
+func (q *queuePoster) pump(t *testing.T, n int) {
+	t.Helper()
+	for range n {
+		select {
+		case fn := <-q.ch:
+			fn()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for async suggestions")
+		}
+	}
+}
+
+type slowProvider struct {
+	mu        sync.Mutex
+	queries   []string
+	cancelled []string
+	delay     time.Duration
+}
+
+func (p *slowProvider) GetSuggestions(lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions {
+	return p.GetSuggestionsContext(context.Background(), lines, cursorLine, cursorCol)
+}
+
+func (p *slowProvider) GetSuggestionsContext(ctx context.Context, lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions {
+	query := lines[cursorLine][:cursorCol]
+	p.mu.Lock()
+	p.queries = append(p.queries, query)
+	p.mu.Unlock()
+
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.cancelled = append(p.cancelled, query)
+		p.mu.Unlock()
+		return nil
+	}
+	return &AutocompleteSuggestions{
+		Items:  []AutocompleteItem{{Value: "help", Label: "help"}, {Value: "hello", Label: "hello"}},
+		Prefix: query,
+	}
+}
+
+func (p *slowProvider) ApplyCompletion(lines []string, cursorLine, cursorCol int, item AutocompleteItem, prefix string) *AutocompleteResult {
+	return nil
+}
+
+func (p *slowProvider) snapshot() ([]string, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string{}, p.queries...), append([]string{}, p.cancelled...)
+}
+
+func newAsyncEditor(provider AutocompleteProvider, debounce time.Duration) (*Editor, *queuePoster) {
+	poster := &queuePoster{ch: make(chan func(), 8)}
+	e := NewEditor(&mockEditorTerm{w: 40, h: 20}, nil, WithEditorAutocompleteDebounce(debounce))
+	e.autocompletePoster = poster
+	e.SetAutocomplete(provider, SelectListTheme{}, 5)
+	return e, poster
+}
+
+func TestEditorAsyncAutocompleteDebouncesKeystrokes(t *testing.T) {
+	provider := &slowProvider{}
+	e, poster := newAsyncEditor(provider, 30*time.Millisecond)
+
+	e.HandleInput("/")
+	e.HandleInput("h")
+	e.HandleInput("e")
+
+	if !strings.Contains(strings.Join(e.Render(40), "\n"), "loading…") {
+		t.Fatalf("expected a loading row while suggestions are pending")
+	}
+
+	poster.pump(t, 1)
+
+	queries, _ := provider.snapshot()
+	if len(queries) != 1 || queries[0] != "/he" {
+		t.Fatalf("expected a single debounced query for \"/he\", got %q", queries)
+	}
+	out := fasttui.StripAnsi(strings.Join(e.Render(40), "\n"))
+	if strings.Contains(out, "loading…") || !strings.Contains(out, "hello") {
+		t.Fatalf("expected suggestions to replace the loading row, got %q", out)
+	}
+}
+
+func TestEditorAsyncAutocompleteCancelsStaleRequest(t *testing.T) {
+	provider := &slowProvider{delay: 50 * time.Millisecond}
+	e, poster := newAsyncEditor(provider, 0)
+
+	e.HandleInput("/")
+	time.Sleep(10 * time.Millisecond) // let the first query start
+	e.HandleInput("h")
+
+	poster.pump(t, 1)
+
+	_, cancelled := provider.snapshot()
+	if len(cancelled) != 1 || cancelled[0] != "/" {
+		t.Fatalf("expected the stale \"/\" query to be cancelled, got %q", cancelled)
+	}
+	if !e.IsShowingAutocomplete() || e.autocompletePrefix != "/h" {
+		t.Fatalf("expected results for \"/h\", prefix %q", e.autocompletePrefix)
+	}
+}
+
+func TestEditorAsyncAutocompleteEscapeWhileLoading(t *testing.T) {
+	provider := &slowProvider{}
+	e, _ := newAsyncEditor(provider, time.Hour)
+
+	e.HandleInput("/")
+	e.HandleInput("\x1b")
+
+	if e.IsShowingAutocomplete() || strings.Contains(strings.Join(e.Render(40), "\n"), "loading…") {
+		t.Fatalf("escape should cancel pending suggestions")
+	}
+}
+
+func TestEditorAsyncForceFileCompletion(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"alpha.txt", "beta.txt", "bravo.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, poster := newAsyncEditor(NewCombinedAutocompleteProvider(nil, root, ""), time.Hour)
+
+	e.SetText([]string{"open b"})
+	e.SetCursor(0, 6)
+	e.HandleInput("\t")
+	if e.autocompleteList != nil || !e.autocompleteLoading {
+		t.Fatalf("Tab should query file suggestions off the event loop")
+	}
+	poster.pump(t, 1)
+	if e.autocompleteState != "force" || e.autocompleteList == nil || len(e.autocompleteList.items) != 2 {
+		t.Fatalf("expected two forced file suggestions, state %q", e.autocompleteState)
+	}
+
+	e.cancelAutocomplete()
+	e.SetText([]string{"open a"})
+	e.SetCursor(0, 6)
+	e.HandleInput("\t")
+	poster.pump(t, 1)
+	if got := e.GetTextString(); got != "open alpha.txt" || e.IsShowingAutocomplete() {
+		t.Fatalf("single suggestion should be applied, got %q (showing %v)", got, e.IsShowingAutocomplete())
+	}
+}
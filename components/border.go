@@ -1,8 +1,14 @@
 package components
 
-import "github.com/yeeaiclub/fasttui"
+import (
+	"github.com/yeeaiclub/fasttui"
+	"github.com/yeeaiclub/fasttui/style"
+)
 
-var _ fasttui.Component = (*DynamicBorder)(nil)
+var (
+	_ fasttui.Component  = (*DynamicBorder)(nil)
+	_ fasttui.ThemeAware = (*DynamicBorder)(nil)
+)
 
 type DynamicBorder struct {
 	color func(string) string
@@ -18,6 +24,13 @@ func WithBorderColor(color func(string) string) DynamicBorderOption {
 	}
 }
 
+// WithBorderStyle colors the border with the theme's border token.
+func WithBorderStyle(theme *style.Theme) DynamicBorderOption {
+	return func(d *DynamicBorder) {
+		d.SetTheme(theme)
+	}
+}
+
 func NewDynamicBorder(opts ...DynamicBorderOption) *DynamicBorder {
 	d := &DynamicBorder{
 		color: func(s string) string { return s },
@@ -32,6 +45,11 @@ func NewDynamicBorder(opts ...DynamicBorderOption) *DynamicBorder {
 	return d
 }
 
+// SetTheme colors the border with the theme's border token.
+func (d *DynamicBorder) SetTheme(theme *style.Theme) {
+	d.color = func(s string) string { return theme.Fg(style.ColorBorder, s) }
+}
+
 func (d *DynamicBorder) Render(width int) []string {
 	line := ""
 	for range max(0, width) {
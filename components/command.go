@@ -0,0 +1,677 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ArgType is the value type of a positional argument or flag.
+type ArgType int
+
+const (
+	ArgString ArgType = iota
+	ArgInt
+	ArgBool // flags only; a bare --flag means true
+)
+
+// ArgSpec describes a positional argument.
+type ArgSpec struct {
+	Name        string
+	Description string
+	Type        ArgType
+	Required    bool
+	// Variadic collects every remaining positional word. Only valid on the last argument.
+	Variadic bool
+	// Choices restricts the value to a fixed set and drives completion.
+	Choices []string
+	// Complete returns completions for a partially typed value.
+	// It is consulted when Choices is empty.
+	Complete func(prefix string) []AutocompleteItem
+}
+
+// FlagSpec describes a "--name value" or "-n value" option.
+type FlagSpec struct {
+	Name        string // long name without "--"
+	Short       string // optional single letter without "-"
+	Description string
+	Type        ArgType
+	Default     string
+	Choices     []string
+	Complete    func(prefix string) []AutocompleteItem
+}
+
+// CommandSpec declares a slash command. Commands with Subcommands dispatch on
+// their first positional word; the invoked subcommand's Args and Flags apply
+// after it, and the parent's flags stay valid.
+type CommandSpec struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Args        []ArgSpec
+	Flags       []FlagSpec
+	Subcommands []*CommandSpec
+}
+
+// CommandInvocation is a parsed and validated command line.
+type CommandInvocation struct {
+	// Path holds the command and subcommand names, e.g. ["git", "commit"].
+	Path []string
+	// Spec is the innermost command that was invoked.
+	Spec  *CommandSpec
+	Args  map[string][]string
+	Flags map[string]string
+	// Help is set instead of Args/Flags for /help and for --help on any
+	// command; it holds the rendered help text.
+	Help string
+}
+
+// Arg returns the first value of a positional argument, or "".
+func (inv *CommandInvocation) Arg(name string) string {
+	if values := inv.Args[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ArgList returns every value of a variadic positional argument.
+func (inv *CommandInvocation) ArgList(name string) []string {
+	return inv.Args[name]
+}
+
+// Flag returns a flag's value, its default when not given, or "".
+func (inv *CommandInvocation) Flag(name string) string {
+	return inv.Flags[name]
+}
+
+// Int returns an integer argument or flag. Values were validated by the parser,
+// so a missing value yields 0.
+func (inv *CommandInvocation) Int(name string) int {
+	value := inv.Arg(name)
+	if value == "" {
+		value = inv.Flag(name)
+	}
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// Bool returns a boolean flag.
+func (inv *CommandInvocation) Bool(name string) bool {
+	b, _ := strconv.ParseBool(inv.Flag(name))
+	return b
+}
+
+// ErrNotACommand is returned by CommandSet.Parse for text that does not start with "/".
+var ErrNotACommand = errors.New("not a slash command")
+
+// CommandError is a validation error from CommandSet.Parse.
+type CommandError struct {
+	Command string // e.g. "/git commit"; empty when the command itself is unknown
+	Message string
+	Usage   string
+}
+
+func (e *CommandError) Error() string {
+	if e.Command == "" {
+		return e.Message
+	}
+	return e.Command + ": " + e.Message
+}
+
+// CommandSet is a registry of slash commands with a built-in /help.
+type CommandSet struct {
+	commands []*CommandSpec
+	help     *CommandSpec
+}
+
+// NewCommandSet registers specs. A "help" command is added unless one of the
+// specs already uses that name.
+func NewCommandSet(specs ...*CommandSpec) *CommandSet {
+	s := &CommandSet{commands: specs}
+	if s.Lookup("help") == nil {
+		s.help = &CommandSpec{
+			Name:        "help",
+			Description: "Show available commands",
+			Args: []ArgSpec{{
+				Name:        "command",
+				Description: "Command to describe",
+				Variadic:    true,
+				Complete:    s.completeCommandNames,
+			}},
+		}
+		s.commands = append(s.commands, s.help)
+	}
+	return s
+}
+
+// Commands returns the registered specs, including the built-in help.
+func (s *CommandSet) Commands() []*CommandSpec {
+	return s.commands
+}
+
+// Items returns the commands in the form NewCombinedAutocompleteProvider expects.
+func (s *CommandSet) Items() []any {
+	items := make([]any, len(s.commands))
+	for i, c := range s.commands {
+		items[i] = c
+	}
+	return items
+}
+
+// Lookup finds a top-level command by name or alias.
+func (s *CommandSet) Lookup(name string) *CommandSpec {
+	for _, c := range s.commands {
+		if c.matches(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Parse turns submitted editor text such as `/git commit -m "fix bug"` into an
+// invocation. Words may be quoted with ' or " and a backslash escapes the next
+// character. Errors are *CommandError, or ErrNotACommand.
+func (s *CommandSet) Parse(text string) (*CommandInvocation, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return nil, ErrNotACommand
+	}
+	words, _, err := splitCommandLine(text[1:])
+	if err != nil {
+		return nil, &CommandError{Message: err.Error()}
+	}
+	if len(words) == 0 {
+		return nil, &CommandError{Message: "missing command name"}
+	}
+
+	spec := s.Lookup(words[0])
+	if spec == nil {
+		return nil, &CommandError{Message: fmt.Sprintf("unknown command /%s", words[0])}
+	}
+	inv, err := parseInvocation(spec, words[1:])
+	if err != nil {
+		return nil, err
+	}
+	if spec == s.help {
+		help, err := s.Help(inv.ArgList("command")...)
+		if err != nil {
+			return nil, err
+		}
+		inv.Help = help
+	} else if inv.Help != "" {
+		inv.Help = commandHelp(inv.Path, inv.Spec)
+	}
+	return inv, nil
+}
+
+// Help renders an overview of all commands, or the detailed help of the
+// command named by path (e.g. "git", "commit").
+func (s *CommandSet) Help(path ...string) (string, error) {
+	if len(path) == 0 {
+		var b strings.Builder
+		b.WriteString("Commands:\n")
+		rows := make([][2]string, 0, len(s.commands))
+		for _, c := range s.commands {
+			rows = append(rows, [2]string{"/" + c.Name, c.Description})
+		}
+		writeHelpRows(&b, rows)
+		return strings.TrimRight(b.String(), "\n"), nil
+	}
+
+	spec := s.Lookup(strings.TrimPrefix(path[0], "/"))
+	if spec == nil {
+		return "", &CommandError{Command: "/help", Message: fmt.Sprintf("unknown command /%s", strings.TrimPrefix(path[0], "/"))}
+	}
+	names := []string{spec.Name}
+	for _, name := range path[1:] {
+		sub := spec.subcommand(name)
+		if sub == nil {
+			return "", &CommandError{
+				Command: "/help",
+				Message: fmt.Sprintf("unknown subcommand %q of /%s", name, strings.Join(names, " ")),
+			}
+		}
+		spec = sub
+		names = append(names, sub.Name)
+	}
+	return commandHelp(names, spec), nil
+}
+
+func (s *CommandSet) completeCommandNames(prefix string) []AutocompleteItem {
+	var items []AutocompleteItem
+	for _, c := range s.commands {
+		if hasPrefixFold(c.Name, prefix) {
+			items = append(items, AutocompleteItem{Value: c.Name, Label: c.Name, Description: c.Description})
+		}
+	}
+	return items
+}
+
+func (c *CommandSpec) matches(name string) bool {
+	return c.Name == name || slices.Contains(c.Aliases, name)
+}
+
+func (c *CommandSpec) subcommand(name string) *CommandSpec {
+	for _, sub := range c.Subcommands {
+		if sub.matches(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+// findFlag looks a flag up by long or short name, innermost command first.
+func findFlag(chain []*CommandSpec, name string) *FlagSpec {
+	for i := len(chain) - 1; i >= 0; i-- {
+		for j := range chain[i].Flags {
+			f := &chain[i].Flags[j]
+			if f.Name == name || (f.Short != "" && f.Short == name) {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func parseInvocation(spec *CommandSpec, words []string) (*CommandInvocation, error) {
+	inv := &CommandInvocation{
+		Path:  []string{spec.Name},
+		Spec:  spec,
+		Args:  make(map[string][]string),
+		Flags: make(map[string]string),
+	}
+	chain := []*CommandSpec{spec}
+	fail := func(format string, a ...any) (*CommandInvocation, error) {
+		return nil, &CommandError{
+			Command: "/" + strings.Join(inv.Path, " "),
+			Message: fmt.Sprintf(format, a...),
+			Usage:   commandUsage(inv.Path, inv.Spec),
+		}
+	}
+
+	pos := 0
+	flagsDone := false
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !flagsDone && word == "--" {
+			flagsDone = true
+			continue
+		}
+
+		if !flagsDone && isFlagWord(word) {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			flag := findFlag(chain, name)
+			if flag == nil {
+				if name == "help" || name == "h" {
+					inv.Help = "requested"
+					return inv, nil
+				}
+				return fail("unknown flag %s", word)
+			}
+			switch {
+			case flag.Type == ArgBool && !hasValue:
+				value = "true"
+			case !hasValue:
+				if i+1 >= len(words) {
+					return fail("flag --%s requires a value", flag.Name)
+				}
+				i++
+				value = words[i]
+			}
+			if err := validateValue(value, flag.Type, flag.Choices); err != nil {
+				return fail("flag --%s: %v", flag.Name, err)
+			}
+			inv.Flags[flag.Name] = value
+			continue
+		}
+
+		cmd := chain[len(chain)-1]
+		if pos == 0 && len(cmd.Subcommands) > 0 {
+			if sub := cmd.subcommand(word); sub != nil {
+				chain = append(chain, sub)
+				inv.Spec = sub
+				inv.Path = append(inv.Path, sub.Name)
+				continue
+			}
+			if len(cmd.Args) == 0 {
+				return fail("unknown subcommand %q", word)
+			}
+		}
+
+		if pos >= len(cmd.Args) {
+			return fail("unexpected argument %q", word)
+		}
+		arg := cmd.Args[pos]
+		if err := validateValue(word, arg.Type, arg.Choices); err != nil {
+			return fail("argument <%s>: %v", arg.Name, err)
+		}
+		inv.Args[arg.Name] = append(inv.Args[arg.Name], word)
+		if !arg.Variadic {
+			pos++
+		}
+	}
+
+	cmd := inv.Spec
+	if len(cmd.Subcommands) > 0 && len(cmd.Args) == 0 {
+		return fail("missing subcommand (one of %s)", strings.Join(subcommandNames(cmd), ", "))
+	}
+	for _, arg := range cmd.Args {
+		if arg.Required && len(inv.Args[arg.Name]) == 0 {
+			return fail("missing required argument <%s>", arg.Name)
+		}
+	}
+	for _, c := range chain {
+		for _, f := range c.Flags {
+			if _, ok := inv.Flags[f.Name]; !ok && f.Default != "" {
+				inv.Flags[f.Name] = f.Default
+			}
+		}
+	}
+	return inv, nil
+}
+
+// isFlagWord reports whether word is a flag rather than a value such as "-" or "-5".
+func isFlagWord(word string) bool {
+	if len(word) < 2 || word[0] != '-' {
+		return false
+	}
+	return !unicode.IsDigit(rune(word[1]))
+}
+
+func validateValue(value string, typ ArgType, choices []string) error {
+	switch typ {
+	case ArgInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case ArgBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	}
+	if len(choices) > 0 && !slices.Contains(choices, value) {
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(choices, ", "))
+	}
+	return nil
+}
+
+func subcommandNames(c *CommandSpec) []string {
+	names := make([]string, len(c.Subcommands))
+	for i, sub := range c.Subcommands {
+		names[i] = sub.Name
+	}
+	return names
+}
+
+// splitCommandLine splits text into shell-like words. Single and double quotes
+// group words and a backslash escapes the next character outside single quotes.
+// lastStart is the byte offset where the final word begins, or len(text) when
+// the text ends in whitespace, so completion can replace the raw word.
+func splitCommandLine(text string) (words []string, lastStart int, err error) {
+	var b strings.Builder
+	inWord, escaped := false, false
+	var quote rune
+	lastStart = len(text)
+
+	for i, r := range text {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+			continue
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, b.String())
+				b.Reset()
+				inWord = false
+				lastStart = len(text)
+			}
+			continue
+		default:
+			b.WriteRune(r)
+		}
+		if !inWord {
+			inWord = true
+			lastStart = i
+		}
+	}
+	if inWord {
+		words = append(words, b.String())
+	}
+	if quote != 0 {
+		err = errors.New("unterminated quote")
+	} else if escaped {
+		err = errors.New("trailing backslash")
+	}
+	return words, lastStart, err
+}
+
+// quoteArg quotes a completion value that would otherwise split into words.
+func quoteArg(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"'\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// completeArguments returns completions for the text typed after "/name ".
+// The returned prefix is the raw trailing word the items replace.
+func (c *CommandSpec) completeArguments(text string) ([]AutocompleteItem, string) {
+	words, lastStart, _ := splitCommandLine(text)
+	prefix := text[lastStart:]
+	current := ""
+	if lastStart < len(text) && len(words) > 0 {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	chain := []*CommandSpec{c}
+	pos := 0
+	var pendingFlag *FlagSpec
+	flagsDone := false
+	for _, word := range words {
+		if pendingFlag != nil {
+			pendingFlag = nil
+			continue
+		}
+		if !flagsDone && word == "--" {
+			flagsDone = true
+			continue
+		}
+		if !flagsDone && isFlagWord(word) {
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if flag := findFlag(chain, name); flag != nil && flag.Type != ArgBool && !hasValue {
+				pendingFlag = flag
+			}
+			continue
+		}
+		cmd := chain[len(chain)-1]
+		if pos == 0 {
+			if sub := cmd.subcommand(word); sub != nil {
+				chain = append(chain, sub)
+				continue
+			}
+		}
+		if pos < len(cmd.Args) && !cmd.Args[pos].Variadic {
+			pos++
+		}
+	}
+	cmd := chain[len(chain)-1]
+
+	if pendingFlag != nil {
+		return completeValues(current, "", pendingFlag.Choices, pendingFlag.Complete), prefix
+	}
+
+	if !flagsDone && strings.HasPrefix(current, "-") {
+		if name, value, ok := strings.Cut(strings.TrimLeft(current, "-"), "="); ok {
+			flag := findFlag(chain, name)
+			if flag == nil {
+				return nil, prefix
+			}
+			return completeValues(value, "--"+flag.Name+"=", flag.Choices, flag.Complete), prefix
+		}
+		var items []AutocompleteItem
+		for i := len(chain) - 1; i >= 0; i-- {
+			for _, f := range chain[i].Flags {
+				if hasPrefixFold("--"+f.Name, current) || (f.Short != "" && current == "-"+f.Short) {
+					items = append(items, AutocompleteItem{Value: "--" + f.Name, Label: flagLabel(f), Description: f.Description})
+				}
+			}
+		}
+		return items, prefix
+	}
+
+	var items []AutocompleteItem
+	if pos == 0 {
+		for _, sub := range cmd.Subcommands {
+			if hasPrefixFold(sub.Name, current) {
+				items = append(items, AutocompleteItem{Value: sub.Name, Label: sub.Name, Description: sub.Description})
+			}
+		}
+	}
+	if pos < len(cmd.Args) {
+		arg := cmd.Args[pos]
+		items = append(items, completeValues(current, "", arg.Choices, arg.Complete)...)
+	}
+	return items, prefix
+}
+
+func completeValues(current, valuePrefix string, choices []string, complete func(string) []AutocompleteItem) []AutocompleteItem {
+	var items []AutocompleteItem
+	if len(choices) > 0 {
+		for _, choice := range choices {
+			if hasPrefixFold(choice, current) {
+				items = append(items, AutocompleteItem{Value: valuePrefix + quoteArg(choice), Label: choice})
+			}
+		}
+		return items
+	}
+	if complete == nil {
+		return nil
+	}
+	for _, item := range complete(current) {
+		item.Value = valuePrefix + quoteArg(item.Value)
+		items = append(items, item)
+	}
+	return items
+}
+
+func flagLabel(f FlagSpec) string {
+	label := "--" + f.Name
+	if f.Short != "" {
+		label = "-" + f.Short + ", " + label
+	}
+	if f.Type != ArgBool {
+		label += " <" + flagValueName(f) + ">"
+	}
+	return label
+}
+
+func flagValueName(f FlagSpec) string {
+	if len(f.Choices) > 0 {
+		return strings.Join(f.Choices, "|")
+	}
+	if f.Type == ArgInt {
+		return "n"
+	}
+	return "value"
+}
+
+// commandUsage renders the one-line synopsis, e.g. "/git commit [flags] <message>".
+func commandUsage(path []string, c *CommandSpec) string {
+	parts := []string{"/" + strings.Join(path, " ")}
+	if len(c.Subcommands) > 0 {
+		parts = append(parts, "<"+strings.Join(subcommandNames(c), "|")+">")
+	}
+	if len(c.Flags) > 0 {
+		parts = append(parts, "[flags]")
+	}
+	for _, arg := range c.Args {
+		name := arg.Name
+		if arg.Variadic {
+			name += "..."
+		}
+		if arg.Required {
+			parts = append(parts, "<"+name+">")
+		} else {
+			parts = append(parts, "["+name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func commandHelp(path []string, c *CommandSpec) string {
+	var b strings.Builder
+	b.WriteString("Usage: " + commandUsage(path, c) + "\n")
+	if c.Description != "" {
+		b.WriteString("\n" + c.Description + "\n")
+	}
+	if len(c.Aliases) > 0 {
+		b.WriteString("\nAliases: " + strings.Join(c.Aliases, ", ") + "\n")
+	}
+
+	if len(c.Subcommands) > 0 {
+		b.WriteString("\nSubcommands:\n")
+		rows := make([][2]string, 0, len(c.Subcommands))
+		for _, sub := range c.Subcommands {
+			rows = append(rows, [2]string{sub.Name, sub.Description})
+		}
+		writeHelpRows(&b, rows)
+	}
+	if len(c.Args) > 0 {
+		b.WriteString("\nArguments:\n")
+		rows := make([][2]string, 0, len(c.Args))
+		for _, arg := range c.Args {
+			desc := arg.Description
+			if len(arg.Choices) > 0 {
+				desc = strings.TrimSpace(desc + " (" + strings.Join(arg.Choices, ", ") + ")")
+			}
+			rows = append(rows, [2]string{arg.Name, desc})
+		}
+		writeHelpRows(&b, rows)
+	}
+	if len(c.Flags) > 0 {
+		b.WriteString("\nFlags:\n")
+		rows := make([][2]string, 0, len(c.Flags))
+		for _, f := range c.Flags {
+			desc := f.Description
+			if f.Default != "" {
+				desc = strings.TrimSpace(desc + " (default " + f.Default + ")")
+			}
+			rows = append(rows, [2]string{flagLabel(f), desc})
+		}
+		writeHelpRows(&b, rows)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func writeHelpRows(b *strings.Builder, rows [][2]string) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row[0]))
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			b.WriteString("  " + row[0] + "\n")
+			continue
+		}
+		fmt.Fprintf(b, "  %-*s  %s\n", width, row[0], row[1])
+	}
+}
@@ -0,0 +1,179 @@
+package components
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testCommandSet() *CommandSet {
+	return NewCommandSet(
+		&CommandSpec{
+			Name:        "model",
+			Aliases:     []string{"m"},
+			Description: "Switch model",
+			Args: []ArgSpec{{
+				Name:     "name",
+				Required: true,
+				Choices:  []string{"fast", "smart"},
+			}},
+			Flags: []FlagSpec{
+				{Name: "temperature", Short: "t", Type: ArgInt, Default: "1"},
+				{Name: "save", Type: ArgBool, Description: "Persist the choice"},
+			},
+		},
+		&CommandSpec{
+			Name:        "git",
+			Description: "Run git helpers",
+			Flags:       []FlagSpec{{Name: "verbose", Short: "v", Type: ArgBool}},
+			Subcommands: []*CommandSpec{
+				{
+					Name:        "commit",
+					Description: "Commit staged changes",
+					Args:        []ArgSpec{{Name: "message", Required: true}},
+					Flags:       []FlagSpec{{Name: "author", Complete: func(prefix string) []AutocompleteItem { return []AutocompleteItem{{Value: "Jane Doe"}} }}},
+				},
+				{
+					Name: "add",
+					Args: []ArgSpec{{Name: "paths", Variadic: true}},
+				},
+			},
+		},
+	)
+}
+
+func TestCommandSetParse(t *testing.T) {
+	s := testCommandSet()
+
+	inv, err := s.Parse(`/m smart -t 3 --save`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Spec.Name != "model" || inv.Arg("name") != "smart" || inv.Int("temperature") != 3 || !inv.Bool("save") {
+		t.Fatalf("unexpected invocation %+v", inv)
+	}
+
+	inv, err = s.Parse(`/git -v commit "fix the bug" --author='Jane Doe'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(inv.Path, []string{"git", "commit"}) || inv.Arg("message") != "fix the bug" ||
+		inv.Flag("author") != "Jane Doe" || !inv.Bool("verbose") {
+		t.Fatalf("unexpected invocation %+v", inv)
+	}
+
+	inv, err = s.Parse(`/git add a.go -- -b.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(inv.ArgList("paths"), []string{"a.go", "-b.go"}) {
+		t.Fatalf("paths = %v", inv.ArgList("paths"))
+	}
+
+	inv, err = s.Parse(`/model fast`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Flag("temperature") != "1" {
+		t.Fatalf("default not applied: %v", inv.Flags)
+	}
+}
+
+func TestCommandSetParseErrors(t *testing.T) {
+	s := testCommandSet()
+
+	if _, err := s.Parse("hello"); !errors.Is(err, ErrNotACommand) {
+		t.Fatalf("plain text: err = %v", err)
+	}
+
+	cases := map[string]string{
+		`/nope`:             "unknown command /nope",
+		`/model`:            "missing required argument <name>",
+		`/model slow`:       `"slow" is not one of fast, smart`,
+		`/model fast -t hi`: `"hi" is not an integer`,
+		`/model fast --x`:   "unknown flag --x",
+		`/model fast extra`: `unexpected argument "extra"`,
+		`/model fast -t`:    "requires a value",
+		`/git`:              "missing subcommand",
+		`/git push`:         `unknown subcommand "push"`,
+		`/git commit "oops`: "unterminated quote",
+	}
+	for input, want := range cases {
+		_, err := s.Parse(input)
+		var cmdErr *CommandError
+		if !errors.As(err, &cmdErr) || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want containing %q", input, err, want)
+		}
+	}
+}
+
+func TestCommandSetHelp(t *testing.T) {
+	s := testCommandSet()
+
+	inv, err := s.Parse("/help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/model", "Switch model", "/git", "/help"} {
+		if !strings.Contains(inv.Help, want) {
+			t.Errorf("overview missing %q:\n%s", want, inv.Help)
+		}
+	}
+
+	inv, err = s.Parse("/git commit --help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(inv.Help, "Usage: /git commit [flags] <message>") {
+		t.Errorf("unexpected help:\n%s", inv.Help)
+	}
+
+	inv, err = s.Parse("/help model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Aliases: m", "-t, --temperature <n>", "(default 1)", "(fast, smart)"} {
+		if !strings.Contains(inv.Help, want) {
+			t.Errorf("model help missing %q:\n%s", want, inv.Help)
+		}
+	}
+}
+
+func TestCommandSpecCompletion(t *testing.T) {
+	s := testCommandSet()
+	p := NewCombinedAutocompleteProvider(s.Items(), t.TempDir(), "")
+
+	cases := []struct {
+		line   string
+		prefix string
+		values []string
+	}{
+		{"/model ", "", []string{"fast", "smart"}},
+		{"/model sm", "sm", []string{"smart"}},
+		{"/model fast --", "--", []string{"--temperature", "--save"}},
+		{"/git ", "", []string{"commit", "add"}},
+		{"/git commit msg --author ", "", []string{`"Jane Doe"`}},
+		{"/git commit msg --author=J", "--author=J", []string{`--author="Jane Doe"`}},
+		{"/help g", "g", []string{"git"}},
+	}
+	for _, c := range cases {
+		got := p.GetSuggestions([]string{c.line}, 0, len(c.line))
+		if got == nil {
+			t.Errorf("%q: no suggestions", c.line)
+			continue
+		}
+		var values []string
+		for _, item := range got.Items {
+			values = append(values, item.Value)
+		}
+		if got.Prefix != c.prefix || !slices.Equal(values, c.values) {
+			t.Errorf("%q: prefix %q values %v, want %q %v", c.line, got.Prefix, values, c.prefix, c.values)
+		}
+	}
+
+	result := p.ApplyCompletion([]string{"/model sm"}, 0, 9, AutocompleteItem{Value: "smart"}, "sm")
+	if result.Lines[0] != "/model smart" {
+		t.Fatalf("ApplyCompletion = %q", result.Lines[0])
+	}
+}
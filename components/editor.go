@@ -1,17 +1,23 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/clipperhouse/uax29/v2/graphemes"
 	"github.com/yeeaiclub/fasttui"
 	"github.com/yeeaiclub/fasttui/keys"
+	"github.com/yeeaiclub/fasttui/style"
 )
 
-var _ fasttui.Component = (*Editor)(nil)
+var (
+	_ fasttui.Component  = (*Editor)(nil)
+	_ fasttui.ThemeAware = (*Editor)(nil)
+)
 
 type Editor struct {
 	isInPaste    bool
@@ -37,6 +43,18 @@ type Editor struct {
 	autocompleteMaxVisible  int
 	autocompleteSelectTheme SelectListTheme
 
+	// poster runs timer callbacks on the UI event loop, e.g. replaying a key
+	// sequence prefix that timed out
+	poster eventPoster
+
+	// Async autocomplete: requests are debounced, sequenced and cancelled when stale
+	autocompletePoster   eventPoster
+	autocompleteDebounce time.Duration
+	autocompleteSeq      uint64
+	autocompleteCancel   context.CancelFunc
+	autocompleteTimer    *time.Timer
+	autocompleteLoading  bool
+
 	// Callbacks
 	OnSubmit func(text string)
 	OnChange func(text string)
@@ -48,6 +66,12 @@ type Editor struct {
 	// Kill ring for Emacs-style operations
 	killRing    []string
 	borderColor func(string) string
+
+	// External editor support
+	releaser     terminalReleaser
+	tempFileExt  string
+	errorMessage string
+	errorColor   func(string) string
 }
 
 // EditorOption configures optional behavior and theming of Editor.
@@ -60,6 +84,13 @@ func WithEditorBorderColor(color func(string) string) EditorOption {
 	}
 }
 
+// WithEditorStyle themes the editor and its autocomplete list, see SetTheme.
+func WithEditorStyle(theme *style.Theme) EditorOption {
+	return func(e *Editor) {
+		e.SetTheme(theme)
+	}
+}
+
 type EditorState struct {
 	lines      []string
 	cursorLine int
@@ -78,7 +109,11 @@ func NewEditor(term fasttui.Terminal, submit func(text string), opts ...EditorOp
 		borderColor: func(s string) string {
 			return s
 		},
+		errorColor: func(s string) string {
+			return s
+		},
 		autocompleteMaxVisible: 5,
+		autocompleteDebounce:   defaultAutocompleteDebounce,
 	}
 
 	for _, opt := range opts {
@@ -107,7 +142,7 @@ func (e *Editor) SetAutocomplete(provider AutocompleteProvider, theme SelectList
 }
 
 func (e *Editor) HandleInput(data string) {
-	kb := keys.GetEditorKeybindings()
+	e.errorMessage = ""
 
 	// Handle bracketed paste mode
 	if strings.Contains(data, "\x1b[200~") {
@@ -135,6 +170,35 @@ func (e *Editor) HandleInput(data string) {
 		return
 	}
 
+	kb := keys.GetEditorKeybindings()
+	stroke := kb.Feed(data)
+	for _, key := range stroke.Replay() {
+		e.handleKey(key, kb)
+	}
+	if stroke.Pending() {
+		if e.poster != nil {
+			poster := e.poster
+			stroke.OnTimeout(func(abandoned []string) {
+				poster.Post(func() {
+					for _, key := range abandoned {
+						e.handleKey(key, kb)
+					}
+				})
+			})
+		}
+		return
+	}
+	e.handleKey(data, stroke)
+}
+
+// keyMatcher resolves input to keybinding actions. Implemented by
+// *keys.EditorKeybindingsManager and *keys.KeyStroke.
+type keyMatcher interface {
+	Matches(data string, action keys.EditorAction) bool
+}
+
+// handleKey dispatches one key press to the matching editor action.
+func (e *Editor) handleKey(data string, kb keyMatcher) {
 	// Ctrl+C - let parent handle (exit/clear)
 	if kb.Matches(data, keys.EditorActionCopy) {
 		if e.OnCancel != nil {
@@ -149,6 +213,18 @@ func (e *Editor) HandleInput(data string) {
 		return
 	}
 
+	// Edit in $VISUAL / $EDITOR
+	if e.releaser != nil && kb.Matches(data, keys.EditorActionExternalEditor) {
+		e.openExternalEditor()
+		return
+	}
+
+	// Cancel suggestions that are still loading
+	if e.autocompleteLoading && e.autocompleteList == nil && kb.Matches(data, keys.EditorActionSelectCancel) {
+		e.cancelAutocomplete()
+		return
+	}
+
 	// Handle autocomplete mode
 	if e.autocompleteState != "" && e.autocompleteList != nil && e.autocompleteProvider != nil {
 		// Cancel autocomplete
@@ -333,9 +409,17 @@ func (e *Editor) HandleInput(data string) {
 		return
 	}
 
+	// A completed sequence bound to an action the editor doesn't handle
+	// must not leak its last key into the text.
+	if stroke, ok := kb.(*keys.KeyStroke); ok && stroke.Sequence() != "" {
+		return
+	}
+
 	// Regular characters
 	if len(data) > 0 && data[0] >= 32 {
 		e.insertCharacter(data)
+	} else if text := keys.KeyText(data); text != "" {
+		e.insertCharacter(text)
 	}
 }
 
@@ -422,6 +506,11 @@ func (e *Editor) Render(width int) []string {
 		result = append(result, e.fillWithHorizontal(width))
 	}
 
+	if e.errorMessage != "" {
+		msg := fasttui.TruncateToWidth(e.errorMessage, contentWidth, "...", false)
+		result = append(result, leftPadding+e.errorColor(msg))
+	}
+
 	// Render autocomplete list if active
 	if e.autocompleteState != "" && e.autocompleteList != nil {
 		acLines := e.autocompleteList.Render(contentWidth)
@@ -430,6 +519,10 @@ func (e *Editor) Render(width int) []string {
 			padding := strings.Repeat(" ", max(0, contentWidth-lineWidth))
 			result = append(result, leftPadding+line+padding+rightPadding)
 		}
+	} else if e.autocompleteLoading {
+		line := e.renderAutocompleteLoading()
+		padding := strings.Repeat(" ", max(0, contentWidth-fasttui.VisibleWidth(line)))
+		result = append(result, leftPadding+line+padding+rightPadding)
 	}
 
 	return result
@@ -523,6 +616,23 @@ func (e *Editor) SetFocused(focused bool) {
 	e.focused = focused
 }
 
+// SetTheme colors the border with the border token, or borderAccent while
+// focused, and messages with the error token, and styles the autocomplete
+// list with SelectListThemeFrom.
+func (e *Editor) SetTheme(theme *style.Theme) {
+	e.borderColor = func(s string) string {
+		if e.focused {
+			return theme.Fg(style.ColorBorderAccent, s)
+		}
+		return theme.Fg(style.ColorBorder, s)
+	}
+	e.errorColor = func(s string) string { return theme.Fg(style.ColorError, s) }
+	e.autocompleteSelectTheme = SelectListThemeFrom(theme)
+	if e.autocompleteList != nil {
+		e.autocompleteList.SetTheme(theme)
+	}
+}
+
 func (e *Editor) WantsKeyRelease() bool {
 	return false
 }
@@ -1662,6 +1772,7 @@ func (e *Editor) undo() {
 // handleSubmit handles submit action
 func (e *Editor) handleSubmit() {
 	result := strings.TrimSpace(strings.Join(e.state.lines, "\n"))
+	e.cancelAutocomplete()
 
 	e.state = EditorState{lines: []string{""}, cursorLine: 0, cursorCol: 0}
 	e.historyIndex = -1
@@ -1933,25 +2044,17 @@ func (e *Editor) tryTriggerAutocomplete(explicitTab bool) {
 		}
 	}
 
-	suggestions := e.autocompleteProvider.GetSuggestions(e.state.lines, e.state.cursorLine, e.state.cursorCol)
-	if suggestions == nil || len(suggestions.Items) == 0 {
-		e.cancelAutocomplete()
-		return
+	// Explicit Tab skips the debounce; the user is waiting for the list
+	debounce := e.autocompleteDebounce
+	if explicitTab {
+		debounce = 0
 	}
-
-	e.autocompletePrefix = suggestions.Prefix
-
-	items := make([]SelectItem, len(suggestions.Items))
-	for i, it := range suggestions.Items {
-		items[i] = SelectItem{
-			Label:       it.Label,
-			Value:       it.Value,
-			Description: it.Description,
-		}
+	if e.requestAsyncSuggestions(debounce) {
+		return
 	}
 
-	e.autocompleteList = NewSelectList(items, e.autocompleteMaxVisible, WithSelectListTheme(e.autocompleteSelectTheme))
-	e.autocompleteState = "regular"
+	suggestions := e.autocompleteProvider.GetSuggestions(e.state.lines, e.state.cursorLine, e.state.cursorCol)
+	e.showSuggestions(suggestions, "regular")
 }
 
 // handleTabCompletion handles Tab key when not already in autocomplete mode.
@@ -1992,7 +2095,28 @@ func (e *Editor) forceFileAutocomplete(explicitTab bool) {
 		return
 	}
 
+	// Listing directories can be slow, so keep it off the event loop when possible
+	if e.autocompletePoster != nil {
+		debounce := e.autocompleteDebounce
+		if explicitTab {
+			debounce = 0
+		}
+		query := func(_ context.Context, lines []string, cursorLine, cursorCol int) *AutocompleteSuggestions {
+			return provider.GetForceFileSuggestions(lines, cursorLine, cursorCol)
+		}
+		e.querySuggestionsAsync(debounce, "force", query, func(suggestions *AutocompleteSuggestions) {
+			e.applyForceFileSuggestions(provider, suggestions, explicitTab)
+		})
+		return
+	}
+
 	suggestions := provider.GetForceFileSuggestions(e.state.lines, e.state.cursorLine, e.state.cursorCol)
+	e.applyForceFileSuggestions(provider, suggestions, explicitTab)
+}
+
+// applyForceFileSuggestions shows forced file suggestions, or completes the only
+// one right away when Tab was pressed.
+func (e *Editor) applyForceFileSuggestions(provider *CombinedAutocompleteProvider, suggestions *AutocompleteSuggestions, explicitTab bool) {
 	if suggestions == nil || len(suggestions.Items) == 0 {
 		e.cancelAutocomplete()
 		return
@@ -2001,6 +2125,7 @@ func (e *Editor) forceFileAutocomplete(explicitTab bool) {
 	// If there's exactly one suggestion and Tab explicitly pressed, apply immediately.
 	if explicitTab && len(suggestions.Items) == 1 {
 		item := suggestions.Items[0]
+		e.cancelAutocomplete() // drop the loading state of an async request
 		e.pushUndoSnapshot()
 		e.lastAction = ""
 		res := provider.ApplyCompletion(e.state.lines, e.state.cursorLine, e.state.cursorCol, item, suggestions.Prefix)
@@ -2015,21 +2140,12 @@ func (e *Editor) forceFileAutocomplete(explicitTab bool) {
 		return
 	}
 
-	e.autocompletePrefix = suggestions.Prefix
-	items := make([]SelectItem, len(suggestions.Items))
-	for i, it := range suggestions.Items {
-		items[i] = SelectItem{
-			Label:       it.Label,
-			Value:       it.Value,
-			Description: it.Description,
-		}
-	}
-	e.autocompleteList = NewSelectList(items, e.autocompleteMaxVisible, WithSelectListTheme(e.autocompleteSelectTheme))
-	e.autocompleteState = "force"
+	e.showSuggestions(suggestions, "force")
 }
 
 // cancelAutocomplete hides autocomplete UI.
 func (e *Editor) cancelAutocomplete() {
+	e.cancelPendingSuggestions()
 	e.autocompleteState = ""
 	e.autocompleteList = nil
 	e.autocompletePrefix = ""
@@ -2051,22 +2167,12 @@ func (e *Editor) updateAutocomplete() {
 		return
 	}
 
-	suggestions := e.autocompleteProvider.GetSuggestions(e.state.lines, e.state.cursorLine, e.state.cursorCol)
-	if suggestions == nil || len(suggestions.Items) == 0 {
-		e.cancelAutocomplete()
+	if e.requestAsyncSuggestions(e.autocompleteDebounce) {
 		return
 	}
 
-	e.autocompletePrefix = suggestions.Prefix
-	items := make([]SelectItem, len(suggestions.Items))
-	for i, it := range suggestions.Items {
-		items[i] = SelectItem{
-			Label:       it.Label,
-			Value:       it.Value,
-			Description: it.Description,
-		}
-	}
-	e.autocompleteList = NewSelectList(items, e.autocompleteMaxVisible, WithSelectListTheme(e.autocompleteSelectTheme))
+	suggestions := e.autocompleteProvider.GetSuggestions(e.state.lines, e.state.cursorLine, e.state.cursorCol)
+	e.showSuggestions(suggestions, e.autocompleteState)
 }
 
 // applyAutocompleteItem applies the currently selected autocomplete item.
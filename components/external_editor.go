@@ -0,0 +1,138 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/yeeaiclub/fasttui"
+)
+
+const defaultExternalEditorExt = ".md"
+
+// terminalReleaser hands the TTY to another process for the duration of fn.
+type terminalReleaser interface {
+	ReleaseTerminal(fn func() error) error
+}
+
+// WithEditorExternalEditor enables the external editor action (ctrl+g by default).
+// The TUI is suspended while $VISUAL or $EDITOR edits the current text.
+func WithEditorExternalEditor(ui *fasttui.TUI) EditorOption {
+	return func(e *Editor) {
+		if ui != nil {
+			e.releaser = ui
+		}
+	}
+}
+
+// WithEditorTempFileExt sets the extension of the temp file handed to the external
+// editor, which usually selects its syntax mode. Defaults to ".md".
+func WithEditorTempFileExt(ext string) EditorOption {
+	return func(e *Editor) {
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		e.tempFileExt = ext
+	}
+}
+
+// WithEditorErrorColor sets the color function used for the inline error line.
+func WithEditorErrorColor(color func(string) string) EditorOption {
+	return func(e *Editor) {
+		e.errorColor = color
+	}
+}
+
+// externalEditorCommand returns the user's editor command split into fields:
+// $VISUAL, then $EDITOR, then a platform default.
+func externalEditorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// runExternalEditor writes text to a temp file, runs the editor on it attached to
+// the process's stdio and returns the edited content.
+func runExternalEditor(text, ext string) (string, error) {
+	f, err := os.CreateTemp("", "fasttui-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	_, err = f.WriteString(text)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	args := externalEditorCommand()
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		name := filepath.Base(args[0])
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("%s exited with status %d", name, exitErr.ExitCode())
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	// Editors terminate the last line; the prompt should not gain a blank line
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	return strings.TrimSuffix(content, "\n"), nil
+}
+
+// openExternalEditor edits the current text in the external editor and replaces
+// the content with the result. Failures are shown below the editor until the next key.
+func (e *Editor) openExternalEditor() {
+	if e.releaser == nil {
+		return
+	}
+	ext := e.tempFileExt
+	if ext == "" {
+		ext = defaultExternalEditorExt
+	}
+
+	var edited string
+	err := e.releaser.ReleaseTerminal(func() error {
+		var runErr error
+		edited, runErr = runExternalEditor(e.GetTextString(), ext)
+		return runErr
+	})
+	if err != nil {
+		e.errorMessage = "External editor: " + err.Error()
+		return
+	}
+
+	e.cancelAutocomplete()
+	e.pushUndoSnapshot()
+	e.historyIndex = -1
+	e.lastAction = ""
+	e.SetText(strings.Split(edited, "\n"))
+	last := len(e.state.lines) - 1
+	e.SetCursor(last, len(e.state.lines[last]))
+
+	if e.OnChange != nil {
+		e.OnChange(e.GetTextString())
+	}
+}
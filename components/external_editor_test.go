@@ -0,0 +1,75 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type fakeReleaser struct{ calls int }
+
+func (f *fakeReleaser) ReleaseTerminal(fn func() error) error {
+	f.calls++
+	return fn()
+}
+
+func writeEditorScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script editors are not available on windows")
+	}
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEditorExternalEditorReplacesText(t *testing.T) {
+	script := writeEditorScript(t, `case "$1" in *.txt) ;; *) exit 3 ;; esac
+printf 'edited\nsecond line\n' > "$1"`)
+	t.Setenv("VISUAL", script)
+
+	releaser := &fakeReleaser{}
+	e := NewEditor(&mockEditorTerm{w: 40, h: 10}, nil, WithEditorTempFileExt("txt"))
+	e.releaser = releaser
+	e.SetText([]string{"draft"})
+
+	e.HandleInput("\x07") // ctrl+g
+
+	if releaser.calls != 1 {
+		t.Fatalf("expected the terminal to be released once, got %d", releaser.calls)
+	}
+	if got := e.GetTextString(); got != "edited\nsecond line" {
+		t.Fatalf("unexpected text after external edit: %q", got)
+	}
+	if line, col := e.GetCursor(); line != 1 || col != len("second line") {
+		t.Fatalf("cursor should be at end of text, got %d,%d", line, col)
+	}
+}
+
+func TestEditorExternalEditorShowsError(t *testing.T) {
+	t.Setenv("VISUAL", writeEditorScript(t, "exit 2"))
+
+	e := NewEditor(&mockEditorTerm{w: 60, h: 10}, nil)
+	e.releaser = &fakeReleaser{}
+	e.SetText([]string{"keep me"})
+
+	e.HandleInput("\x07")
+
+	if got := e.GetTextString(); got != "keep me" {
+		t.Fatalf("text should be unchanged after a failed edit, got %q", got)
+	}
+	lines := renderEditorLinesForTest(t, e, 60, 10)
+	if !strings.Contains(strings.Join(lines, "\n"), "exited with status 2") {
+		t.Fatalf("expected inline error, got %q", lines)
+	}
+
+	e.HandleInput("x")
+	lines = renderEditorLinesForTest(t, e, 60, 10)
+	if strings.Contains(strings.Join(lines, "\n"), "External editor") {
+		t.Fatalf("error should clear on the next key, got %q", lines)
+	}
+}
@@ -0,0 +1,231 @@
+package components
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultIndexRefreshInterval = 2 * time.Second
+	defaultIndexMaxEntries      = 100000
+)
+
+// FileIndexEntry is one indexed path. Path is slash-separated and relative to the
+// index root; directories carry a trailing "/" like fd output.
+type FileIndexEntry struct {
+	Path  string
+	IsDir bool
+}
+
+// FileIndex is a cached listing of a directory tree used for @-file completion
+// when fd is not available. It honours .gitignore, .ignore, .git/info/exclude
+// and the global git excludes file, and skips hidden entries.
+//
+// Refreshing is incremental: a directory is only listed again when its mtime,
+// its own ignore files or the ignore files of an ancestor changed since the last
+// scan.
+type FileIndex struct {
+	root            string
+	refreshInterval time.Duration
+	maxEntries      int
+
+	mu          sync.Mutex
+	dirs        map[string]*indexedDir
+	entries     []FileIndexEntry
+	lastRefresh time.Time
+	rootMtime   time.Time // newest mtime of the global and .git/info excludes
+}
+
+type indexedDir struct {
+	mtime       time.Time
+	ignoreMtime time.Time     // newest mtime of this directory's .gitignore/.ignore
+	files       []string      // relative paths of non-ignored files
+	subdirs     []string      // relative paths of non-ignored directories
+	rules       []ignoreRules // rule stack that applies to this directory's children
+}
+
+// NewFileIndex creates an index rooted at root. Nothing is scanned until the
+// first call to Entries.
+func NewFileIndex(root string) *FileIndex {
+	return &FileIndex{
+		root:            root,
+		refreshInterval: defaultIndexRefreshInterval,
+		maxEntries:      defaultIndexMaxEntries,
+	}
+}
+
+// Entries returns all indexed paths, shallow entries first. The tree is
+// re-checked at most once per refresh interval.
+func (x *FileIndex) Entries(ctx context.Context) []FileIndexEntry {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.dirs == nil || time.Since(x.lastRefresh) >= x.refreshInterval {
+		x.refresh(ctx)
+	}
+	return x.entries
+}
+
+// refresh walks the tree concurrently, reusing unchanged directory listings.
+// A cancelled scan keeps the previous index.
+func (x *FileIndex) refresh(ctx context.Context) {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		count atomic.Int64
+		sem   = make(chan struct{}, runtime.NumCPU()*2)
+		dirs  = make(map[string]*indexedDir)
+	)
+
+	var walk func(rel string, stack []ignoreRules, stackChanged bool)
+	walk = func(rel string, stack []ignoreRules, stackChanged bool) {
+		defer wg.Done()
+		if ctx.Err() != nil || count.Load() >= int64(x.maxEntries) {
+			return
+		}
+
+		sem <- struct{}{}
+		node, rulesChanged := x.scanDir(rel, stack, stackChanged)
+		<-sem
+		if node == nil {
+			return
+		}
+		count.Add(int64(len(node.files) + len(node.subdirs)))
+
+		mu.Lock()
+		dirs[rel] = node
+		mu.Unlock()
+
+		for _, sub := range node.subdirs {
+			wg.Add(1)
+			go walk(sub, node.rules, rulesChanged)
+		}
+	}
+
+	globalFile := globalIgnoreFile()
+	excludeFile := filepath.Join(x.root, ".git", "info", "exclude")
+	rootMtime := newestMtime(globalFile, excludeFile)
+
+	wg.Add(1)
+	go walk("", rootRules(globalFile, excludeFile), x.dirs == nil || !rootMtime.Equal(x.rootMtime))
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	x.dirs = dirs
+	x.entries = flattenIndex(dirs)
+	x.lastRefresh = time.Now()
+	x.rootMtime = rootMtime
+}
+
+// rootRules returns the rules that apply to the whole tree before any
+// .gitignore: the global excludes file and .git/info/exclude.
+func rootRules(files ...string) []ignoreRules {
+	var stack []ignoreRules
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		if rs, ok := loadIgnoreFile("", file); ok {
+			stack = append(stack, rs)
+		}
+	}
+	return stack
+}
+
+// scanDir lists one directory, or returns the previous listing when nothing
+// that affects it has changed. The second result reports whether the rule stack
+// handed to its children differs from the previous scan.
+func (x *FileIndex) scanDir(rel string, stack []ignoreRules, stackChanged bool) (*indexedDir, bool) {
+	abs := filepath.Join(x.root, filepath.FromSlash(rel))
+	info, err := os.Stat(abs)
+	if err != nil || !info.IsDir() {
+		return nil, false
+	}
+	ignoreMtime := newestMtime(filepath.Join(abs, ".gitignore"), filepath.Join(abs, ".ignore"))
+
+	old := x.dirs[rel]
+	rulesChanged := stackChanged || old == nil || !old.ignoreMtime.Equal(ignoreMtime)
+	if !rulesChanged && old.mtime.Equal(info.ModTime()) {
+		return old, false
+	}
+
+	node := &indexedDir{
+		mtime:       info.ModTime(),
+		ignoreMtime: ignoreMtime,
+		rules:       slices.Clip(stack),
+	}
+	for _, name := range []string{".gitignore", ".ignore"} {
+		if rs, ok := loadIgnoreFile(rel, filepath.Join(abs, name)); ok {
+			node.rules = append(node.rules, rs)
+		}
+	}
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return node, rulesChanged
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		child := path.Join(rel, name)
+		// Symlinked directories are listed as files to avoid cycles.
+		isDir := entry.IsDir()
+		if isIgnored(node.rules, child, isDir) {
+			continue
+		}
+		if isDir {
+			node.subdirs = append(node.subdirs, child)
+		} else {
+			node.files = append(node.files, child)
+		}
+	}
+	return node, rulesChanged
+}
+
+func newestMtime(files ...string) time.Time {
+	var newest time.Time
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest
+}
+
+// flattenIndex orders entries by depth, then path, so an empty query lists the
+// top level first.
+func flattenIndex(dirs map[string]*indexedDir) []FileIndexEntry {
+	var entries []FileIndexEntry
+	for _, node := range dirs {
+		for _, file := range node.files {
+			entries = append(entries, FileIndexEntry{Path: file})
+		}
+		for _, dir := range node.subdirs {
+			entries = append(entries, FileIndexEntry{Path: dir + "/", IsDir: true})
+		}
+	}
+	slices.SortFunc(entries, func(a, b FileIndexEntry) int {
+		da := strings.Count(strings.TrimSuffix(a.Path, "/"), "/")
+		db := strings.Count(strings.TrimSuffix(b.Path, "/"), "/")
+		if da != db {
+			return da - db
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return entries
+}
@@ -0,0 +1,148 @@
+package components
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func indexPaths(x *FileIndex) []string {
+	var paths []string
+	for _, e := range x.Entries(context.Background()) {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestIgnoreRuleMatching(t *testing.T) {
+	rs := parseIgnoreRules("", strings.Join([]string{
+		"# comment",
+		"*.log",
+		"!keep.log",
+		"build/",
+		"/root-only.txt",
+		"docs/**/*.tmp",
+	}, "\n"))
+
+	cases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"a.log", false, true},
+		{"nested/b.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"docs/x.tmp", false, true},
+		{"docs/a/b/x.tmp", false, true},
+		{"other/x.tmp", false, false},
+	}
+	for _, c := range cases {
+		if got := isIgnored([]ignoreRules{rs}, c.path, c.isDir); got != c.ignored {
+			t.Errorf("isIgnored(%q, dir=%v) = %v, want %v", c.path, c.isDir, got, c.ignored)
+		}
+	}
+}
+
+func TestFileIndexHonoursIgnoreFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":            "*.log\nbuild/\n",
+		".git/info/exclude":     "secret.txt\n",
+		".hidden/file.txt":      "",
+		"main.go":               "",
+		"debug.log":             "",
+		"secret.txt":            "",
+		"build/out.bin":         "",
+		"src/app.go":            "",
+		"src/.ignore":           "gen/\n",
+		"src/gen/types.go":      "",
+		"src/lib/.gitignore":    "!important.log\n",
+		"src/lib/important.log": "",
+		"src/lib/other.log":     "",
+	})
+
+	x := NewFileIndex(root)
+	got := indexPaths(x)
+	want := []string{
+		"main.go",
+		"src/",
+		"src/app.go",
+		"src/lib/",
+		"src/lib/important.log",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+}
+
+func TestFileIndexIncrementalRefresh(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.txt":     "",
+		"sub/b.txt": "",
+	})
+
+	x := NewFileIndex(root)
+	x.refreshInterval = 0
+	if got := indexPaths(x); !slices.Contains(got, "sub/b.txt") {
+		t.Fatalf("initial scan missing sub/b.txt: %v", got)
+	}
+
+	writeTree(t, root, map[string]string{"sub/c.txt": ""})
+	// Force a distinct mtime on filesystems with coarse timestamps.
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(root, "sub"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if got := indexPaths(x); !slices.Contains(got, "sub/c.txt") {
+		t.Fatalf("refresh missed new file: %v", got)
+	}
+
+	writeTree(t, root, map[string]string{".gitignore": "sub/\n"})
+	if got := indexPaths(x); slices.Contains(got, "sub/") || slices.Contains(got, "sub/b.txt") {
+		t.Fatalf("new .gitignore not applied: %v", got)
+	}
+}
+
+func TestFuzzyFileSuggestionsWithoutFd(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"README.md":            "",
+		"components/editor.go": "",
+		"components/input.go":  "",
+	})
+
+	p := NewCombinedAutocompleteProvider(nil, root, "definitely-not-fd-binary")
+	if p.fdPath != "" {
+		t.Fatalf("missing fd binary should fall back to the native index")
+	}
+	items := p.getFuzzyFileSuggestions(context.Background(), "editor")
+	if len(items) == 0 || items[0].Value != "@components/editor.go" {
+		t.Fatalf("suggestions = %+v, want @components/editor.go first", items)
+	}
+}
@@ -2,8 +2,10 @@ package components
 
 import (
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"unicode"
 )
 
 var (
@@ -16,87 +18,469 @@ var (
 type FuzzyMatch struct {
 	Matches bool
 	Score   float64
+	// Positions holds the rune indices of text that matched, in ascending order.
+	Positions []int
+}
+
+// Scoring follows fzf's Smith-Waterman variant: every matched rune earns
+// scoreMatch plus a bonus depending on what precedes it, and gaps between
+// matched runes are penalised with an affine cost.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary            = scoreMatch / 2
+	bonusNonWord             = scoreMatch / 2
+	bonusCamel123            = bonusBoundary + scoreGapExtension
+	bonusConsecutive         = -(scoreGapStart + scoreGapExtension)
+	bonusBoundaryWhite       = bonusBoundary + 2
+	bonusBoundaryDelimiter   = bonusBoundary + 1
+	bonusFirstCharMultiplier = 2
+
+	// maxAlignCells bounds the DP table; longer inputs use a greedy alignment.
+	maxAlignCells = 64 * 1024
+)
+
+type charClass int
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charDelimiter
+	charLower
+	charUpper
+	charLetter
+	charNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case unicode.IsSpace(r):
+		return charWhite
+	case strings.ContainsRune("/\\,:;|", r):
+		return charDelimiter
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsDigit(r):
+		return charNumber
+	case unicode.IsLetter(r):
+		return charLetter
+	}
+	return charNonWord
+}
+
+// bonusFor scores a rune of class cur that follows a rune of class prev.
+func bonusFor(prev, cur charClass) int {
+	if cur > charNonWord {
+		switch prev {
+		case charWhite:
+			return bonusBoundaryWhite
+		case charDelimiter:
+			return bonusBoundaryDelimiter
+		case charNonWord:
+			return bonusBoundary
+		}
+	}
+	if prev == charLower && cur == charUpper || prev != charNumber && cur == charNumber {
+		return bonusCamel123
+	}
+	switch cur {
+	case charNonWord, charDelimiter:
+		return bonusNonWord
+	case charWhite:
+		return bonusBoundaryWhite
+	}
+	return 0
+}
+
+// diacriticFold maps accented Latin letters to their base letter.
+var diacriticFold = func() map[rune]rune {
+	groups := map[rune]string{
+		'a': "àáâãäåāăą", 'c': "çćĉċč", 'd': "ďđ", 'e': "èéêëēĕėęě",
+		'g': "ĝğġģ", 'h': "ĥħ", 'i': "ìíîïĩīĭįı", 'j': "ĵ", 'k': "ķ",
+		'l': "ĺļľŀł", 'n': "ñńņňŉ", 'o': "òóôõöøōŏő", 'r': "ŕŗř",
+		's': "śŝşšſ", 't': "ţťŧ", 'u': "ùúûüũūŭůűų", 'w': "ŵ", 'y': "ýÿŷ",
+		'z': "źżž",
+	}
+	fold := make(map[rune]rune)
+	for base, variants := range groups {
+		for _, r := range variants {
+			fold[r] = base
+		}
+	}
+	return fold
+}()
+
+// normalizeRune lower-cases r and strips diacritics, so "É" matches "e".
+func normalizeRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if r < 0x80 {
+		return r
+	}
+	if base, ok := diacriticFold[r]; ok {
+		return base
+	}
+	return r
+}
+
+func normalizeRunes(runes []rune) []rune {
+	out := make([]rune, len(runes))
+	for i, r := range runes {
+		out[i] = normalizeRune(r)
+	}
+	return out
+}
+
+// matchBonuses returns the position bonus of every rune in text.
+func matchBonuses(text []rune) []int {
+	bonuses := make([]int, len(text))
+	prev := charWhite
+	for i, r := range text {
+		cur := classOf(r)
+		bonuses[i] = bonusFor(prev, cur)
+		prev = cur
+	}
+	return bonuses
 }
 
 // fuzzyMatch performs fuzzy matching between query and text.
 // Matches if all query characters appear in order (not necessarily consecutive).
 func fuzzyMatch(query, text string) FuzzyMatch {
-	queryLower := strings.ToLower(query)
-	textLower := strings.ToLower(text)
+	textRunes := []rune(text)
+	term := fuzzyTerm{kind: termFuzzy, pattern: normalizeRunes([]rune(query))}
+	score, positions, ok := term.match(normalizeRunes(textRunes), matchBonuses(textRunes))
+	if !ok {
+		return FuzzyMatch{}
+	}
+	return FuzzyMatch{Matches: true, Score: -float64(score), Positions: positions}
+}
 
-	matchQuery := func(normalizedQuery string) FuzzyMatch {
-		if len(normalizedQuery) == 0 {
-			return FuzzyMatch{Matches: true, Score: 0}
-		}
+// alignFuzzy finds the highest scoring placement of pattern in text.
+func alignFuzzy(pattern, text []rune, bonuses []int) (int, []int, bool) {
+	m := len(pattern)
+	if m == 0 {
+		return 0, nil, true
+	}
 
-		if len(normalizedQuery) > len(textLower) {
-			return FuzzyMatch{Matches: false, Score: 0}
+	// Narrow the search window to [lo, hi] and bail out early when pattern
+	// is not a subsequence at all.
+	lo, pi := -1, 0
+	for j := 0; j < len(text) && pi < m; j++ {
+		if text[j] == pattern[pi] {
+			if pi == 0 {
+				lo = j
+			}
+			pi++
 		}
+	}
+	if pi < m {
+		return 0, nil, false
+	}
+	hi, pi := -1, m-1
+	for j := len(text) - 1; j >= lo && pi >= 0; j-- {
+		if text[j] == pattern[pi] {
+			if pi == m-1 {
+				hi = j
+			}
+			pi--
+		}
+	}
 
-		queryIndex := 0
-		score := 0.0
-		lastMatchIndex := -1
-		consecutiveMatches := 0
-
-		for i := 0; i < len(textLower) && queryIndex < len(normalizedQuery); i++ {
-			if textLower[i] == normalizedQuery[queryIndex] {
-				isWordBoundary := i == 0 || isWordBoundaryChar(textLower[i-1])
+	window := text[lo : hi+1]
+	n := len(window)
+	if m*n > maxAlignCells {
+		return alignGreedy(pattern, text, bonuses, lo)
+	}
 
-				// Reward consecutive matches
-				if lastMatchIndex == i-1 {
-					consecutiveMatches++
-					score -= float64(consecutiveMatches) * 5
-				} else {
-					consecutiveMatches = 0
-					// Penalize gaps
-					if lastMatchIndex >= 0 {
-						score += float64(i-lastMatchIndex-1) * 2
+	const negInf = -1 << 30
+	score := make([]int, m*n)
+	run := make([]int, m*n)  // length of the consecutive run ending here
+	from := make([]int, m*n) // window index of the previous pattern rune
+
+	for i := range m {
+		gapBest, gapFrom := negInf, -1
+		for j := range n {
+			idx := i*n + j
+			score[idx] = negInf
+			if i > 0 {
+				// Best predecessor at least two runes back, i.e. with a gap.
+				gapBest += scoreGapExtension
+				if j >= 2 {
+					if cand := score[(i-1)*n+j-2] + scoreGapStart; cand > gapBest {
+						gapBest, gapFrom = cand, j-2
 					}
 				}
+			}
+			if window[j] != pattern[i] {
+				continue
+			}
 
-				// Reward word boundary matches
-				if isWordBoundary {
-					score -= 10
+			bonus := bonuses[lo+j]
+			if i == 0 {
+				score[idx] = scoreMatch + bonus*bonusFirstCharMultiplier
+				run[idx] = 1
+				from[idx] = -1
+				continue
+			}
+
+			best, src, length := negInf, -1, 0
+			if gapBest > negInf/2 {
+				best, src, length = gapBest+scoreMatch+bonus, gapFrom, 1
+			}
+			if j >= 1 {
+				if prev := score[(i-1)*n+j-1]; prev > negInf/2 {
+					prevRun := run[(i-1)*n+j-1]
+					runBonus := max(bonus, bonusConsecutive, bonuses[lo+j-prevRun])
+					if s := prev + scoreMatch + runBonus; s >= best {
+						best, src, length = s, j-1, prevRun+1
+					}
 				}
+			}
+			score[idx], from[idx], run[idx] = best, src, length
+		}
+	}
 
-				// Slight penalty for later matches
-				score += float64(i) * 0.1
+	bestScore, bestJ := negInf, -1
+	for j := range n {
+		if s := score[(m-1)*n+j]; s > bestScore {
+			bestScore, bestJ = s, j
+		}
+	}
+	if bestJ < 0 {
+		return 0, nil, false
+	}
 
-				lastMatchIndex = i
-				queryIndex++
-			}
+	positions := make([]int, m)
+	for i, j := m-1, bestJ; i >= 0; i-- {
+		positions[i] = lo + j
+		j = from[i*n+j]
+	}
+	return bestScore, positions, true
+}
+
+// alignGreedy matches pattern left to right starting at lo.
+func alignGreedy(pattern, text []rune, bonuses []int, lo int) (int, []int, bool) {
+	positions := make([]int, 0, len(pattern))
+	for j := lo; j < len(text) && len(positions) < len(pattern); j++ {
+		if text[j] == pattern[len(positions)] {
+			positions = append(positions, j)
 		}
+	}
+	if len(positions) < len(pattern) {
+		return 0, nil, false
+	}
+	return scorePositions(positions, bonuses), positions, true
+}
 
-		if queryIndex < len(normalizedQuery) {
-			return FuzzyMatch{Matches: false, Score: 0}
+// scorePositions scores a fixed alignment with the same rules as alignFuzzy.
+func scorePositions(positions []int, bonuses []int) int {
+	total, runStart := 0, 0
+	for i, p := range positions {
+		bonus := bonuses[p]
+		switch {
+		case i == 0:
+			total += scoreMatch + bonus*bonusFirstCharMultiplier
+			runStart = p
+		case p == positions[i-1]+1:
+			total += scoreMatch + max(bonus, bonusConsecutive, bonuses[runStart])
+		default:
+			total += scoreMatch + bonus + scoreGapStart + scoreGapExtension*(p-positions[i-1]-2)
+			runStart = p
 		}
+	}
+	return total
+}
+
+type fuzzyTermKind int
 
-		return FuzzyMatch{Matches: true, Score: score}
+const (
+	termFuzzy  fuzzyTermKind = iota
+	termExact                // 'foo
+	termPrefix               // ^foo
+	termSuffix               // foo$
+	termEqual                // ^foo$
+)
+
+type fuzzyTerm struct {
+	kind    fuzzyTermKind
+	pattern []rune // normalized
+	inverse bool   // !foo: text must not contain the term
+}
+
+// match reports the term's score and matched positions in normalized text.
+func (t fuzzyTerm) match(text []rune, bonuses []int) (int, []int, bool) {
+	switch t.kind {
+	case termFuzzy:
+		if score, positions, ok := alignFuzzy(t.pattern, text, bonuses); ok {
+			return score, positions, true
+		}
+		// Try swapping letters and digits, e.g. "v2" for "2v".
+		swapped := getSwappedQuery(string(t.pattern))
+		if swapped == "" {
+			return 0, nil, false
+		}
+		score, positions, ok := alignFuzzy([]rune(swapped), text, bonuses)
+		return score - 5, positions, ok
+	case termPrefix:
+		if !runesHavePrefix(text, t.pattern) {
+			return 0, nil, false
+		}
+		return t.exactAt(0, bonuses)
+	case termSuffix:
+		start := len(text) - len(t.pattern)
+		if start < 0 || !runesHavePrefix(text[start:], t.pattern) {
+			return 0, nil, false
+		}
+		return t.exactAt(start, bonuses)
+	case termEqual:
+		if !slices.Equal(text, t.pattern) {
+			return 0, nil, false
+		}
+		return t.exactAt(0, bonuses)
 	}
 
-	// Try primary match
-	primaryMatch := matchQuery(queryLower)
-	if primaryMatch.Matches {
-		return primaryMatch
+	// termExact: keep the best scoring occurrence.
+	bestScore, bestStart := 0, -1
+	for start := 0; start+len(t.pattern) <= len(text); start++ {
+		if !runesHavePrefix(text[start:], t.pattern) {
+			continue
+		}
+		if score, _, _ := t.exactAt(start, bonuses); bestStart < 0 || score > bestScore {
+			bestScore, bestStart = score, start
+		}
 	}
+	if bestStart < 0 {
+		return 0, nil, false
+	}
+	return t.exactAt(bestStart, bonuses)
+}
+
+func (t fuzzyTerm) exactAt(start int, bonuses []int) (int, []int, bool) {
+	positions := make([]int, len(t.pattern))
+	for i := range positions {
+		positions[i] = start + i
+	}
+	return scorePositions(positions, bonuses), positions, true
+}
+
+func runesHavePrefix(s, prefix []rune) bool {
+	return len(s) >= len(prefix) && slices.Equal(s[:len(prefix)], prefix)
+}
+
+// FuzzyQuery is a parsed fzf-style search query. Space-separated terms must all
+// match; each term is one of:
+//
+//	foo    fuzzy match
+//	'foo   exact substring
+//	^foo   prefix
+//	foo$   suffix
+//	!foo   must not contain foo (also !^foo, !foo$)
+//
+// Matching ignores case and diacritics.
+type FuzzyQuery struct {
+	terms []fuzzyTerm
+}
 
-	// Try swapping letters and digits
-	swappedQuery := getSwappedQuery(queryLower)
-	if swappedQuery == "" {
-		return primaryMatch
+// ParseFuzzyQuery parses query into terms.
+func ParseFuzzyQuery(query string) FuzzyQuery {
+	var q FuzzyQuery
+	for _, field := range strings.Fields(query) {
+		t := fuzzyTerm{kind: termFuzzy}
+		if len(field) > 1 && field[0] == '!' {
+			t.inverse = true
+			t.kind = termExact
+			field = field[1:]
+		}
+		switch {
+		case len(field) > 1 && field[0] == '\'':
+			t.kind = termExact
+			field = field[1:]
+		case len(field) > 1 && field[0] == '^':
+			t.kind = termPrefix
+			field = field[1:]
+		}
+		if len(field) > 1 && strings.HasSuffix(field, "$") {
+			if t.kind == termPrefix {
+				t.kind = termEqual
+			} else {
+				t.kind = termSuffix
+			}
+			field = field[:len(field)-1]
+		}
+		t.pattern = normalizeRunes([]rune(field))
+		q.terms = append(q.terms, t)
 	}
+	return q
+}
+
+// Empty reports whether the query has no terms and therefore matches everything.
+func (q FuzzyQuery) Empty() bool {
+	return len(q.terms) == 0
+}
 
-	swappedMatch := matchQuery(swappedQuery)
-	if !swappedMatch.Matches {
-		return primaryMatch
+// Match scores text against every term. Positions of all positive terms are
+// merged.
+func (q FuzzyQuery) Match(text string) FuzzyMatch {
+	if len(q.terms) == 0 {
+		return FuzzyMatch{Matches: true}
 	}
 
-	return FuzzyMatch{Matches: true, Score: swappedMatch.Score + 5}
+	textRunes := []rune(text)
+	normalized := normalizeRunes(textRunes)
+	bonuses := matchBonuses(textRunes)
+
+	total := 0
+	var positions []int
+	for _, t := range q.terms {
+		score, pos, ok := t.match(normalized, bonuses)
+		if t.inverse {
+			if ok {
+				return FuzzyMatch{}
+			}
+			continue
+		}
+		if !ok {
+			return FuzzyMatch{}
+		}
+		total += score
+		positions = append(positions, pos...)
+	}
+	slices.Sort(positions)
+	return FuzzyMatch{Matches: true, Score: -float64(total), Positions: slices.Compact(positions)}
 }
 
-// isWordBoundaryChar checks if a character is a word boundary character.
-func isWordBoundaryChar(c byte) bool {
-	return c == ' ' || c == '-' || c == '_' || c == '.' || c == '/' || c == ':'
+// HighlightMatches wraps the runes of text at positions with style, grouping
+// consecutive positions into one styled run.
+func HighlightMatches(text string, positions []int, style func(string) string) string {
+	if len(positions) == 0 || style == nil {
+		return text
+	}
+
+	var b strings.Builder
+	var match strings.Builder
+	next := 0
+	flush := func() {
+		if match.Len() > 0 {
+			b.WriteString(style(match.String()))
+			match.Reset()
+		}
+	}
+	i := 0
+	for _, r := range text {
+		if next < len(positions) && positions[next] == i {
+			match.WriteRune(r)
+			next++
+		} else {
+			flush()
+			b.WriteRune(r)
+		}
+		i++
+	}
+	flush()
+	return b.String()
 }
 
 // getSwappedQuery attempts to swap letters and digits in the query.
@@ -115,56 +499,33 @@ func getSwappedQuery(query string) string {
 }
 
 // FuzzyFilter filters and sorts items by fuzzy match quality (best matches first).
-// Supports space-separated tokens: all tokens must match.
+// The query uses FuzzyQuery syntax; items with equal scores keep their order.
 func FuzzyFilter[T any](items []T, query string, getText func(T) string) []T {
-	trimmedQuery := strings.TrimSpace(query)
-	if trimmedQuery == "" {
-		return items
-	}
-
-	// Split query into tokens
-	tokens := strings.Fields(trimmedQuery)
-	if len(tokens) == 0 {
+	q := ParseFuzzyQuery(query)
+	if q.Empty() {
 		return items
 	}
 
 	type result struct {
-		item       T
-		totalScore float64
+		item  T
+		score float64
 	}
 
 	var results []result
-
 	for _, item := range items {
-		text := getText(item)
-		totalScore := 0.0
-		allMatch := true
-
-		for _, token := range tokens {
-			match := fuzzyMatch(token, text)
-			if match.Matches {
-				totalScore += match.Score
-			} else {
-				allMatch = false
-				break
-			}
-		}
-
-		if allMatch {
-			results = append(results, result{item: item, totalScore: totalScore})
+		if match := q.Match(getText(item)); match.Matches {
+			results = append(results, result{item: item, score: match.Score})
 		}
 	}
 
 	// Sort by score (lower is better)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].totalScore < results[j].totalScore
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score < results[j].score
 	})
 
-	// Extract sorted items
 	filtered := make([]T, len(results))
 	for i, r := range results {
 		filtered[i] = r.item
 	}
-
 	return filtered
 }
@@ -0,0 +1,95 @@
+package components
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyMatchPositions(t *testing.T) {
+	cases := []struct {
+		query, text string
+		positions   []int
+	}{
+		{"fb", "foo_bar", []int{0, 4}},
+		{"gc", "git checkout", []int{0, 4}},
+		{"ed", "components/editor.go", []int{11, 12}},
+		{"sm", "SelectMenu", []int{0, 6}},
+		{"cafe", "Café", []int{0, 1, 2, 3}},
+	}
+	for _, c := range cases {
+		m := fuzzyMatch(c.query, c.text)
+		if !m.Matches || !slices.Equal(m.Positions, c.positions) {
+			t.Errorf("fuzzyMatch(%q, %q) = %+v, want positions %v", c.query, c.text, m, c.positions)
+		}
+	}
+
+	if fuzzyMatch("xyz", "foo").Matches {
+		t.Error("non-subsequence should not match")
+	}
+}
+
+func TestFuzzyMatchPrefersBoundaries(t *testing.T) {
+	boundary := fuzzyMatch("ed", "components/editor.go")
+	inner := fuzzyMatch("ed", "components/coded.go")
+	if !(boundary.Score < inner.Score) {
+		t.Fatalf("boundary match should rank higher: %v vs %v", boundary.Score, inner.Score)
+	}
+
+	consecutive := fuzzyMatch("abc", "xabcx")
+	spread := fuzzyMatch("abc", "xaxbxc")
+	if !(consecutive.Score < spread.Score) {
+		t.Fatalf("consecutive match should rank higher: %v vs %v", consecutive.Score, spread.Score)
+	}
+}
+
+func TestFuzzyQuerySyntax(t *testing.T) {
+	cases := []struct {
+		query, text string
+		matches     bool
+	}{
+		{"'bar", "foobar", true},
+		{"'fbr", "foobar", false},
+		{"^foo", "foobar", true},
+		{"^bar", "foobar", false},
+		{"bar$", "foobar", true},
+		{"foo$", "foobar", false},
+		{"^foobar$", "foobar", true},
+		{"!baz", "foobar", true},
+		{"!bar", "foobar", false},
+		{"fo !^bar", "foobar", true},
+		{"fo ar", "foobar", true},
+		{"fo xy", "foobar", false},
+		{"résumé", "RESUME.md", true},
+	}
+	for _, c := range cases {
+		if got := ParseFuzzyQuery(c.query).Match(c.text).Matches; got != c.matches {
+			t.Errorf("%q against %q: matches = %v, want %v", c.query, c.text, got, c.matches)
+		}
+	}
+}
+
+func TestFuzzyFilterOrdersByScore(t *testing.T) {
+	items := []string{"src/fuzzy_test.go", "fuzzy.go", "foo/uzzy.go"}
+	got := FuzzyFilter(items, "fuzzy", func(s string) string { return s })
+	want := []string{"fuzzy.go", "src/fuzzy_test.go", "foo/uzzy.go"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("FuzzyFilter = %v, want %v", got, want)
+	}
+}
+
+func TestSelectListHighlightsMatches(t *testing.T) {
+	list := NewSelectList([]SelectItem{{Value: "model"}, {Value: "help"}}, 5,
+		WithSelectListTheme(SelectListTheme{
+			Match: func(s string) string { return "[" + s + "]" },
+		}),
+		WithSelectListHighlight("mo"),
+	)
+	out := strings.Join(list.Render(30), "\n")
+	if !strings.Contains(out, "[mo]del") {
+		t.Fatalf("expected highlighted label, got %q", out)
+	}
+	if !strings.Contains(out, "  help") {
+		t.Fatalf("non-matching label should render plain, got %q", out)
+	}
+}
@@ -0,0 +1,143 @@
+package components
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern line of a .gitignore-style file.
+type ignoreRule struct {
+	segments []string // pattern split on "/", "**" kept as its own segment
+	negate   bool     // "!pattern" re-includes a previously ignored path
+	dirOnly  bool     // "pattern/" only matches directories
+	anchored bool     // pattern contains a slash, so it is relative to the ignore file's directory
+}
+
+// ignoreRules holds the rules of one ignore file together with the directory it
+// applies to, relative to the index root ("" for the root itself).
+type ignoreRules struct {
+	base  string
+	rules []ignoreRule
+}
+
+// parseIgnoreRules parses .gitignore syntax: comments, blank lines, "!" negation,
+// trailing "/" for directories, leading or inner "/" for anchoring, and the
+// "*", "?", "[...]" and "**" wildcards.
+func parseIgnoreRules(base string, content string) ignoreRules {
+	rs := ignoreRules{base: base}
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if !strings.HasSuffix(line, "\\ ") {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.segments = strings.Split(line, "/")
+		rs.rules = append(rs.rules, rule)
+	}
+	return rs
+}
+
+// loadIgnoreFile reads an ignore file; a missing or empty file yields no rules.
+func loadIgnoreFile(base, file string) (ignoreRules, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ignoreRules{}, false
+	}
+	rs := parseIgnoreRules(base, string(data))
+	return rs, len(rs.rules) > 0
+}
+
+// globalIgnoreFile returns git's default global excludes file:
+// $XDG_CONFIG_HOME/git/ignore, falling back to ~/.config/git/ignore.
+func globalIgnoreFile() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "git", "ignore")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "git", "ignore")
+}
+
+// match reports whether rel (slash-separated, relative to the rule's base) matches.
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		return matchSegment(r.segments[0], path.Base(rel))
+	}
+	return matchSegments(r.segments, strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range len(parts) + 1 {
+				if matchSegments(pattern, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 || !matchSegment(pattern[0], parts[0]) {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func matchSegment(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// isIgnored applies rule sets from the outermost to the innermost directory;
+// the last matching rule wins, as in git.
+func isIgnored(stack []ignoreRules, relPath string, isDir bool) bool {
+	ignored := false
+	for _, rs := range stack {
+		rel := relPath
+		if rs.base != "" {
+			if !strings.HasPrefix(relPath, rs.base+"/") {
+				continue
+			}
+			rel = relPath[len(rs.base)+1:]
+		}
+		for _, rule := range rs.rules {
+			if rule.match(rel, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
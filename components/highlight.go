@@ -0,0 +1,334 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+// codeLanguage describes just enough of a language for token highlighting.
+type codeLanguage struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	// multilineQuotes are the quotes whose strings may span lines.
+	multilineQuotes string
+	keywords        map[string]bool
+	types           map[string]bool
+	// capitalTypes treats capitalised identifiers as type names.
+	capitalTypes bool
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for w := range strings.FieldsSeq(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	cKeywords  = "break case const continue default do else enum extern for goto if inline register return sizeof static struct switch typedef union volatile while"
+	cTypes     = "bool char double float int long short signed unsigned void size_t"
+	jsKeywords = "async await break case catch class const continue debugger default delete do else export extends finally for from function if import in instanceof let new of return static super switch this throw try typeof var void while yield true false null undefined"
+	tsKeywords = jsKeywords + " abstract as declare implements interface keyof namespace private protected public readonly satisfies type"
+	tsTypes    = "any bigint boolean never number object string symbol unknown void"
+)
+
+var codeLanguages = map[string]*codeLanguage{
+	"go": {
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'`",
+		multilineQuotes: "`",
+		keywords:        wordSet("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var true false nil iota"),
+		types:           wordSet("any bool byte comparable complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr"),
+		capitalTypes:    true,
+	},
+	"javascript": {
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'`",
+		multilineQuotes: "`",
+		keywords:        wordSet(jsKeywords),
+		capitalTypes:    true,
+	},
+	"typescript": {
+		lineComments:    []string{"//"},
+		blockComment:    [2]string{"/*", "*/"},
+		quotes:          "\"'`",
+		multilineQuotes: "`",
+		keywords:        wordSet(tsKeywords),
+		types:           wordSet(tsTypes),
+		capitalTypes:    true,
+	},
+	"python": {
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords:     wordSet("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield True False None self"),
+		types:        wordSet("bool bytes dict float frozenset int list object set str tuple"),
+		capitalTypes: true,
+	},
+	"rust": {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+		keywords:     wordSet("as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while"),
+		types:        wordSet("bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128 usize"),
+		capitalTypes: true,
+	},
+	"c": {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords:     wordSet(cKeywords + " NULL"),
+		types:        wordSet(cTypes),
+	},
+	"cpp": {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords:     wordSet(cKeywords + " auto catch class constexpr delete explicit false friend mutable namespace new noexcept nullptr operator override private protected public template this throw true try typename using virtual"),
+		types:        wordSet(cTypes + " string vector"),
+		capitalTypes: true,
+	},
+	"java": {
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords:     wordSet("abstract assert break case catch class const continue default do else enum extends final finally for if implements import instanceof interface native new package private protected public return static super switch synchronized this throw throws try var void volatile while true false null"),
+		types:        wordSet("boolean byte char double float int long short"),
+		capitalTypes: true,
+	},
+	"bash": {
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords:     wordSet("case do done elif else esac export fi for function if in local return then until while"),
+	},
+	"ruby": {
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords:     wordSet("alias and begin break case class def do else elsif end ensure false for if in module next nil not or redo rescue retry return self super then true undef unless until when while yield"),
+		capitalTypes: true,
+	},
+	"lua": {
+		lineComments: []string{"--"},
+		quotes:       "\"'",
+		keywords:     wordSet("and break do else elseif end false for function goto if in local nil not or repeat return then true until while"),
+	},
+	"sql": {
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords: wordSet("select from where and or not insert into values update set delete create table drop alter index join left right inner outer on as group by order having limit offset union distinct null is in like between case when then else end primary key foreign references " +
+			"SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER INDEX JOIN LEFT RIGHT INNER OUTER ON AS GROUP BY ORDER HAVING LIMIT OFFSET UNION DISTINCT NULL IS IN LIKE BETWEEN CASE WHEN THEN ELSE END PRIMARY KEY FOREIGN REFERENCES"),
+		types: wordSet("int integer bigint text varchar char boolean date timestamp real float INT INTEGER BIGINT TEXT VARCHAR CHAR BOOLEAN DATE TIMESTAMP REAL FLOAT"),
+	},
+	"json": {
+		quotes:   "\"",
+		keywords: wordSet("true false null"),
+	},
+	"yaml": {
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords:     wordSet("true false null yes no on off"),
+	},
+}
+
+func init() {
+	codeLanguages["tsx"] = codeLanguages["typescript"]
+	codeLanguages["jsonc"] = codeLanguages["json"]
+}
+
+// languageAliases maps fence names that are not file extensions.
+var languageAliases = map[string]string{
+	"golang":      "go",
+	"shell":       "bash",
+	"shellscript": "bash",
+	"console":     "bash",
+	"python3":     "python",
+	"c++":         "cpp",
+	"node":        "javascript",
+}
+
+// lookupCodeLanguage resolves a fence info string ("go", "py", "TypeScript")
+// to its highlighting rules.
+func lookupCodeLanguage(lang string) *codeLanguage {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if l, ok := codeLanguages[lang]; ok {
+		return l
+	}
+	if alias, ok := languageAliases[lang]; ok {
+		return codeLanguages[alias]
+	}
+	if id, _, ok := style.LangByExtension(lang); ok {
+		return codeLanguages[id]
+	}
+	return nil
+}
+
+// CodeHighlighter returns a MarkdownTheme.HighlightCode function that colors
+// code with the theme's syntax tokens. Code in an unknown language is colored
+// with the code block token. Every returned line is styled on its own, so
+// comments and strings spanning lines survive wrapping.
+func CodeHighlighter(theme *style.Theme) func(code, lang string) []string {
+	return func(code, lang string) []string {
+		l := lookupCodeLanguage(lang)
+		if l == nil {
+			lines := strings.Split(code, "\n")
+			for i, line := range lines {
+				if line != "" {
+					lines[i] = theme.Fg(style.ColorMdCodeBlock, line)
+				}
+			}
+			return lines
+		}
+		var (
+			lines   []string
+			current strings.Builder
+		)
+		for _, tok := range l.tokenize(code) {
+			for i, part := range strings.Split(tok.text, "\n") {
+				if i > 0 {
+					lines = append(lines, current.String())
+					current.Reset()
+				}
+				if part == "" {
+					continue
+				}
+				if tok.color == "" {
+					current.WriteString(part)
+				} else {
+					current.WriteString(theme.Fg(tok.color, part))
+				}
+			}
+		}
+		return append(lines, current.String())
+	}
+}
+
+type codeToken struct {
+	text  string
+	color style.ThemeColor
+}
+
+func (l *codeLanguage) tokenize(code string) []codeToken {
+	var tokens []codeToken
+	emit := func(text string, color style.ThemeColor) {
+		tokens = append(tokens, codeToken{text, color})
+	}
+	for i := 0; i < len(code); {
+		rest := code[i:]
+		c := code[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			j := i + 1
+			for j < len(code) && strings.IndexByte(" \t\n\r", code[j]) >= 0 {
+				j++
+			}
+			emit(code[i:j], "")
+			i = j
+		case l.startsLineComment(rest):
+			j := strings.IndexByte(rest, '\n')
+			if j < 0 {
+				j = len(rest)
+			}
+			emit(rest[:j], style.ColorSyntaxComment)
+			i += j
+		case l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]):
+			j := strings.Index(rest[len(l.blockComment[0]):], l.blockComment[1])
+			if j < 0 {
+				j = len(rest)
+			} else {
+				j += len(l.blockComment[0]) + len(l.blockComment[1])
+			}
+			emit(rest[:j], style.ColorSyntaxComment)
+			i += j
+		case strings.IndexByte(l.quotes, c) >= 0:
+			j := l.stringEnd(rest)
+			emit(rest[:j], style.ColorSyntaxString)
+			i += j
+		case isDigit(c):
+			j := 1
+			for j < len(rest) && (isIdentByte(rest[j]) || rest[j] == '.') {
+				j++
+			}
+			emit(rest[:j], style.ColorSyntaxNumber)
+			i += j
+		case isIdentByte(c):
+			j := 1
+			for j < len(rest) && isIdentByte(rest[j]) {
+				j++
+			}
+			emit(rest[:j], l.identColor(rest[:j], strings.TrimLeft(rest[j:], " \t")))
+			i += j
+		case strings.IndexByte("+-*/%=<>!&|^~?:", c) >= 0:
+			emit(rest[:1], style.ColorSyntaxOperator)
+			i++
+		case c < 0x80:
+			emit(rest[:1], style.ColorSyntaxPunctuation)
+			i++
+		default:
+			j := 1
+			for j < len(rest) && rest[j] >= 0x80 {
+				j++
+			}
+			emit(rest[:j], style.ColorMdCodeBlock)
+			i += j
+		}
+	}
+	return tokens
+}
+
+func (l *codeLanguage) startsLineComment(s string) bool {
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringEnd returns the length of the string literal at the start of s,
+// stopping at the end of the line unless the quote may span lines.
+func (l *codeLanguage) stringEnd(s string) int {
+	quote := s[0]
+	multiline := strings.IndexByte(l.multilineQuotes, quote) >= 0
+	for j := 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case '\n':
+			if !multiline {
+				return j
+			}
+		case quote:
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+func (l *codeLanguage) identColor(word, after string) style.ThemeColor {
+	switch {
+	case l.keywords[word]:
+		return style.ColorSyntaxKeyword
+	case l.types[word]:
+		return style.ColorSyntaxType
+	case strings.HasPrefix(after, "("):
+		return style.ColorSyntaxFunction
+	case l.capitalTypes && word[0] >= 'A' && word[0] <= 'Z':
+		return style.ColorSyntaxType
+	}
+	return style.ColorSyntaxVariable
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
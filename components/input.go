@@ -5,103 +5,211 @@ import (
 
 	"github.com/yeeaiclub/fasttui"
 	"github.com/yeeaiclub/fasttui/keys"
+	"github.com/yeeaiclub/fasttui/style"
 )
 
-var _ fasttui.Component = (*Input)(nil)
+var (
+	_ fasttui.Component  = (*Input)(nil)
+	_ fasttui.ThemeAware = (*Input)(nil)
+)
+
+const defaultInputPrompt = "> "
 
+// Input is a single-line text field. The cursor is a byte offset into value that
+// always sits on a grapheme cluster boundary; scrolling is measured in terminal cells.
 type Input struct {
 	value        string
 	cursor       int
+	scrollOffset int // index of the first visible grapheme
 	onSubmit     func(string)
 	onEscape     func()
 	focused      bool
 	pastedBuffer string
 	isInPaste    bool
+
+	prompt           string
+	placeholder      string
+	placeholderColor func(string) string
+	mask             string
+	maxLength        int // in grapheme clusters, 0 = unlimited
+	validator        func(string) error
+	validationErr    error
+	errorColor       func(string) string
+}
+
+// InputOption configures optional behavior and theming of Input.
+type InputOption func(*Input)
+
+// WithInputPrompt replaces the default "> " prompt. Pass "" for no prompt.
+func WithInputPrompt(prompt string) InputOption {
+	return func(i *Input) {
+		i.prompt = prompt
+	}
+}
+
+// WithInputPlaceholder sets the hint shown while the value is empty.
+func WithInputPlaceholder(text string) InputOption {
+	return func(i *Input) {
+		i.placeholder = text
+	}
+}
+
+// WithInputPlaceholderColor sets the color function used for the placeholder.
+func WithInputPlaceholderColor(color func(string) string) InputOption {
+	return func(i *Input) {
+		i.placeholderColor = color
+	}
+}
+
+// WithInputMask renders every grapheme of the value as mask, e.g. "•" for passwords.
+func WithInputMask(mask string) InputOption {
+	return func(i *Input) {
+		i.mask = mask
+	}
+}
+
+// WithInputMaxLength limits the value to n grapheme clusters. Non-positive means unlimited.
+func WithInputMaxLength(n int) InputOption {
+	return func(i *Input) {
+		i.maxLength = max(0, n)
+	}
+}
+
+// WithInputValidator validates the value after every edit. A non-nil error is
+// rendered below the field and blocks submit.
+func WithInputValidator(validate func(string) error) InputOption {
+	return func(i *Input) {
+		i.validator = validate
+	}
+}
+
+// WithInputStyle themes the input, see SetTheme.
+func WithInputStyle(theme *style.Theme) InputOption {
+	return func(i *Input) {
+		i.SetTheme(theme)
+	}
 }
 
-func NewInput() *Input {
-	return &Input{
-		value:        "",
-		cursor:       0,
-		onSubmit:     nil,
-		onEscape:     nil,
-		focused:      false,
-		pastedBuffer: "",
-		isInPaste:    false,
+// WithInputErrorColor sets the color function used for the validation error line.
+func WithInputErrorColor(color func(string) string) InputOption {
+	return func(i *Input) {
+		i.errorColor = color
 	}
 }
 
+func NewInput(opts ...InputOption) *Input {
+	i := &Input{
+		prompt: defaultInputPrompt,
+		placeholderColor: func(s string) string {
+			return "\x1b[2m" + s + "\x1b[22m"
+		},
+		errorColor: func(s string) string {
+			return s
+		},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(i)
+		}
+	}
+	return i
+}
+
 func (i *Input) Render(width int) []string {
-	// Calculate visible window
-	prompt := "> "
-	availableWidth := width - len(prompt)
+	prompt := i.prompt
+	availableWidth := width - fasttui.VisibleWidth(prompt)
 
 	if availableWidth <= 0 {
-		return []string{prompt}
+		return []string{fasttui.TruncateToWidth(prompt, width, "", false)}
 	}
 
-	visibleText := ""
-	cursorDisplay := i.cursor
+	marker := ""
+	if i.focused {
+		marker = CURSOR_MARKER
+	}
 
-	if len(i.value) < availableWidth {
-		// Everything fits (leave room for cursor at end)
-		visibleText = i.value
+	var line string
+	if i.value == "" && i.placeholder != "" {
+		hint := fasttui.TruncateToWidth(i.placeholder, availableWidth-1, "", false)
+		hintWidth := fasttui.VisibleWidth(hint)
+		line = prompt + marker + "\x1b[7m \x1b[27m" + i.placeholderColor(hint) +
+			strings.Repeat(" ", max(0, availableWidth-1-hintWidth))
 	} else {
-		// Need horizontal scrolling
-		// Reserve one character for cursor if it's at the end
-		scrollWidth := availableWidth
-		if i.cursor == len(i.value) {
-			scrollWidth = availableWidth - 1
-		}
-		halfWidth := scrollWidth / 2
-
-		if i.cursor < halfWidth {
-			// Cursor near start
-			visibleText = i.value[:scrollWidth]
-			cursorDisplay = i.cursor
-		} else if i.cursor > len(i.value)-halfWidth {
-			// Cursor near end
-			visibleText = i.value[len(i.value)-scrollWidth:]
-			cursorDisplay = scrollWidth - (len(i.value) - i.cursor)
-		} else {
-			// Cursor in middle
-			start := i.cursor - halfWidth
-			visibleText = i.value[start : start+scrollWidth]
-			cursorDisplay = halfWidth
-		}
+		line = prompt + i.renderValue(availableWidth, marker)
 	}
 
-	// Build line with fake cursor
-	// Insert cursor character at cursor position
-	beforeCursor := ""
-	if cursorDisplay > 0 {
-		beforeCursor = visibleText[:cursorDisplay]
+	result := []string{line}
+	if i.validationErr != nil {
+		msg := fasttui.TruncateToWidth(i.validationErr.Error(), width, "...", false)
+		result = append(result, i.errorColor(msg))
 	}
+	return result
+}
 
-	atCursor := " " // Character at cursor, or space if at end
-	if cursorDisplay < len(visibleText) {
-		atCursor = string(visibleText[cursorDisplay])
+// renderValue renders the visible window of the value with an inverse-video cursor,
+// padded to availableWidth cells.
+func (i *Input) renderValue(availableWidth int, marker string) string {
+	segs := collectGraphemeSegments(i.value)
+	cells := make([]string, len(segs))
+	widths := make([]int, len(segs))
+	cursorIdx := len(segs)
+	for idx, seg := range segs {
+		cells[idx] = seg.text
+		if i.mask != "" {
+			cells[idx] = i.mask
+		}
+		widths[idx] = fasttui.GraphemeWidth(cells[idx])
+		if seg.index == i.cursor {
+			cursorIdx = idx
+		}
 	}
 
-	afterCursor := ""
-	if cursorDisplay+1 < len(visibleText) {
-		afterCursor = visibleText[cursorDisplay+1:]
+	cursorWidth := 1
+	if cursorIdx < len(segs) {
+		cursorWidth = max(1, widths[cursorIdx])
 	}
 
-	// Use inverse video to show cursor
-	cursorChar := "\x1b[7m" + atCursor + "\x1b[27m" // ESC[7m = reverse video, ESC[27m = normal
-	textWithCursor := beforeCursor + cursorChar + afterCursor
+	// Keep the cursor inside the window, moving it only as far as needed
+	i.scrollOffset = min(i.scrollOffset, cursorIdx)
+	for i.scrollOffset < cursorIdx && sumWidths(widths[i.scrollOffset:cursorIdx])+cursorWidth > availableWidth {
+		i.scrollOffset++
+	}
 
-	// Calculate visual width
-	visualLength := fasttui.VisibleWidth(textWithCursor)
-	paddingCount := availableWidth - visualLength
-	if paddingCount < 0 {
-		paddingCount = 0
+	var b strings.Builder
+	used := 0
+	for idx := i.scrollOffset; idx < len(segs); idx++ {
+		w := widths[idx]
+		if idx == cursorIdx {
+			w = cursorWidth
+		}
+		if used+w > availableWidth {
+			break
+		}
+		if idx == cursorIdx {
+			b.WriteString(marker)
+			b.WriteString("\x1b[7m")
+			b.WriteString(cells[idx])
+			b.WriteString("\x1b[27m")
+		} else {
+			b.WriteString(cells[idx])
+		}
+		used += w
+	}
+	if cursorIdx == len(segs) && used < availableWidth {
+		b.WriteString(marker)
+		b.WriteString("\x1b[7m \x1b[27m")
+		used++
 	}
-	padding := strings.Repeat(" ", paddingCount)
-	line := prompt + textWithCursor + padding
+	b.WriteString(strings.Repeat(" ", max(0, availableWidth-used)))
+	return b.String()
+}
 
-	return []string{line}
+func sumWidths(widths []int) int {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	return total
 }
 
 func (i *Input) HandleInput(data string) {
@@ -154,6 +262,9 @@ func (i *Input) HandleInput(data string) {
 
 	// Submit
 	if kb.Matches(data, keys.EditorActionSubmit) || data == "\n" {
+		if i.validate() != nil {
+			return
+		}
 		if i.onSubmit != nil {
 			i.onSubmit(i.value)
 		}
@@ -163,33 +274,16 @@ func (i *Input) HandleInput(data string) {
 	// Deletion
 	if kb.Matches(data, keys.EditorActionDeleteCharBackward) {
 		if i.cursor > 0 {
-			beforeCursor := i.value[:i.cursor]
-			graphemeLength := 1
-			if len(beforeCursor) > 0 {
-				// Simple implementation: delete one rune
-				runes := []rune(beforeCursor)
-				if len(runes) > 0 {
-					graphemeLength = len(string(runes[len(runes)-1]))
-				}
-			}
-			i.value = i.value[:i.cursor-graphemeLength] + i.value[i.cursor:]
-			i.cursor -= graphemeLength
+			start := prevGraphemeBoundary(i.value, i.cursor)
+			i.setValue(i.value[:start]+i.value[i.cursor:], start)
 		}
 		return
 	}
 
 	if kb.Matches(data, keys.EditorActionDeleteCharForward) {
 		if i.cursor < len(i.value) {
-			afterCursor := i.value[i.cursor:]
-			graphemeLength := 1
-			if len(afterCursor) > 0 {
-				// Simple implementation: delete one rune
-				runes := []rune(afterCursor)
-				if len(runes) > 0 {
-					graphemeLength = len(string(runes[0]))
-				}
-			}
-			i.value = i.value[:i.cursor] + i.value[i.cursor+graphemeLength:]
+			end := nextGraphemeBoundary(i.value, i.cursor)
+			i.setValue(i.value[:i.cursor]+i.value[end:], i.cursor)
 		}
 		return
 	}
@@ -199,45 +293,29 @@ func (i *Input) HandleInput(data string) {
 		return
 	}
 
+	if kb.Matches(data, keys.EditorActionDeleteWordForward) {
+		i.deleteWordForward()
+		return
+	}
+
 	if kb.Matches(data, keys.EditorActionDeleteToLineStart) {
-		i.value = i.value[i.cursor:]
-		i.cursor = 0
+		i.setValue(i.value[i.cursor:], 0)
 		return
 	}
 
 	if kb.Matches(data, keys.EditorActionDeleteToLineEnd) {
-		i.value = i.value[:i.cursor]
+		i.setValue(i.value[:i.cursor], i.cursor)
 		return
 	}
 
 	// Cursor movement
 	if kb.Matches(data, keys.EditorActionCursorLeft) {
-		if i.cursor > 0 {
-			beforeCursor := i.value[:i.cursor]
-			graphemeLength := 1
-			if len(beforeCursor) > 0 {
-				runes := []rune(beforeCursor)
-				if len(runes) > 0 {
-					graphemeLength = len(string(runes[len(runes)-1]))
-				}
-			}
-			i.cursor -= graphemeLength
-		}
+		i.cursor = prevGraphemeBoundary(i.value, i.cursor)
 		return
 	}
 
 	if kb.Matches(data, keys.EditorActionCursorRight) {
-		if i.cursor < len(i.value) {
-			afterCursor := i.value[i.cursor:]
-			graphemeLength := 1
-			if len(afterCursor) > 0 {
-				runes := []rune(afterCursor)
-				if len(runes) > 0 {
-					graphemeLength = len(string(runes[0]))
-				}
-			}
-			i.cursor += graphemeLength
-		}
+		i.cursor = nextGraphemeBoundary(i.value, i.cursor)
 		return
 	}
 
@@ -272,8 +350,9 @@ func (i *Input) HandleInput(data string) {
 		}
 	}
 	if !hasControlChars {
-		i.value = i.value[:i.cursor] + data + i.value[i.cursor:]
-		i.cursor += len(data)
+		i.insert(data)
+	} else if text := keys.KeyText(data); text != "" {
+		i.insert(text)
 	}
 }
 
@@ -288,10 +367,28 @@ func (i *Input) GetValue() string {
 	return i.value
 }
 
+// SetValue replaces the value, truncated to the max length, and moves the cursor to the end.
+func (i *Input) SetValue(value string) {
+	value = truncateGraphemes(value, i.maxLength)
+	i.setValue(value, len(value))
+}
+
+// Err returns the current validation error, if any.
+func (i *Input) Err() error {
+	return i.validationErr
+}
+
 func (i *Input) SetFocused(focused bool) {
 	i.focused = focused
 }
 
+// SetTheme colors the placeholder with the dim token and validation errors
+// with the error token.
+func (i *Input) SetTheme(theme *style.Theme) {
+	i.placeholderColor = func(s string) string { return theme.Fg(style.ColorDim, s) }
+	i.errorColor = func(s string) string { return theme.Fg(style.ColorError, s) }
+}
+
 func (i *Input) IsFocused() bool {
 	return i.focused
 }
@@ -310,9 +407,37 @@ func (i *Input) handlePaste(content string) {
 	cleanText = strings.ReplaceAll(cleanText, "\r", "")
 	cleanText = strings.ReplaceAll(cleanText, "\n", "")
 
-	// Insert at cursor position
-	i.value = i.value[:i.cursor] + cleanText + i.value[i.cursor:]
-	i.cursor += len(cleanText)
+	i.insert(cleanText)
+}
+
+// insert adds text at the cursor, dropping whatever would exceed the max length.
+func (i *Input) insert(text string) {
+	if i.maxLength > 0 {
+		room := i.maxLength - len(collectGraphemeSegments(i.value))
+		if room <= 0 {
+			return
+		}
+		text = truncateGraphemes(text, room)
+	}
+	if text == "" {
+		return
+	}
+	i.setValue(i.value[:i.cursor]+text+i.value[i.cursor:], i.cursor+len(text))
+}
+
+// setValue is the single mutation point for value: it moves the cursor and revalidates.
+func (i *Input) setValue(value string, cursor int) {
+	i.value = value
+	i.cursor = cursor
+	i.validate()
+}
+
+func (i *Input) validate() error {
+	if i.validator == nil {
+		return nil
+	}
+	i.validationErr = i.validator(i.value)
+	return i.validationErr
 }
 
 func (i *Input) deleteWordBackwards() {
@@ -323,10 +448,18 @@ func (i *Input) deleteWordBackwards() {
 	oldCursor := i.cursor
 	i.moveWordBackwards()
 	deleteFrom := i.cursor
-	i.cursor = oldCursor
+	i.setValue(i.value[:deleteFrom]+i.value[oldCursor:], deleteFrom)
+}
 
-	i.value = i.value[:deleteFrom] + i.value[i.cursor:]
-	i.cursor = deleteFrom
+func (i *Input) deleteWordForward() {
+	if i.cursor >= len(i.value) {
+		return
+	}
+
+	oldCursor := i.cursor
+	i.moveWordForwards()
+	deleteTo := i.cursor
+	i.setValue(i.value[:oldCursor]+i.value[deleteTo:], oldCursor)
 }
 
 func (i *Input) moveWordBackwards() {
@@ -334,33 +467,32 @@ func (i *Input) moveWordBackwards() {
 		return
 	}
 
-	textBeforeCursor := i.value[:i.cursor]
-	runes := []rune(textBeforeCursor)
+	segs := collectGraphemeSegments(i.value[:i.cursor])
+	idx := len(segs)
 
 	// Skip trailing whitespace
-	for len(runes) > 0 && fasttui.IsWhitespaceChar(string(runes[len(runes)-1])) {
-		i.cursor -= len(string(runes[len(runes)-1]))
-		runes = runes[:len(runes)-1]
+	for idx > 0 && fasttui.IsWhitespaceChar(segs[idx-1].text) {
+		idx--
 	}
 
-	if len(runes) > 0 {
-		lastRune := string(runes[len(runes)-1])
-		if fasttui.IsPunctuationChar(lastRune) {
+	if idx > 0 {
+		if fasttui.IsPunctuationChar(segs[idx-1].text) {
 			// Skip punctuation run
-			for len(runes) > 0 && fasttui.IsPunctuationChar(string(runes[len(runes)-1])) {
-				i.cursor -= len(string(runes[len(runes)-1]))
-				runes = runes[:len(runes)-1]
+			for idx > 0 && fasttui.IsPunctuationChar(segs[idx-1].text) {
+				idx--
 			}
 		} else {
 			// Skip word run
-			for len(runes) > 0 &&
-				!fasttui.IsWhitespaceChar(string(runes[len(runes)-1])) &&
-				!fasttui.IsPunctuationChar(string(runes[len(runes)-1])) {
-				i.cursor -= len(string(runes[len(runes)-1]))
-				runes = runes[:len(runes)-1]
+			for idx > 0 && isWordChar(segs[idx-1].text) {
+				idx--
 			}
 		}
 	}
+
+	if idx == len(segs) {
+		return
+	}
+	i.cursor = segs[idx].index
 }
 
 func (i *Input) moveWordForwards() {
@@ -368,32 +500,65 @@ func (i *Input) moveWordForwards() {
 		return
 	}
 
-	textAfterCursor := i.value[i.cursor:]
-	runes := []rune(textAfterCursor)
+	rest := i.value[i.cursor:]
+	segs := collectGraphemeSegments(rest)
 	idx := 0
 
 	// Skip leading whitespace
-	for idx < len(runes) && fasttui.IsWhitespaceChar(string(runes[idx])) {
-		i.cursor += len(string(runes[idx]))
+	for idx < len(segs) && fasttui.IsWhitespaceChar(segs[idx].text) {
 		idx++
 	}
 
-	if idx < len(runes) {
-		firstRune := string(runes[idx])
-		if fasttui.IsPunctuationChar(firstRune) {
+	if idx < len(segs) {
+		if fasttui.IsPunctuationChar(segs[idx].text) {
 			// Skip punctuation run
-			for idx < len(runes) && fasttui.IsPunctuationChar(string(runes[idx])) {
-				i.cursor += len(string(runes[idx]))
+			for idx < len(segs) && fasttui.IsPunctuationChar(segs[idx].text) {
 				idx++
 			}
 		} else {
 			// Skip word run
-			for idx < len(runes) &&
-				!fasttui.IsWhitespaceChar(string(runes[idx])) &&
-				!fasttui.IsPunctuationChar(string(runes[idx])) {
-				i.cursor += len(string(runes[idx]))
+			for idx < len(segs) && isWordChar(segs[idx].text) {
 				idx++
 			}
 		}
 	}
+
+	if idx == len(segs) {
+		i.cursor = len(i.value)
+		return
+	}
+	i.cursor += segs[idx].index
+}
+
+// prevGraphemeBoundary returns the start of the grapheme cluster ending at pos.
+func prevGraphemeBoundary(s string, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	segs := collectGraphemeSegments(s[:pos])
+	return segs[len(segs)-1].index
+}
+
+// nextGraphemeBoundary returns the end of the grapheme cluster starting at pos.
+func nextGraphemeBoundary(s string, pos int) int {
+	if pos >= len(s) {
+		return len(s)
+	}
+	segs := collectGraphemeSegments(s[pos:])
+	if len(segs) < 2 {
+		return len(s)
+	}
+	return pos + segs[1].index
+}
+
+// truncateGraphemes keeps at most n grapheme clusters of s; n <= 0 keeps everything.
+func truncateGraphemes(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	segs := collectGraphemeSegments(s)
+	if len(segs) <= n {
+		return s
+	}
+	return s[:segs[n].index]
 }
@@ -1,7 +1,11 @@
 package components
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/yeeaiclub/fasttui"
 )
 
 func TestInputSubmitWithBackslash(t *testing.T) {
@@ -35,3 +39,105 @@ func TestInputInsertBackslash(t *testing.T) {
 		t.Errorf("expected \"\\\\x\", got %q", input.GetValue())
 	}
 }
+
+func TestInputGraphemeEditing(t *testing.T) {
+	input := NewInput()
+
+	input.HandleInput("é") // e + combining acute
+	input.HandleInput("👩‍💻")
+	input.HandleInput("中")
+
+	input.HandleInput("\x1b[D") // left over 中
+	input.HandleInput("\x7f")   // backspace removes the whole ZWJ sequence
+	if got := input.GetValue(); got != "é中" {
+		t.Fatalf("expected grapheme-aware backspace, got %q", got)
+	}
+
+	input.HandleInput("\x1b[H")  // home
+	input.HandleInput("\x1b[3~") // delete removes e + accent together
+	if got := input.GetValue(); got != "中" {
+		t.Fatalf("expected grapheme-aware delete, got %q", got)
+	}
+}
+
+func TestInputRenderWideCharsFitWidth(t *testing.T) {
+	input := NewInput()
+	for range 30 {
+		input.HandleInput("中")
+	}
+
+	for _, width := range []int{10, 11, 20} {
+		lines := input.Render(width)
+		if got := fasttui.VisibleWidth(lines[0]); got != width {
+			t.Fatalf("width %d: rendered %d cells: %q", width, got, lines[0])
+		}
+		if !strings.Contains(lines[0], "\x1b[7m \x1b[27m") {
+			t.Fatalf("width %d: cursor at end should stay visible: %q", width, lines[0])
+		}
+	}
+}
+
+func TestInputPromptPlaceholderAndMask(t *testing.T) {
+	input := NewInput(
+		WithInputPrompt("Password: "),
+		WithInputPlaceholder("required"),
+		WithInputPlaceholderColor(func(s string) string { return "<" + s + ">" }),
+		WithInputMask("*"),
+	)
+
+	line := input.Render(30)[0]
+	if !strings.HasPrefix(line, "Password: ") || !strings.Contains(line, "<required>") {
+		t.Fatalf("expected prompt and placeholder, got %q", line)
+	}
+
+	input.HandleInput("secret")
+	line = fasttui.StripAnsi(input.Render(30)[0])
+	if strings.Contains(line, "secret") || !strings.HasPrefix(line, "Password: ******") {
+		t.Fatalf("expected masked value, got %q", line)
+	}
+	if input.GetValue() != "secret" {
+		t.Fatalf("mask must not change the value, got %q", input.GetValue())
+	}
+}
+
+func TestInputMaxLength(t *testing.T) {
+	input := NewInput(WithInputMaxLength(3))
+
+	input.HandleInput("ab")
+	input.HandleInput("\x1b[200~cdef\x1b[201~")
+	input.HandleInput("g")
+
+	if got := input.GetValue(); got != "abc" {
+		t.Fatalf("expected value capped at 3 graphemes, got %q", got)
+	}
+}
+
+func TestInputValidatorBlocksSubmit(t *testing.T) {
+	submitted := ""
+	input := NewInput(WithInputValidator(func(v string) error {
+		if len(v) < 3 {
+			return errors.New("at least 3 characters")
+		}
+		return nil
+	}))
+	input.SetOnSubmit(func(v string) { submitted = v })
+
+	input.HandleInput("ab")
+	input.HandleInput("\r")
+	if submitted != "" {
+		t.Fatalf("invalid value should not submit, got %q", submitted)
+	}
+	lines := input.Render(40)
+	if len(lines) != 2 || lines[1] != "at least 3 characters" {
+		t.Fatalf("expected inline error line, got %q", lines)
+	}
+
+	input.HandleInput("c")
+	input.HandleInput("\r")
+	if submitted != "abc" {
+		t.Fatalf("expected submit after fixing the value, got %q", submitted)
+	}
+	if lines := input.Render(40); len(lines) != 1 {
+		t.Fatalf("error line should disappear once valid, got %q", lines)
+	}
+}
@@ -0,0 +1,15 @@
+package components
+
+import "testing"
+
+func TestEditorReportedKeyText(t *testing.T) {
+	e := NewEditor(&mockEditorTerm{w: 80, h: 24}, nil)
+	e.SetFocused(true)
+	// Kitty "report all keys" with "report associated text"
+	e.HandleInput("\x1b[97;1;97u")
+	e.HandleInput("\x1b[98:66;2;66u")
+	e.HandleInput("\x1b[98:66;2:3u")
+	if e.GetTextString() != "aB" {
+		t.Fatalf("text = %q", e.GetTextString())
+	}
+}
@@ -0,0 +1,120 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/keys"
+)
+
+func withKeybindings(t *testing.T, config keys.EditorKeybindingsConfig) *keys.EditorKeybindingsManager {
+	t.Helper()
+	prev := keys.GetEditorKeybindings()
+	mgr := keys.NewEditorKeybindingsManager(config)
+	keys.SetEditorKeybindings(mgr)
+	t.Cleanup(func() { keys.SetEditorKeybindings(prev) })
+	return mgr
+}
+
+func TestEditorKeySequence(t *testing.T) {
+	mgr := withKeybindings(t, keys.EditorKeybindingsConfig{
+		keys.EditorActionDeleteToLineStart: {"ctrl+x ctrl+u"},
+		keys.EditorActionCursorLineStart:   {"g g"},
+	})
+	var indicator []string
+	mgr.SetSequenceIndicator(func(pending string) { indicator = append(indicator, pending) })
+
+	e := NewEditor(&mockEditorTerm{w: 80, h: 24}, nil)
+	e.SetFocused(true)
+	e.SetText([]string{"hello"})
+	e.SetCursor(0, 5)
+
+	e.HandleInput("\x18")
+	if mgr.PendingSequence() != "ctrl+x" || e.GetTextString() != "hello" {
+		t.Fatalf("ctrl+x should be pending, got %q / %q", mgr.PendingSequence(), e.GetTextString())
+	}
+	e.HandleInput("\x15")
+	if e.GetTextString() != "" {
+		t.Fatalf("sequence should delete to line start, text = %q", e.GetTextString())
+	}
+	if len(indicator) != 2 || indicator[0] != "ctrl+x" || indicator[1] != "" {
+		t.Fatalf("indicator calls = %q", indicator)
+	}
+
+	// An abandoned prefix is replayed as ordinary input.
+	e.HandleInput("g")
+	e.HandleInput("x")
+	if e.GetTextString() != "gx" {
+		t.Fatalf("abandoned prefix should be replayed, text = %q", e.GetTextString())
+	}
+
+	// A completed sequence runs its action and inserts nothing.
+	e.HandleInput("g")
+	e.HandleInput("g")
+	e.HandleInput("!")
+	if e.GetTextString() != "!gx" {
+		t.Fatalf("g g should move to line start, text = %q", e.GetTextString())
+	}
+}
+
+func TestEditorKeySequenceTimeoutReplay(t *testing.T) {
+	mgr := withKeybindings(t, keys.EditorKeybindingsConfig{
+		keys.EditorActionCursorLineStart: {"g g"},
+	})
+	mgr.SetSequenceTimeout(10 * time.Millisecond)
+
+	poster := &queuePoster{ch: make(chan func(), 8)}
+	e := NewEditor(&mockEditorTerm{w: 80, h: 24}, nil)
+	e.poster = poster
+	e.SetFocused(true)
+
+	e.HandleInput("g")
+	if e.GetTextString() != "" {
+		t.Fatalf("g should be pending, text = %q", e.GetTextString())
+	}
+	poster.pump(t, 1)
+	if e.GetTextString() != "g" {
+		t.Fatalf("timed out prefix should be inserted, text = %q", e.GetTextString())
+	}
+	if mgr.PendingSequence() != "" {
+		t.Fatalf("pending = %q after timeout", mgr.PendingSequence())
+	}
+}
+
+func TestEditorKeySequenceTimeoutWithoutEventLoop(t *testing.T) {
+	mgr := withKeybindings(t, keys.EditorKeybindingsConfig{
+		keys.EditorActionCursorLineStart: {"g g"},
+	})
+	mgr.SetSequenceTimeout(time.Millisecond)
+
+	e := NewEditor(&mockEditorTerm{w: 80, h: 24}, nil)
+	e.SetFocused(true)
+	e.HandleInput("g")
+	time.Sleep(20 * time.Millisecond)
+	e.HandleInput("x")
+	if e.GetTextString() != "gx" {
+		t.Fatalf("timed out prefix should be replayed with the next key, text = %q", e.GetTextString())
+	}
+}
+
+func TestEditorKeySequenceRestart(t *testing.T) {
+	withKeybindings(t, keys.EditorKeybindingsConfig{
+		keys.EditorActionCursorLineStart:   {"g g"},
+		keys.EditorActionDeleteToLineStart: {"ctrl+x ctrl+u"},
+	})
+
+	e := NewEditor(&mockEditorTerm{w: 80, h: 24}, nil)
+	e.SetFocused(true)
+	e.SetText([]string{"ab"})
+	e.SetCursor(0, 2)
+	// "g" is abandoned by a key that starts another sequence.
+	e.HandleInput("g")
+	e.HandleInput("\x18")
+	if e.GetTextString() != "abg" {
+		t.Fatalf("abandoned prefix should be inserted, text = %q", e.GetTextString())
+	}
+	e.HandleInput("\x15")
+	if e.GetTextString() != "" {
+		t.Fatalf("ctrl+x ctrl+u should delete to line start, text = %q", e.GetTextString())
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"time"
 
 	"github.com/yeeaiclub/fasttui"
+	"github.com/yeeaiclub/fasttui/style"
 )
 
-var _ fasttui.Component = (*Loader)(nil)
+var (
+	_ fasttui.Component  = (*Loader)(nil)
+	_ fasttui.ThemeAware = (*Loader)(nil)
+)
 
 const loaderDefaultTickInterval = 150 * time.Millisecond
 
@@ -30,6 +34,7 @@ type Loader struct {
 	messageColorFn func(string) string
 	tickInterval   time.Duration
 	message        string
+	plain          bool // no animation: the TUI renders into a pipe or dumb terminal
 }
 
 // LoaderOption configures optional theming and behavior of Loader.
@@ -49,6 +54,13 @@ func WithLoaderMessageColor(fn func(string) string) LoaderOption {
 	}
 }
 
+// WithLoaderStyle themes the loader, see SetTheme.
+func WithLoaderStyle(theme *style.Theme) LoaderOption {
+	return func(l *Loader) {
+		l.SetTheme(theme)
+	}
+}
+
 // WithLoaderTickInterval sets the animation frame refresh interval.
 // Non-positive values are replaced with the default when Start runs.
 func WithLoaderTickInterval(d time.Duration) LoaderOption {
@@ -72,6 +84,7 @@ func NewLoader(
 		ui:           ui,
 		tickInterval: loaderDefaultTickInterval,
 		message:      message,
+		plain:        ui != nil && ui.IsPlain(),
 	}
 
 	for _, opt := range opts {
@@ -114,11 +127,14 @@ func (l *Loader) loop() {
 	stop := l.stopCh
 	msgCh := l.msgCh
 	done := l.doneCh
-	ticker := time.NewTicker(l.tickInterval)
-	defer func() {
-		ticker.Stop()
-		close(done)
-	}()
+	// In plain mode the ticker channel stays nil so only message updates repaint.
+	var tick <-chan time.Time
+	if !l.plain {
+		ticker := time.NewTicker(l.tickInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	defer close(done)
 
 	msg := l.message
 	l.paint(0, msg)
@@ -131,17 +147,25 @@ func (l *Loader) loop() {
 		case m := <-msgCh:
 			msg = m
 			l.paint(frame, msg)
-		case <-ticker.C:
+		case <-tick:
 			frame = (frame + 1) % len(l.frames)
 			l.paint(frame, msg)
 		}
 	}
 }
 
+// SetTheme colors the spinner with the accent token and the message with the
+// muted token, from the next frame on.
+func (l *Loader) SetTheme(theme *style.Theme) {
+	l.textMu.Lock()
+	defer l.textMu.Unlock()
+	l.spinnerColorFn = func(s string) string { return theme.Fg(style.ColorAccent, s) }
+	l.messageColorFn = func(s string) string { return theme.Fg(style.ColorMuted, s) }
+}
+
 func (l *Loader) paint(frame int, msg string) {
-	line := l.buildSpinnerLine(frame, msg)
 	l.textMu.Lock()
-	l.Text.SetText(line)
+	l.Text.SetText(l.buildSpinnerLine(frame, msg))
 	l.textMu.Unlock()
 
 	if l.ui != nil {
@@ -178,6 +202,12 @@ func (l *Loader) SetMessage(message string) {
 }
 
 func (l *Loader) buildSpinnerLine(frame int, message string) string {
+	if l.plain {
+		if l.messageColorFn != nil {
+			return l.messageColorFn(message)
+		}
+		return message
+	}
 	f := l.frames[frame]
 	if l.spinnerColorFn != nil && l.messageColorFn != nil {
 		return l.spinnerColorFn(f) + " " + l.messageColorFn(message)
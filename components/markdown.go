@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/yeeaiclub/fasttui"
+	"github.com/yeeaiclub/fasttui/style"
 )
 
 type delimiterValidator func(text string, pos int) bool
@@ -42,6 +43,8 @@ type Markdown struct {
 	paddingY         int
 	defaultTextStyle *DefaultTextStyle
 	theme            *MarkdownTheme
+	noHyperlinks     bool // render links as text plus URL, without OSC 8
+	hideLinkURLs     bool // omit the " (url)" after clickable links
 
 	cachedText         *string
 	cachedWidth        *int
@@ -59,6 +62,13 @@ func WithMarkdownTheme(theme *MarkdownTheme) MarkdownOption {
 	}
 }
 
+// WithMarkdownStyle themes the markdown with MarkdownThemeFrom(theme).
+func WithMarkdownStyle(theme *style.Theme) MarkdownOption {
+	return func(m *Markdown) {
+		m.SetTheme(theme)
+	}
+}
+
 // WithMarkdownDefaultTextStyle sets the default text style (including background).
 func WithMarkdownDefaultTextStyle(style *DefaultTextStyle) MarkdownOption {
 	return func(m *Markdown) {
@@ -66,6 +76,23 @@ func WithMarkdownDefaultTextStyle(style *DefaultTextStyle) MarkdownOption {
 	}
 }
 
+// WithMarkdownHyperlinks controls whether links are emitted as clickable OSC 8
+// hyperlinks. It is on by default; terminals without OSC 8 support show the
+// link text only.
+func WithMarkdownHyperlinks(enabled bool) MarkdownOption {
+	return func(m *Markdown) {
+		m.noHyperlinks = !enabled
+	}
+}
+
+// WithMarkdownHideLinkURLs leaves out the " (url)" printed after link text
+// when links are clickable hyperlinks.
+func WithMarkdownHideLinkURLs(hide bool) MarkdownOption {
+	return func(m *Markdown) {
+		m.hideLinkURLs = hide
+	}
+}
+
 // NewMarkdown creates a Markdown component with optional theming and style options.
 func NewMarkdown(text string, paddingX, paddingY int, opts ...MarkdownOption) *Markdown {
 	m := &Markdown{
@@ -88,6 +115,12 @@ func (m *Markdown) SetText(text string) {
 	m.Invalidate()
 }
 
+// SetTheme replaces the markdown theme with MarkdownThemeFrom(theme).
+func (m *Markdown) SetTheme(theme *style.Theme) {
+	m.theme = MarkdownThemeFrom(theme)
+	m.Invalidate()
+}
+
 func (m *Markdown) Invalidate() {
 	m.cachedText = nil
 	m.cachedWidth = nil
@@ -143,18 +176,26 @@ func (m *Markdown) renderMarkdown(text string, width int) []string {
 	lines := strings.Split(text, "\n")
 	result := []string{}
 	inCodeBlock := false
+	var codeLang string
+	var codeLines []string
 
 	for i := range lines {
 		line := lines[i]
 
 		// Code block fences and content
-		if strings.HasPrefix(strings.TrimSpace(line), "```") {
-			inCodeBlock = m.handleCodeBlockFence(strings.TrimSpace(line), inCodeBlock, &result)
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") {
+			if inCodeBlock {
+				m.flushCodeBlock(codeLines, codeLang, &result)
+				codeLines = nil
+			} else {
+				codeLang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			inCodeBlock = m.handleCodeBlockFence(trimmed, inCodeBlock, &result)
 			continue
 		}
 
 		if inCodeBlock {
-			m.handleCodeBlockLine(line, &result)
+			codeLines = append(codeLines, line)
 			continue
 		}
 
@@ -191,6 +232,10 @@ func (m *Markdown) renderMarkdown(text string, width int) []string {
 		m.handleParagraph(line, i, lines, &result)
 	}
 
+	// An unterminated block runs to the end of the text.
+	if inCodeBlock {
+		m.flushCodeBlock(codeLines, codeLang, &result)
+	}
 	return result
 }
 
@@ -221,6 +266,27 @@ func (m *Markdown) handleCodeBlockFence(trimmedLine string, inCodeBlock bool, re
 	return false
 }
 
+// flushCodeBlock renders a code block's content, through the theme's
+// HighlightCode when set so tokens spanning lines are highlighted as a whole.
+func (m *Markdown) flushCodeBlock(lines []string, lang string, result *[]string) {
+	if len(lines) == 0 {
+		return
+	}
+	if m.theme == nil || m.theme.HighlightCode == nil {
+		for _, line := range lines {
+			m.handleCodeBlockLine(line, result)
+		}
+		return
+	}
+	indent := "  "
+	if m.theme.CodeBlockIndent != "" {
+		indent = m.theme.CodeBlockIndent
+	}
+	for _, line := range m.theme.HighlightCode(strings.Join(lines, "\n"), lang) {
+		*result = append(*result, indent+line)
+	}
+}
+
 func (m *Markdown) handleCodeBlockLine(line string, result *[]string) {
 	indent := "  "
 	if m.theme != nil && m.theme.CodeBlockIndent != "" {
@@ -512,19 +578,23 @@ func (m *Markdown) replaceLinks(text string) string {
 		linkText := remaining[start+1 : textEnd]
 		linkURL := remaining[textEnd+2 : urlEnd]
 
-		// Check if link text matches URL
-		if linkText == linkURL || (strings.HasPrefix(linkURL, "mailto:") && linkText == linkURL[7:]) {
-			if m.theme.Underline != nil {
-				result.WriteString(m.theme.Link(m.theme.Underline(linkText)))
-			} else {
-				result.WriteString(m.theme.Link(linkText))
-			}
-		} else {
-			if m.theme.Underline != nil {
-				result.WriteString(m.theme.Link(m.theme.Underline(linkText)))
-			} else {
-				result.WriteString(m.theme.Link(linkText))
-			}
+		styled := linkText
+		if m.theme.Underline != nil {
+			styled = m.theme.Underline(styled)
+		}
+		styled = m.theme.Link(styled)
+		if !m.noHyperlinks {
+			styled = fasttui.Hyperlink(styled, fasttui.StripAnsi(linkURL))
+		}
+		result.WriteString(styled)
+
+		// Show the URL unless the link text already is the URL, or the
+		// link is clickable and URLs are hidden
+		showURL := linkText != linkURL && !(strings.HasPrefix(linkURL, "mailto:") && linkText == linkURL[7:])
+		if !m.noHyperlinks && m.hideLinkURLs {
+			showURL = false
+		}
+		if showURL {
 			if m.theme.LinkURL != nil {
 				result.WriteString(m.theme.LinkURL(" (" + linkURL + ")"))
 			} else {
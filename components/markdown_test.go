@@ -132,3 +132,29 @@ func TestRenderInline_UnderscoreEmphasis(t *testing.T) {
 	assert.Equal(t, "<i>foo_bar</i>", m.renderInline("_foo_bar_"))
 	assert.Equal(t, "<b>bold</b>", m.renderInline("__bold__"))
 }
+
+func TestRenderInline_Hyperlinks(t *testing.T) {
+	theme := &MarkdownTheme{Link: func(s string) string { return "\x1b[36m" + s + "\x1b[39m" }}
+
+	m := NewMarkdown("", 0, 0, WithMarkdownTheme(theme))
+	assert.Equal(t, "see \x1b]8;;https://go.dev\x07\x1b[36mGo\x1b[39m\x1b]8;;\x07 (https://go.dev)", m.renderInline("see [Go](https://go.dev)"))
+
+	m = NewMarkdown("", 0, 0, WithMarkdownTheme(theme), WithMarkdownHideLinkURLs(true))
+	assert.Equal(t, "\x1b]8;;https://go.dev\x07\x1b[36mGo\x1b[39m\x1b]8;;\x07", m.renderInline("[Go](https://go.dev)"))
+
+	m = NewMarkdown("", 0, 0, WithMarkdownTheme(theme), WithMarkdownHyperlinks(false), WithMarkdownHideLinkURLs(true))
+	assert.Equal(t, "\x1b[36mGo\x1b[39m (https://go.dev)", m.renderInline("[Go](https://go.dev)"))
+}
+
+func TestMarkdownWrapsHyperlinkAcrossLines(t *testing.T) {
+	m := NewMarkdown("[one two three four](https://example.com)", 0, 0,
+		WithMarkdownTheme(&MarkdownTheme{Link: func(s string) string { return s }}),
+		WithMarkdownHideLinkURLs(true))
+
+	lines := m.Render(10)
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "\x1b]8;;https://example.com\x07"), "line %q should reopen the link", line)
+		assert.Contains(t, line, fasttui.HyperlinkClose, "line %q should close the link", line)
+	}
+}
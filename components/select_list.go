@@ -2,10 +2,13 @@ package components
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/yeeaiclub/fasttui"
 	"github.com/yeeaiclub/fasttui/keys"
+	"github.com/yeeaiclub/fasttui/style"
 )
 
 var _ fasttui.Component = (*SelectList)(nil)
@@ -14,6 +17,15 @@ type SelectItem struct {
 	Label       string
 	Value       string
 	Description string
+	// Detail is shown right-aligned, e.g. a shortcut or metadata.
+	Detail string
+	// Group names the section the item belongs to. Consecutive items of the
+	// same group are rendered under a non-selectable header.
+	Group string
+	// Disabled items are shown but cannot be selected; DisabledReason is
+	// displayed in place of the description.
+	Disabled       bool
+	DisabledReason string
 }
 
 type SelectListTheme struct {
@@ -22,16 +34,42 @@ type SelectListTheme struct {
 	NoMatch        func(string) string
 	ScrollInfo     func(string) string
 	Description    func(string) string
+	// Match styles the label runes matched by the highlight query.
+	// Defaults to bold.
+	Match func(string) string
+	// Header styles group headers. Defaults to bold.
+	Header func(string) string
+	// Disabled styles disabled items.
+	Disabled func(string) string
+	// Detail styles the right-aligned column.
+	Detail func(string) string
+	// Filter styles the filter line.
+	Filter func(string) string
+	// Selected styles the selected row, padded to the full width.
+	Selected func(string) string
+	// Checked and Unchecked are the multi-select checkboxes.
+	// Default to "[x] " and "[ ] ".
+	Checked   string
+	Unchecked string
 }
 
 type SelectList struct {
 	items         []SelectItem
-	filteredItems []SelectItem
-	selectedIndex int
+	filtered      []int // indices into items, in display order
+	selectedIndex int   // index into filtered
 	maxVisible    int
 	theme         SelectListTheme
+	highlight     FuzzyQuery
+
+	filterEnabled bool
+	filterPrompt  string
+	filter        string
+
+	multiSelect bool
+	checked     map[int]bool
 
 	onSelect          func(item SelectItem)
+	onConfirm         func(items []SelectItem)
 	onCancel          func()
 	onSelectionChange func(item SelectItem)
 }
@@ -46,13 +84,44 @@ func WithSelectListTheme(theme SelectListTheme) SelectListOption {
 	}
 }
 
+// WithSelectListStyle themes the list with SelectListThemeFrom(theme).
+func WithSelectListStyle(theme *style.Theme) SelectListOption {
+	return func(s *SelectList) {
+		s.SetTheme(theme)
+	}
+}
+
+// WithSelectListHighlight highlights the parts of each label matched by query.
+func WithSelectListHighlight(query string) SelectListOption {
+	return func(s *SelectList) {
+		s.SetHighlightQuery(query)
+	}
+}
+
+// WithSelectListFilter shows a filter line above the items. Typed text narrows
+// the list with FuzzyFilter and highlights the matches.
+func WithSelectListFilter(prompt string) SelectListOption {
+	return func(s *SelectList) {
+		s.filterEnabled = true
+		s.filterPrompt = prompt
+	}
+}
+
+// WithSelectListMultiSelect renders checkboxes. Space (or Tab while filtering)
+// toggles the current item and confirm reports every checked item through
+// SetOnConfirm.
+func WithSelectListMultiSelect() SelectListOption {
+	return func(s *SelectList) {
+		s.multiSelect = true
+	}
+}
+
 // NewSelectList creates a SelectList with optional theming options.
 func NewSelectList(items []SelectItem, maxVisible int, opts ...SelectListOption) *SelectList {
 	s := &SelectList{
-		items:         items,
-		filteredItems: items,
-		selectedIndex: 0,
-		maxVisible:    maxVisible,
+		items:      items,
+		maxVisible: maxVisible,
+		checked:    make(map[int]bool),
 		theme: SelectListTheme{
 			SelectedPrefix: "→ ",
 			NormalPrefix:   "  ",
@@ -65,172 +134,403 @@ func NewSelectList(items []SelectItem, maxVisible int, opts ...SelectListOption)
 		}
 	}
 
+	s.applyFilter()
 	return s
 }
 
-func (s *SelectList) Render(width int) []string {
-	visible := min(s.maxVisible, len(s.filteredItems))
-	lines := make([]string, 0, visible+1)
+// SetTheme replaces the list theme with SelectListThemeFrom(theme), keeping
+// the configured checkboxes.
+func (s *SelectList) SetTheme(theme *style.Theme) {
+	next := SelectListThemeFrom(theme)
+	next.Checked, next.Unchecked = s.theme.Checked, s.theme.Unchecked
+	s.theme = next
+}
 
-	if len(s.filteredItems) == 0 {
-		if s.theme.NoMatch != nil {
-			lines = append(lines, s.theme.NoMatch("  No matching commands"))
-		} else {
-			lines = append(lines, "  No matching commands")
+// SetHighlightQuery sets the FuzzyQuery whose matches are highlighted in labels.
+func (s *SelectList) SetHighlightQuery(query string) {
+	s.highlight = ParseFuzzyQuery(query)
+}
+
+// SetFilter replaces the filter text and re-filters the items.
+func (s *SelectList) SetFilter(filter string) {
+	s.filter = filter
+	s.applyFilter()
+}
+
+// Filter returns the current filter text.
+func (s *SelectList) Filter() string {
+	return s.filter
+}
+
+// CheckedItems returns the checked items in list order.
+func (s *SelectList) CheckedItems() []SelectItem {
+	var items []SelectItem
+	for i, item := range s.items {
+		if s.checked[i] {
+			items = append(items, item)
 		}
-		return lines
 	}
+	return items
+}
+
+func itemLabel(item SelectItem) string {
+	if item.Label != "" {
+		return item.Label
+	}
+	return item.Value
+}
 
-	startIndex := max(0, min(s.selectedIndex-s.maxVisible/2, len(s.filteredItems)-s.maxVisible))
-	endIndex := min(startIndex+s.maxVisible, len(s.filteredItems))
+// applyFilter recomputes the visible items. Matches are ranked by score but
+// stay grouped under their section, in the order sections first appear.
+func (s *SelectList) applyFilter() {
+	indices := make([]int, len(s.items))
+	for i := range indices {
+		indices[i] = i
+	}
+	if s.filterEnabled {
+		s.SetHighlightQuery(s.filter)
+		indices = FuzzyFilter(indices, s.filter, func(i int) string {
+			return itemLabel(s.items[i])
+		})
+	}
 
-	for i := startIndex; i < endIndex; i++ {
-		item := s.filteredItems[i]
-		isSelected := i == s.selectedIndex
-		line := ""
-		if isSelected {
-			line = s.handleSelect(item, width)
-		} else {
-			line = s.handleNoSelect(item, width)
+	groupOrder := make(map[string]int)
+	for _, item := range s.items {
+		if _, ok := groupOrder[item.Group]; !ok {
+			groupOrder[item.Group] = len(groupOrder)
 		}
-		lines = append(lines, line)
 	}
+	slices.SortStableFunc(indices, func(a, b int) int {
+		return groupOrder[s.items[a].Group] - groupOrder[s.items[b].Group]
+	})
 
-	if startIndex > 0 || endIndex < len(s.filteredItems) {
-		rateText := fmt.Sprintf(" (%d/%d)", s.selectedIndex+1, len(s.filteredItems))
-		if s.theme.ScrollInfo != nil {
-			lines = append(lines, s.theme.ScrollInfo(rateText))
-		} else {
-			lines = append(lines, rateText)
+	s.filtered = indices
+	s.selectedIndex = s.nextSelectable(-1, 1)
+}
+
+// nextSelectable returns the first enabled position after from in direction
+// dir, wrapping around, or -1 when nothing is selectable.
+func (s *SelectList) nextSelectable(from, dir int) int {
+	n := len(s.filtered)
+	for step := 1; step <= n; step++ {
+		i := ((from+dir*step)%n + n) % n
+		if !s.items[s.filtered[i]].Disabled {
+			return i
 		}
 	}
-	return lines
+	return -1
 }
 
-func (s *SelectList) handleSelect(item SelectItem, width int) string {
-	prefix := "→ "
-	prefixLen := len(prefix)
+func (s *SelectList) hasSelection() bool {
+	return s.selectedIndex >= 0 && s.selectedIndex < len(s.filtered)
+}
 
-	display := item.Value
-	if item.Label != "" {
-		display = item.Label
+// selectRow is one rendered line: a group header or an item.
+type selectRow struct {
+	header string
+	pos    int // index into filtered; -1 for headers
+}
+
+func (s *SelectList) rows() []selectRow {
+	rows := make([]selectRow, 0, len(s.filtered))
+	group := ""
+	for pos, idx := range s.filtered {
+		item := s.items[idx]
+		if item.Group != "" && (pos == 0 || item.Group != group) {
+			rows = append(rows, selectRow{header: item.Group, pos: -1})
+		}
+		group = item.Group
+		rows = append(rows, selectRow{pos: pos})
 	}
+	return rows
+}
 
-	descLine := ""
-	if item.Description != "" {
-		descLine = normalizeToSingleLine(item.Description)
+func (s *SelectList) Render(width int) []string {
+	var lines []string
+	if s.filterEnabled {
+		lines = append(lines, s.renderFilter(width))
 	}
 
-	if width < 40 || descLine == "" {
-		maxWidth := width - prefixLen - 2
-		return fmt.Sprintf("%s %s", prefix, fasttui.TruncateToWidth(display, maxWidth, "", false))
+	if len(s.filtered) == 0 {
+		text := "  No matching commands"
+		if s.filterEnabled {
+			text = "  No matching items"
+		}
+		return append(lines, styled(s.theme.NoMatch, text))
 	}
 
-	maxValueWidth := min(30, width-prefixLen-4)
-	truncatedValue := fasttui.TruncateToWidth(display, maxValueWidth, "", false)
-	spacing := strings.Repeat(" ", max(1, 32-len(truncatedValue)))
-	start := prefixLen + len(truncatedValue) + len(spacing)
-	remainingWidth := width - start - 2
+	rows := s.rows()
+	selectedRow := 0
+	for i, row := range rows {
+		if row.pos >= 0 && row.pos == s.selectedIndex {
+			selectedRow = i
+		}
+	}
+	startIndex := max(0, min(selectedRow-s.maxVisible/2, len(rows)-s.maxVisible))
+	endIndex := min(startIndex+s.maxVisible, len(rows))
+
+	for _, row := range rows[startIndex:endIndex] {
+		if row.pos < 0 {
+			header := fasttui.TruncateToWidth(row.header, max(1, width-2), "", false)
+			if s.theme.Header != nil {
+				header = s.theme.Header(header)
+			} else {
+				header = "\x1b[1m" + header + "\x1b[22m"
+			}
+			lines = append(lines, header)
+			continue
+		}
+		lines = append(lines, s.renderItem(s.items[s.filtered[row.pos]], s.filtered[row.pos], row.pos == s.selectedIndex, width))
+	}
 
-	if remainingWidth > 10 && descLine != "" {
-		desc := fasttui.TruncateToWidth(descLine, remainingWidth, "", false)
-		descText := s.theme.Description(spacing + desc)
-		return prefix + truncatedValue + descText
+	if startIndex > 0 || endIndex < len(rows) {
+		rateText := fmt.Sprintf(" (%d/%d)", max(0, s.selectedIndex)+1, len(s.filtered))
+		lines = append(lines, styled(s.theme.ScrollInfo, rateText))
 	}
-	maxWidth := width - len(prefix) - 2
-	return prefix + fasttui.TruncateToWidth(display, maxWidth, "", false)
+	return lines
 }
 
-func (s *SelectList) handleNoSelect(item SelectItem, width int) string {
-	prefix := "  "
-	prefixLen := len(prefix)
-	display := item.Value
-	if item.Label != "" {
-		display = item.Label
+func (s *SelectList) renderFilter(width int) string {
+	prompt := s.filterPrompt
+	if prompt == "" {
+		prompt = "> "
 	}
+	line := fasttui.TruncateToWidth(prompt+s.filter, max(1, width-1), "", false)
+	return styled(s.theme.Filter, line) + "\x1b[7m \x1b[0m"
+}
 
-	descLine := ""
-	if item.Description != "" {
-		descLine = normalizeToSingleLine(item.Description)
+func styled(style func(string) string, text string) string {
+	if style == nil {
+		return text
+	}
+	return style(text)
+}
+
+// displayText returns the item's label with matched runes highlighted.
+func (s *SelectList) displayText(item SelectItem) string {
+	display := itemLabel(item)
+	if s.highlight.Empty() || item.Disabled {
+		return display
+	}
+	match := s.highlight.Match(display)
+	if !match.Matches {
+		return display
 	}
+	style := s.theme.Match
+	if style == nil {
+		style = func(text string) string { return "\x1b[1m" + text + "\x1b[22m" }
+	}
+	return HighlightMatches(display, match.Positions, style)
+}
 
-	if descLine == "" || width < 40 {
-		maxWidth := width - len(prefix) - 2
-		line := prefix + fasttui.TruncateToWidth(display, maxWidth, "", false)
-		return line
+func (s *SelectList) renderItem(item SelectItem, index int, selected bool, width int) string {
+	prefix := orDefault(s.theme.NormalPrefix, "  ")
+	if selected {
+		prefix = orDefault(s.theme.SelectedPrefix, "→ ")
+	}
+	if s.multiSelect {
+		if s.checked[index] {
+			prefix += orDefault(s.theme.Checked, "[x] ")
+		} else {
+			prefix += orDefault(s.theme.Unchecked, "[ ] ")
+		}
+	}
+	prefixLen := fasttui.VisibleWidth(prefix)
+
+	detail := ""
+	detailWidth := 0
+	if item.Detail != "" {
+		detail = normalizeToSingleLine(item.Detail)
+		detailWidth = fasttui.VisibleWidth(detail) + 1
+		if detailWidth > width/3 {
+			detail, detailWidth = "", 0
+		}
 	}
+	available := width - detailWidth
 
-	maxValueWidth := min(30, width-prefixLen-4)
-	truncatedValue := fasttui.TruncateToWidth(display, maxValueWidth, "", false)
-	spacing := strings.Repeat(" ", max(1, 32-len(truncatedValue)))
+	descLine := ""
+	if item.Disabled && item.DisabledReason != "" {
+		descLine = "(" + normalizeToSingleLine(item.DisabledReason) + ")"
+	} else if item.Description != "" {
+		descLine = normalizeToSingleLine(item.Description)
+	}
 
-	start := prefixLen + len(truncatedValue) + len(spacing)
-	remainingWidth := width - start - 2
+	display := s.displayText(item)
+	var line string
+	maxValueWidth := min(30, available-prefixLen-4)
+	truncatedValue := fasttui.TruncateToWidth(display, max(1, maxValueWidth), "", false)
+	spacing := strings.Repeat(" ", max(1, 32-fasttui.VisibleWidth(truncatedValue)))
+	remainingWidth := available - (prefixLen + fasttui.VisibleWidth(truncatedValue) + len(spacing)) - 2
 
-	if remainingWidth > 10 && descLine != "" {
+	if width >= 40 && descLine != "" && remainingWidth > 10 {
 		desc := fasttui.TruncateToWidth(descLine, remainingWidth, "", false)
-		descText := s.theme.Description(spacing + desc)
-		return prefix + truncatedValue + descText
+		line = prefix + truncatedValue + styled(s.theme.Description, spacing+desc)
+	} else {
+		line = prefix + fasttui.TruncateToWidth(display, max(1, available-prefixLen-2), "", false)
 	}
 
-	maxWidth := width - len(prefix) - 2
-	return prefix + fasttui.TruncateToWidth(display, maxWidth, "", false)
+	if item.Disabled {
+		line = styled(s.theme.Disabled, fasttui.StripAnsi(line))
+	}
+	if detail != "" {
+		pad := max(1, width-fasttui.VisibleWidth(line)-detailWidth+1)
+		line += strings.Repeat(" ", pad) + styled(s.theme.Detail, detail)
+	}
+	if selected && s.theme.Selected != nil {
+		line += strings.Repeat(" ", max(0, width-fasttui.VisibleWidth(line)))
+		line = s.theme.Selected(line)
+	}
+	return line
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
 }
 
 func (s *SelectList) HandleInput(keyData string) {
-	if len(s.filteredItems) == 0 {
-		kb := keys.GetEditorKeybindings()
-		if kb.Matches(keyData, keys.EditorActionSelectCancel) {
-			if s.onCancel != nil {
-				s.onCancel()
+	kb := keys.GetEditorKeybindings()
+
+	if kb.Matches(keyData, keys.EditorActionSelectCancel) {
+		if s.onCancel != nil {
+			s.onCancel()
+		}
+		return
+	}
+
+	if s.multiSelect && s.isToggleKey(keyData) {
+		if s.hasSelection() {
+			index := s.filtered[s.selectedIndex]
+			s.checked[index] = !s.checked[index]
+			if !s.checked[index] {
+				delete(s.checked, index)
 			}
 		}
 		return
 	}
 
+	if s.filterEnabled && s.handleFilterInput(keyData) {
+		return
+	}
+
+	if !s.hasSelection() {
+		return
+	}
+
+	switch {
+	case kb.Matches(keyData, keys.EditorActionSelectUp):
+		s.moveSelection(-1, 1)
+	case kb.Matches(keyData, keys.EditorActionSelectDown):
+		s.moveSelection(1, 1)
+	case kb.Matches(keyData, keys.EditorActionSelectPageUp):
+		s.moveSelection(-1, max(1, s.maxVisible-1))
+	case kb.Matches(keyData, keys.EditorActionSelectPageDown):
+		s.moveSelection(1, max(1, s.maxVisible-1))
+	case kb.Matches(keyData, keys.EditorActionSelectConfirm):
+		s.confirm()
+	}
+}
+
+// isToggleKey reports whether data toggles a checkbox: space, unless the
+// filter line is taking text, and tab.
+func (s *SelectList) isToggleKey(data string) bool {
+	if keys.MatchesKey(data, "tab") {
+		return true
+	}
+	return !s.filterEnabled && keys.MatchesKey(data, "space")
+}
+
+// handleFilterInput edits the filter line and reports whether data was consumed.
+func (s *SelectList) handleFilterInput(data string) bool {
 	kb := keys.GetEditorKeybindings()
-	if kb.Matches(keyData, keys.EditorActionSelectUp) {
-		if s.selectedIndex == 0 {
-			s.selectedIndex = len(s.filteredItems) - 1
-		} else {
-			s.selectedIndex--
+	switch {
+	case kb.Matches(data, keys.EditorActionDeleteCharBackward):
+		if s.filter == "" {
+			return true
 		}
-		s.notifySelectionChange()
+		runes := []rune(s.filter)
+		s.SetFilter(string(runes[:len(runes)-1]))
+		return true
+	case kb.Matches(data, keys.EditorActionDeleteToLineStart):
+		s.SetFilter("")
+		return true
+	case isPrintableInput(data):
+		s.SetFilter(s.filter + data)
+		return true
+	case keys.KeyText(data) != "":
+		s.SetFilter(s.filter + keys.KeyText(data))
+		return true
 	}
+	return false
+}
 
-	if kb.Matches(keyData, keys.EditorActionSelectDown) {
-		if s.selectedIndex == len(s.filteredItems)-1 {
-			s.selectedIndex = 0
-		} else {
-			s.selectedIndex++
+func isPrintableInput(data string) bool {
+	if data == "" {
+		return false
+	}
+	for _, r := range data {
+		if !unicode.IsPrint(r) {
+			return false
 		}
-		s.notifySelectionChange()
 	}
+	return true
+}
 
-	if kb.Matches(keyData, keys.EditorActionSelectConfirm) {
-		item := s.getSelectItem()
-		if s.onSelect != nil {
-			s.onSelect(item)
+// moveSelection moves by count selectable items, wrapping for single steps and
+// clamping for page moves.
+func (s *SelectList) moveSelection(dir, count int) {
+	pos := s.selectedIndex
+	for range count {
+		next := s.nextSelectable(pos, dir)
+		if next < 0 {
+			break
+		}
+		if count > 1 && (dir > 0 && next < pos || dir < 0 && next > pos) {
+			break
 		}
+		pos = next
+	}
+	if pos != s.selectedIndex {
+		s.selectedIndex = pos
+		s.notifySelectionChange()
 	}
+}
 
-	if kb.Matches(keyData, keys.EditorActionSelectCancel) {
-		if s.onCancel != nil {
-			s.onCancel()
+func (s *SelectList) confirm() {
+	item := s.getSelectItem()
+	if s.multiSelect {
+		items := s.CheckedItems()
+		if len(items) == 0 {
+			items = []SelectItem{item}
 		}
+		if s.onConfirm != nil {
+			s.onConfirm(items)
+		}
+		return
+	}
+	if s.onSelect != nil {
+		s.onSelect(item)
+	}
+	if s.onConfirm != nil {
+		s.onConfirm([]SelectItem{item})
 	}
 }
 
 func (s *SelectList) notifySelectionChange() {
-	selectItem := s.filteredItems[s.selectedIndex]
-	if s.onSelectionChange != nil {
-		s.onSelectionChange(selectItem)
+	if s.onSelectionChange != nil && s.hasSelection() {
+		s.onSelectionChange(s.getSelectItem())
 	}
 }
 
+// getSelectItem returns the highlighted item, or the zero item when nothing
+// is selectable.
 func (s *SelectList) getSelectItem() SelectItem {
-	item := s.filteredItems[s.selectedIndex]
-	return item
+	if !s.hasSelection() {
+		return SelectItem{}
+	}
+	return s.items[s.filtered[s.selectedIndex]]
 }
 
 func normalizeToSingleLine(text string) string {
@@ -242,6 +542,13 @@ func (s *SelectList) SetOnSelect(onSelect func(item SelectItem)) {
 	s.onSelect = onSelect
 }
 
+// SetOnConfirm is called on confirm with the checked items in multi-select
+// mode (the current item when none is checked), or with the single selected
+// item otherwise.
+func (s *SelectList) SetOnConfirm(onConfirm func(items []SelectItem)) {
+	s.onConfirm = onConfirm
+}
+
 func (s *SelectList) SetOnCancel(onCancel func()) {
 	s.onCancel = onCancel
 }
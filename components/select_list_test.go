@@ -0,0 +1,104 @@
+package components
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yeeaiclub/fasttui"
+)
+
+func renderPlain(s *SelectList, width int) []string {
+	lines := s.Render(width)
+	for i, line := range lines {
+		lines[i] = fasttui.StripAnsi(line)
+	}
+	return lines
+}
+
+func TestSelectListFilterAsYouType(t *testing.T) {
+	s := NewSelectList([]SelectItem{
+		{Value: "git-status", Label: "Git Status"},
+		{Value: "git-commit", Label: "Git Commit"},
+		{Value: "open-file", Label: "Open File"},
+	}, 10, WithSelectListFilter("> "))
+
+	for _, r := range "comm" {
+		s.HandleInput(string(r))
+	}
+	lines := renderPlain(s, 60)
+	if lines[0] != "> comm " || len(lines) != 2 || !strings.Contains(lines[1], "Git Commit") {
+		t.Fatalf("unexpected filtered render %q", lines)
+	}
+
+	s.HandleInput("\x7f")
+	if s.Filter() != "com" {
+		t.Fatalf("backspace: filter = %q", s.Filter())
+	}
+
+	s.SetFilter("zzz")
+	if lines := renderPlain(s, 60); !strings.Contains(lines[1], "No matching items") {
+		t.Fatalf("expected empty state, got %q", lines)
+	}
+}
+
+func TestSelectListGroupsAndDisabledItems(t *testing.T) {
+	s := NewSelectList([]SelectItem{
+		{Value: "new", Group: "File"},
+		{Value: "open", Group: "File", Disabled: true, DisabledReason: "no workspace"},
+		{Value: "save", Group: "File"},
+		{Value: "undo", Group: "Edit"},
+	}, 10)
+
+	lines := renderPlain(s, 60)
+	want := []string{"File", "→ new", "  open", "  save", "Edit", "  undo"}
+	if len(lines) != len(want) {
+		t.Fatalf("render = %q", lines)
+	}
+	for i := range want {
+		if !strings.HasPrefix(lines[i], want[i]) {
+			t.Fatalf("line %d = %q, want prefix %q", i, lines[i], want[i])
+		}
+	}
+	if !strings.Contains(lines[2], "(no workspace)") {
+		t.Fatalf("disabled reason missing: %q", lines[2])
+	}
+
+	var selected []string
+	s.SetOnSelectionChange(func(item SelectItem) { selected = append(selected, item.Value) })
+	s.HandleInput("\x1b[B")
+	s.HandleInput("\x1b[B")
+	s.HandleInput("\x1b[B")
+	if !slices.Equal(selected, []string{"save", "undo", "new"}) {
+		t.Fatalf("navigation should skip headers and disabled items, got %v", selected)
+	}
+}
+
+func TestSelectListMultiSelect(t *testing.T) {
+	s := NewSelectList([]SelectItem{{Value: "a"}, {Value: "b"}, {Value: "c"}}, 10, WithSelectListMultiSelect())
+
+	var confirmed []SelectItem
+	s.SetOnConfirm(func(items []SelectItem) { confirmed = items })
+
+	s.HandleInput(" ")
+	s.HandleInput("\x1b[B")
+	s.HandleInput("\x1b[B")
+	s.HandleInput(" ")
+	lines := renderPlain(s, 40)
+	if !strings.HasPrefix(lines[0], "  [x] a") || !strings.HasPrefix(lines[1], "  [ ] b") || !strings.HasPrefix(lines[2], "→ [x] c") {
+		t.Fatalf("unexpected checkboxes %q", lines)
+	}
+
+	s.HandleInput("\r")
+	if len(confirmed) != 2 || confirmed[0].Value != "a" || confirmed[1].Value != "c" {
+		t.Fatalf("confirmed = %+v", confirmed)
+	}
+}
+
+func TestSelectListDetailColumn(t *testing.T) {
+	s := NewSelectList([]SelectItem{{Value: "copy", Detail: "ctrl+c"}}, 10)
+	lines := renderPlain(s, 40)
+	if !strings.HasSuffix(lines[0], " ctrl+c") || fasttui.VisibleWidth(lines[0]) != 40 {
+		t.Fatalf("detail should be right-aligned to the width, got %q", lines[0])
+	}
+}
@@ -1,5 +1,7 @@
 package components
 
+import "github.com/yeeaiclub/fasttui/style"
+
 // MarkdownTheme defines theme functions for markdown elements
 type MarkdownTheme struct {
 	Heading         func(string) string
@@ -19,3 +21,50 @@ type MarkdownTheme struct {
 	HighlightCode   func(code string, lang string) []string
 	CodeBlockIndent string
 }
+
+// MarkdownThemeFrom builds a MarkdownTheme from the theme's markdown tokens,
+// with code blocks highlighted by CodeHighlighter.
+func MarkdownThemeFrom(theme *style.Theme) *MarkdownTheme {
+	fg := func(color style.ThemeColor) func(string) string {
+		return func(s string) string { return theme.Fg(color, s) }
+	}
+	return &MarkdownTheme{
+		Heading:         func(s string) string { return theme.Bold(theme.Fg(style.ColorMdHeading, s)) },
+		Link:            fg(style.ColorMdLink),
+		LinkURL:         fg(style.ColorMdLinkURL),
+		Code:            fg(style.ColorMdCode),
+		CodeBlock:       fg(style.ColorMdCodeBlock),
+		CodeBlockBorder: fg(style.ColorMdCodeBlockBorder),
+		Quote:           func(s string) string { return theme.Italic(theme.Fg(style.ColorMdQuote, s)) },
+		QuoteBorder:     fg(style.ColorMdQuoteBorder),
+		HR:              fg(style.ColorMdHr),
+		ListBullet:      fg(style.ColorMdListBullet),
+		Bold:            theme.Bold,
+		Italic:          theme.Italic,
+		Strikethrough:   theme.Strikethrough,
+		Underline:       theme.Underline,
+		HighlightCode:   CodeHighlighter(theme),
+	}
+}
+
+// SelectListThemeFrom builds a SelectListTheme from the theme: the selected
+// row on the selectedBg background, matches and the filter line in the accent
+// token, descriptions and details muted, and disabled items dim.
+func SelectListThemeFrom(theme *style.Theme) SelectListTheme {
+	fg := func(color style.ThemeColor) func(string) string {
+		return func(s string) string { return theme.Fg(color, s) }
+	}
+	return SelectListTheme{
+		SelectedPrefix: theme.Fg(style.ColorAccent, "→ "),
+		NormalPrefix:   "  ",
+		NoMatch:        fg(style.ColorMuted),
+		ScrollInfo:     fg(style.ColorDim),
+		Description:    fg(style.ColorMuted),
+		Match:          func(s string) string { return theme.Bold(theme.Fg(style.ColorAccent, s)) },
+		Header:         func(s string) string { return theme.Bold(theme.Fg(style.ColorMdHeading, s)) },
+		Disabled:       fg(style.ColorDim),
+		Detail:         fg(style.ColorMuted),
+		Filter:         fg(style.ColorAccent),
+		Selected:       func(s string) string { return theme.Bg(style.BgSelected, s) },
+	}
+}
@@ -0,0 +1,88 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeeaiclub/fasttui"
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+func TestComponentsFollowTheme(t *testing.T) {
+	theme, err := style.LoadTheme("dark", style.WithColorMode(style.ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	border := NewDynamicBorder()
+	border.SetTheme(theme)
+	if line := border.Render(4)[0]; !strings.HasPrefix(line, theme.FgANSI(style.ColorBorder)) {
+		t.Fatalf("border not themed: %q", line)
+	}
+
+	input := NewInput(WithInputPlaceholder("type here"))
+	input.SetTheme(theme)
+	if line := input.Render(40)[0]; !strings.Contains(line, theme.Fg(style.ColorDim, "type here")) {
+		t.Fatalf("placeholder not themed: %q", line)
+	}
+
+	editor := NewEditor(&mockEditorTerm{w: 40, h: 24}, nil, WithEditorStyle(theme))
+	if line := editor.Render(40)[0]; !strings.HasPrefix(line, theme.FgANSI(style.ColorBorder)) {
+		t.Fatalf("unfocused editor border not themed: %q", line)
+	}
+	editor.SetFocused(true)
+	if line := editor.Render(40)[0]; !strings.HasPrefix(line, theme.FgANSI(style.ColorBorderAccent)) {
+		t.Fatalf("focused editor border should use borderAccent: %q", line)
+	}
+}
+
+func TestMarkdownHighlightsCodeBlocks(t *testing.T) {
+	theme, err := style.LoadTheme("dark", style.WithColorMode(style.ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := NewMarkdown("```go\nfunc main() { /* a\nb */ return \"x\" }\n```", 0, 0, WithMarkdownStyle(theme))
+	out := strings.Join(md.Render(80), "\n")
+	for _, want := range []string{
+		theme.Fg(style.ColorSyntaxKeyword, "func"),
+		theme.Fg(style.ColorSyntaxFunction, "main"),
+		theme.Fg(style.ColorSyntaxComment, "/* a"),
+		theme.Fg(style.ColorSyntaxComment, "b */"),
+		theme.Fg(style.ColorSyntaxString, `"x"`),
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %q", want, out)
+		}
+	}
+
+	lines := CodeHighlighter(theme)("x := 42 # not a comment", "golang")
+	if len(lines) != 1 || !strings.Contains(lines[0], theme.Fg(style.ColorSyntaxNumber, "42")) {
+		t.Fatalf("lines = %q", lines)
+	}
+	if got := CodeHighlighter(theme)("plain", "nonesuch"); got[0] != theme.Fg(style.ColorMdCodeBlock, "plain") {
+		t.Fatalf("unknown language = %q", got)
+	}
+}
+
+func TestSelectListThemeFrom(t *testing.T) {
+	theme, err := style.LoadTheme("dark", style.WithColorMode(style.ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []SelectItem{{Label: "alpha", Description: "first"}, {Label: "beta", Description: "second"}}
+	list := NewSelectList(items, 5, WithSelectListStyle(theme), WithSelectListHighlight("al"))
+	out := strings.Join(list.Render(80), "\n")
+	if !strings.Contains(out, theme.FgANSI(style.ColorMuted)) {
+		t.Errorf("description not muted: %q", out)
+	}
+	if !strings.Contains(out, theme.Bold(theme.Fg(style.ColorAccent, "al"))) {
+		t.Errorf("match not accented: %q", out)
+	}
+	lines := list.Render(80)
+	if !strings.HasPrefix(lines[0], theme.BgANSI(style.BgSelected)) || fasttui.VisibleWidth(lines[0]) != 80 {
+		t.Errorf("selected row should fill the width on selectedBg: %q", lines[0])
+	}
+	if strings.Contains(lines[1], theme.BgANSI(style.BgSelected)) {
+		t.Errorf("unselected row has selectedBg: %q", lines[1])
+	}
+}
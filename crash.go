@@ -0,0 +1,173 @@
+package fasttui
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yeeaiclub/fasttui/keys"
+)
+
+// maxRecentInputs bounds the input history kept for crash reports.
+const maxRecentInputs = 32
+
+// PanicError is returned by Run when a component panicked on the event loop.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the goroutine stack at the time of the panic.
+	Stack []byte
+	// CrashLogPath is where the crash report was written.
+	CrashLogPath string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("fasttui: panic on event loop: %v (crash report: %s)", e.Value, e.CrashLogPath)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// CrashReport is the state captured when the event loop recovers a panic.
+type CrashReport struct {
+	Time          time.Time
+	Panic         any
+	Stack         []byte
+	Width, Height int
+	Focused       string   // dynamic type of the focused component
+	LastFrame     []string // most recent rendered frame
+	RecentInputs  []string // oldest first, with typed text redacted, see WithCrashReportInput
+}
+
+// String formats the report for the crash log.
+func (r *CrashReport) String() string {
+	var b strings.Builder
+	b.WriteString("Crash at ")
+	b.WriteString(r.Time.Format(time.RFC3339))
+	b.WriteString("\nPanic: ")
+	b.WriteString(fmt.Sprint(r.Panic))
+	b.WriteString("\nTerminal size: ")
+	b.WriteString(strconv.Itoa(r.Width))
+	b.WriteString("x")
+	b.WriteString(strconv.Itoa(r.Height))
+	b.WriteString("\nFocused component: ")
+	b.WriteString(r.Focused)
+	b.WriteString("\n\n=== Recent input ===\n")
+	for idx, data := range r.RecentInputs {
+		b.WriteString("[")
+		b.WriteString(strconv.Itoa(idx))
+		b.WriteString("] ")
+		b.WriteString(data)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n=== Last frame ===\n")
+	for idx, l := range r.LastFrame {
+		b.WriteString("[")
+		b.WriteString(strconv.Itoa(idx))
+		b.WriteString("] (w=")
+		b.WriteString(strconv.Itoa(VisibleWidth(l)))
+		b.WriteString(") ")
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n=== Stack ===\n")
+	b.Write(r.Stack)
+	return b.String()
+}
+
+func (t *TUI) recordInput(data string) {
+	if !t.crashInput {
+		return
+	}
+	if len(t.recentInputs) == maxRecentInputs {
+		t.recentInputs = append(t.recentInputs[:0], t.recentInputs[1:]...)
+	}
+	t.recentInputs = append(t.recentInputs, describeInput(data))
+}
+
+// describeInput returns data as kept for crash reports: key names such as
+// "ctrl+c" and escape sequences as they are, but only the length of typed
+// text and pastes, which may be passwords or other secrets.
+func describeInput(data string) string {
+	if paste, ok := strings.CutPrefix(data, "\x1b[200~"); ok {
+		paste = strings.TrimSuffix(paste, "\x1b[201~")
+		return "<paste len=" + strconv.Itoa(utf8.RuneCountInString(paste)) + ">"
+	}
+	ev := keys.Decode(data)
+	if name := ev.String(); name != "" {
+		plain := ev.Modifiers&^(keys.ModShift|keys.ModLocks) == 0
+		if ev.Text == "" && !(plain && unicode.IsGraphic(rune(ev.Code))) {
+			return name
+		}
+	} else if strings.HasPrefix(data, "\x1b[") || strings.HasPrefix(data, "\x1b]") || strings.HasPrefix(data, "\x1bO") {
+		return strconv.Quote(data) // an unknown sequence, e.g. a mouse report
+	}
+	if ev.Text != "" || ev.Code > 0 {
+		return "<text len=" + strconv.Itoa(max(1, utf8.RuneCountInString(ev.Text))) + ">"
+	}
+	return "<input bytes=" + strconv.Itoa(len(data)) + ">"
+}
+
+// recoverPanic runs deferred on the event loop. It restores the terminal before
+// anything else so the shell is usable, writes the crash report and records the
+// error for Run. Unless Run is waiting for it, or WithRepanic says otherwise,
+// the error is printed and the original value re-panicked afterwards.
+func (t *TUI) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+
+	// A concurrent Stop is already waiting for the loop and restores the terminal itself
+	restore := t.stopped.CompareAndSwap(false, true)
+	if restore {
+		close(t.stopChan)
+		t.terminal.Stop()
+	}
+
+	report := &CrashReport{
+		Time:         time.Now(),
+		Panic:        r,
+		Stack:        stack,
+		LastFrame:    t.lastFrame,
+		RecentInputs: t.recentInputs,
+		Focused:      "<none>",
+	}
+	report.Width, report.Height = t.terminal.GetSize()
+	if t.focusedComponent != nil {
+		report.Focused = fmt.Sprintf("%T", t.focusedComponent)
+	}
+	path := GetCrashLogPath()
+	WriteCrashLog(path, report.String())
+
+	perr := &PanicError{Value: r, Stack: stack, CrashLogPath: path}
+	t.crashErr.Store(perr)
+
+	if restore {
+		if t.stopJobSignals != nil {
+			t.stopJobSignals()
+			t.stopJobSignals = nil
+		}
+		close(t.finished)
+	}
+
+	repanic := !t.running
+	if t.repanic != nil {
+		repanic = *t.repanic
+	}
+	if repanic {
+		fmt.Fprintln(os.Stderr, perr)
+		panic(r)
+	}
+}
@@ -0,0 +1,151 @@
+package fasttui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stopTrackingTerminal struct {
+	recordingTerminal
+	stopped atomic.Bool
+}
+
+func (s *stopTrackingTerminal) Stop() { s.stopped.Store(true) }
+
+type panickingComponent struct {
+	focused bool
+}
+
+func (p *panickingComponent) Render(width int) []string { return []string{"ok"} }
+func (p *panickingComponent) HandleInput(data string) {
+	if data == "boom" {
+		panic("component exploded")
+	}
+}
+func (p *panickingComponent) WantsKeyRelease() bool { return false }
+func (p *panickingComponent) Invalidate()           {}
+
+// TestRunRecoversPanicAndWritesCrashReport verifies that a panic in a component is
+// recovered, the terminal restored, and the error surfaced from Run.
+func TestRunRecoversPanicAndWritesCrashReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	term := &stopTrackingTerminal{}
+	tui := NewTUI(term, false)
+	comp := &panickingComponent{}
+	tui.AddChild(comp)
+	tui.SetFocus(comp)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- tui.Run() }()
+
+	time.Sleep(15 * time.Millisecond)
+	tui.HandleInput("a")
+	tui.HandleInput("boom")
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after the panic")
+	}
+
+	var perr *PanicError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected *PanicError, got %v", err)
+	}
+	if perr.Value != "component exploded" {
+		t.Fatalf("unexpected panic value %v", perr.Value)
+	}
+	if !term.stopped.Load() {
+		t.Fatal("terminal should be restored after a panic")
+	}
+
+	data, readErr := os.ReadFile(perr.CrashLogPath)
+	if readErr != nil {
+		t.Fatalf("crash report not written: %v", readErr)
+	}
+	report := string(data)
+	for _, want := range []string{
+		"Panic: component exploded",
+		"Terminal size: 80x24",
+		"Focused component: *fasttui.panickingComponent",
+		"[0] <text len=1>",
+		"[1] <text len=4>",
+		"(w=2) ok",
+		"goroutine",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("crash report missing %q:\n%s", want, report)
+		}
+	}
+
+	if strings.Contains(report, "boom") {
+		t.Errorf("crash report contains typed text:\n%s", report)
+	}
+
+	// Stop after a crash must not block or stop the terminal twice
+	tui.Stop()
+}
+
+func TestDescribeInputRedactsText(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"x", "<text len=1>"},
+		{"hunter2", "<text len=7>"},
+		{"\x1b[104;1;104u", "<text len=1>"}, // Kitty, report all keys with text
+		{"\x1b[104u", "<text len=1>"},       // Kitty, report all keys
+		{"\x1b[200~secret token\x1b[201~", "<paste len=12>"},
+		{"\x03", "ctrl+c"},
+		{"\r", "enter"},
+		{"\x1b[A", "up"},
+		{"\x1b[<0;10;5M", `"\x1b[<0;10;5M"`},
+	}
+	for _, tt := range tests {
+		if got := describeInput(tt.data); got != tt.want {
+			t.Errorf("describeInput(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+
+	tui := NewTUI(&recordingTerminal{}, false, WithCrashReportInput(false))
+	tui.recordInput("\x03")
+	if len(tui.recentInputs) != 0 {
+		t.Errorf("inputs kept with WithCrashReportInput(false): %q", tui.recentInputs)
+	}
+}
+
+// TestStartRepanicsByDefault checks that a TUI started with Start still
+// crashes the program on a panic, after restoring the terminal and pointing
+// to the crash report.
+func TestStartRepanicsByDefault(t *testing.T) {
+	if os.Getenv("FASTTUI_PANIC_CHILD") == "1" {
+		tui := NewTUI(&recordingTerminal{}, false)
+		comp := &panickingComponent{}
+		tui.AddChild(comp)
+		tui.SetFocus(comp)
+		tui.Start()
+		tui.HandleInput("boom")
+		time.Sleep(2 * time.Second)
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStartRepanicsByDefault$")
+	cmd.Env = append(os.Environ(), "FASTTUI_PANIC_CHILD=1", "HOME="+t.TempDir())
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("child should crash, got %v:\n%s", err, out)
+	}
+	for _, want := range []string{"crash report: ", "panic: component exploded"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
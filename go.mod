@@ -7,10 +7,10 @@ require (
 	github.com/mattn/go-runewidth v0.0.24
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/sys v0.47.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
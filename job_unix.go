@@ -0,0 +1,45 @@
+//go:build !windows
+
+package fasttui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchJobSignals routes an external SIGTSTP (e.g. `kill -TSTP`) through Suspend so
+// the shell never gets back a terminal in raw mode, and redraws on a stray SIGCONT.
+// Returns a function that stops watching.
+func (t *TUI) watchJobSignals() func() {
+	t.jobSignals = make(chan os.Signal, 1)
+	signal.Notify(t.jobSignals, syscall.SIGTSTP, syscall.SIGCONT)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-t.jobSignals:
+				if sig == syscall.SIGTSTP {
+					_ = t.Suspend()
+				} else if !t.suspended.Load() {
+					t.ForceRender()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(t.jobSignals)
+		close(done)
+	}
+}
+
+// stopProcess stops the process with SIGSTOP. SIGTSTP would not do: once
+// signal.Notify has caught it, Go never restores the default disposition, so
+// the signal is ignored. Kill returns once the process has been continued.
+func (t *TUI) stopProcess() error {
+	return syscall.Kill(syscall.Getpid(), syscall.SIGSTOP)
+}
@@ -0,0 +1,85 @@
+//go:build !windows
+
+package fasttui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestSuspendStopsProcess runs a TUI in a child process, sends it SIGTSTP as
+// a shell would for ctrl+z, and checks that the child really stops and then
+// resumes and repaints on SIGCONT.
+func TestSuspendStopsProcess(t *testing.T) {
+	if os.Getenv("FASTTUI_SUSPEND_CHILD") == "1" {
+		runSuspendChild()
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSuspendStopsProcess$")
+	cmd.Env = append(os.Environ(), "FASTTUI_SUSPEND_CHILD=1")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := cmd.Process.Pid
+	defer syscall.Kill(pid, syscall.SIGKILL)
+
+	status := waitChild(t, pid, syscall.WUNTRACED)
+	if !status.Stopped() || status.StopSignal() != syscall.SIGSTOP {
+		t.Fatalf("child did not stop: status %#x", status)
+	}
+	if err := syscall.Kill(pid, syscall.SIGCONT); err != nil {
+		t.Fatal(err)
+	}
+	if status := waitChild(t, pid, 0); !status.Exited() || status.ExitStatus() != 0 {
+		t.Fatalf("child did not resume cleanly: status %#x", status)
+	}
+}
+
+func waitChild(t *testing.T, pid, options int) syscall.WaitStatus {
+	t.Helper()
+	type result struct {
+		status syscall.WaitStatus
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		_, r.err = syscall.Wait4(pid, &r.status, options, nil)
+		done <- r
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		return r.status
+	case <-time.After(5 * time.Second):
+		syscall.Kill(pid, syscall.SIGKILL)
+		t.Fatal("timed out waiting for the child")
+	}
+	return 0
+}
+
+// runSuspendChild suspends itself through SIGTSTP and exits 0 once the
+// terminal has been resumed.
+func runSuspendChild() {
+	term := &suspendableTerminal{}
+	tui := NewTUI(term, false)
+	tui.Start()
+	defer tui.Stop()
+	time.Sleep(15 * time.Millisecond)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGTSTP)
+	for range 500 {
+		if strings.Contains(term.String(), "<resume>") {
+			os.Exit(0)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package fasttui
+
+// watchJobSignals is a no-op: Windows consoles have no job control signals.
+func (t *TUI) watchJobSignals() func() {
+	return func() {}
+}
+
+func (t *TUI) stopProcess() error {
+	return ErrSuspendUnsupported
+}
@@ -0,0 +1,595 @@
+package keys
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modifiers is the Kitty keyboard protocol modifier bit set, i.e. the
+// modifier parameter of a key sequence minus one.
+type Modifiers int
+
+const (
+	ModShift Modifiers = 1 << iota
+	ModAlt
+	ModCtrl
+	ModSuper
+	ModHyper
+	ModMeta
+	ModCapsLock
+	ModNumLock
+)
+
+// ModLocks masks the lock state bits, which are not part of a key id.
+const ModLocks = ModCapsLock | ModNumLock
+
+var modifierNames = []struct {
+	mod  Modifiers
+	name string
+}{
+	{ModCtrl, "ctrl"},
+	{ModAlt, "alt"},
+	{ModShift, "shift"},
+	{ModSuper, "super"},
+	{ModHyper, "hyper"},
+	{ModMeta, "meta"},
+}
+
+// Has reports whether every bit of mod is set.
+func (m Modifiers) Has(mod Modifiers) bool {
+	return m&mod == mod
+}
+
+// KeyCode identifies a key. Text keys use the Unicode codepoint of the
+// unshifted key ('a', '1', ';'); other keys use the constants below, which
+// share values with the Codepoint* constants used by ParseKittySequence.
+type KeyCode int
+
+const (
+	KeyUnknown   KeyCode = 0
+	KeyTab       KeyCode = CodepointTab
+	KeyEnter     KeyCode = CodepointEnter
+	KeyEscape    KeyCode = CodepointEscape
+	KeySpace     KeyCode = CodepointSpace
+	KeyBackspace KeyCode = CodepointBackspace
+
+	KeyUp    KeyCode = ArrowCodepointUp
+	KeyDown  KeyCode = ArrowCodepointDown
+	KeyRight KeyCode = ArrowCodepointRight
+	KeyLeft  KeyCode = ArrowCodepointLeft
+
+	KeyDelete   KeyCode = FunctionalCodepointDelete
+	KeyInsert   KeyCode = FunctionalCodepointInsert
+	KeyPageUp   KeyCode = FunctionalCodepointPageUp
+	KeyPageDown KeyCode = FunctionalCodepointPageDown
+	KeyHome     KeyCode = FunctionalCodepointHome
+	KeyEnd      KeyCode = FunctionalCodepointEnd
+	KeyClear    KeyCode = -16
+
+	KeyF1  KeyCode = -21
+	KeyF12 KeyCode = KeyF1 - 11
+)
+
+var keyCodeNames = map[KeyCode]string{
+	KeyTab: "tab", KeyEnter: "enter", KeyEscape: "escape", KeySpace: "space",
+	KeyBackspace: "backspace", KeyUp: "up", KeyDown: "down", KeyRight: "right",
+	KeyLeft: "left", KeyDelete: "delete", KeyInsert: "insert", KeyPageUp: "pageup",
+	KeyPageDown: "pagedown", KeyHome: "home", KeyEnd: "end", KeyClear: "clear",
+}
+
+// Name returns the key id name of the code without modifiers, e.g. "pageup",
+// "f5" or "a".
+func (c KeyCode) Name() string {
+	if name, ok := keyCodeNames[c]; ok {
+		return name
+	}
+	if c <= KeyF1 && c >= KeyF12 {
+		return "f" + strconv.Itoa(int(KeyF1-c)+1)
+	}
+	if c > 0 {
+		return string(rune(c))
+	}
+	return ""
+}
+
+func keyCodeFromName(name string) (KeyCode, bool) {
+	for code, n := range keyCodeNames {
+		if n == name {
+			return code, true
+		}
+	}
+	if len(name) >= 2 && name[0] == 'f' {
+		if n, err := strconv.Atoi(name[1:]); err == nil && n >= 1 && n <= 12 {
+			return KeyF1 - KeyCode(n-1), true
+		}
+	}
+	if r, size := utf8.DecodeRuneInString(name); size == len(name) && r != utf8.RuneError {
+		return KeyCode(r), true
+	}
+	return KeyUnknown, false
+}
+
+// KeyEvent is a decoded key press, repeat or release.
+type KeyEvent struct {
+	Code      KeyCode
+	Modifiers Modifiers
+	Type      KeyEventType
+	// Text is the text the key produces, "" for keys that produce none.
+	// For input that is not a single key, such as pasted text, Code is
+	// KeyUnknown and Text holds the input.
+	Text string
+	// ShiftedKey and BaseLayoutKey are the Kitty alternate keys: the key with
+	// shift applied and the key at the same position in the US layout.
+	// Zero when unknown.
+	ShiftedKey    rune
+	BaseLayoutKey rune
+}
+
+// String returns the key id, e.g. "ctrl+shift+a", in the form MatchesKey and
+// keybinding configs use. Lock modifiers are omitted.
+func (e KeyEvent) String() string {
+	name := e.Code.Name()
+	if name == "" {
+		return ""
+	}
+	var parts []string
+	for _, m := range modifierNames {
+		if e.Modifiers.Has(m.mod) {
+			parts = append(parts, m.name)
+		}
+	}
+	return strings.Join(append(parts, name), "+")
+}
+
+// Matches reports whether the event is the key described by keyID, comparing
+// modifiers exactly apart from lock state.
+func (e KeyEvent) Matches(keyID string) bool {
+	parts := strings.Split(strings.ToLower(keyID), "+")
+	code, ok := keyCodeFromName(parts[len(parts)-1])
+	if !ok {
+		return false
+	}
+	var mods Modifiers
+	for _, part := range parts[:len(parts)-1] {
+		found := false
+		for _, m := range modifierNames {
+			if m.name == part {
+				mods |= m.mod
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if e.Code == code && e.Modifiers&^ModLocks == mods {
+		return true
+	}
+	return e.BaseLayoutKey != 0 && KeyCode(e.BaseLayoutKey) == code && e.Modifiers&^ModLocks == mods
+}
+
+// Decode parses one input chunk: legacy control bytes and escape sequences,
+// xterm modifyOtherKeys, and the Kitty keyboard protocol.
+func Decode(data string) KeyEvent {
+	ev := KeyEvent{Type: KeyPress}
+	switch {
+	case data == "":
+		return ev
+	case strings.HasPrefix(data, "\x1b[") && len(data) > 2:
+		if decodeCSI(data[2:], &ev) {
+			return ev
+		}
+	case strings.HasPrefix(data, "\x1bO") && len(data) == 3:
+		if decodeSS3(data[2], &ev) {
+			return ev
+		}
+	case len(data) >= 2 && data[0] == '\x1b':
+		if kittyProtocolActive && data == "\x1b\r" {
+			return KeyEvent{Code: KeyEnter, Modifiers: ModShift, Type: KeyPress}
+		}
+		inner := Decode(data[1:])
+		if inner.Code != KeyUnknown && !inner.Modifiers.Has(ModAlt) {
+			inner.Modifiers |= ModAlt
+			inner.Text = ""
+			return inner
+		}
+	default:
+		if decodeSingle(data, &ev) {
+			return ev
+		}
+	}
+	return KeyEvent{Type: KeyPress, Text: data}
+}
+
+func decodeSingle(data string, ev *KeyEvent) bool {
+	r, size := utf8.DecodeRuneInString(data)
+	if size != len(data) || r == utf8.RuneError {
+		return false
+	}
+	switch {
+	case r == '\t':
+		ev.Code = KeyTab
+	case r == '\r':
+		ev.Code = KeyEnter
+	case r == '\n':
+		ev.Code = KeyEnter
+		if kittyProtocolActive {
+			ev.Modifiers = ModShift
+		}
+	case r == 0x1b:
+		ev.Code = KeyEscape
+	case r == 0x7f || r == 0x08:
+		ev.Code = KeyBackspace
+	case r == 0:
+		ev.Code, ev.Modifiers = KeySpace, ModCtrl
+	case r >= 1 && r <= 26:
+		ev.Code, ev.Modifiers = KeyCode('a'+r-1), ModCtrl
+	case r >= 0x1c && r <= 0x1f:
+		ev.Code, ev.Modifiers = KeyCode([]rune{'\\', ']', '^', '-'}[r-0x1c]), ModCtrl
+	case r == ' ':
+		ev.Code, ev.Text = KeySpace, " "
+	case unicode.IsPrint(r):
+		ev.Text = data
+		lower := unicode.ToLower(r)
+		ev.Code = KeyCode(lower)
+		if lower != r {
+			ev.Modifiers = ModShift
+			ev.ShiftedKey = r
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func decodeSS3(final byte, ev *KeyEvent) bool {
+	switch final {
+	case 'A', 'B', 'C', 'D', 'H', 'F', 'E':
+		ev.Code = cursorKeyCodes[final]
+	case 'a', 'b', 'c', 'd', 'e':
+		ev.Code, ev.Modifiers = cursorKeyCodes[final-'a'+'A'], ModCtrl
+	case 'P', 'Q', 'R', 'S':
+		ev.Code = KeyF1 - KeyCode(final-'P')
+	case 'M':
+		ev.Code = KeyEnter
+	default:
+		return false
+	}
+	return true
+}
+
+var cursorKeyCodes = map[byte]KeyCode{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd, 'E': KeyClear,
+}
+
+// tildeKeyCodes maps the number of a "CSI n ~" sequence to its key.
+var tildeKeyCodes = map[int]KeyCode{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPageUp, 6: KeyPageDown,
+	7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF1 - 1, 13: KeyF1 - 2, 14: KeyF1 - 3, 15: KeyF1 - 4,
+	17: KeyF1 - 5, 18: KeyF1 - 6, 19: KeyF1 - 7, 20: KeyF1 - 8, 21: KeyF1 - 9,
+	23: KeyF1 - 10, 24: KeyF1 - 11,
+}
+
+// decodeCSI parses the part of a CSI sequence after "ESC [".
+func decodeCSI(seq string, ev *KeyEvent) bool {
+	final := seq[len(seq)-1]
+	body := seq[:len(seq)-1]
+
+	// Linux console F1-F5: ESC [ [ A..E
+	if strings.HasPrefix(body, "[") {
+		if body == "[" && final >= 'A' && final <= 'E' {
+			ev.Code = KeyF1 - KeyCode(final-'A')
+			return true
+		}
+		body = body[1:]
+	}
+	if strings.Trim(body, "0123456789;:") != "" {
+		return false
+	}
+	params := strings.Split(body, ";")
+	field := func(i, j int) int {
+		if i >= len(params) {
+			return 0
+		}
+		sub := strings.Split(params[i], ":")
+		if j >= len(sub) {
+			return 0
+		}
+		n, _ := strconv.Atoi(sub[j])
+		return n
+	}
+	setModifiers := func(i int) {
+		if mod := field(i, 0); mod > 0 {
+			ev.Modifiers = Modifiers(mod - 1)
+		}
+		ev.Type = parseEventType(strconv.Itoa(field(i, 1)))
+	}
+
+	switch {
+	case final == 'u':
+		code := field(0, 0)
+		ev.Code = KeyCode(code)
+		ev.ShiftedKey = rune(field(0, 1))
+		ev.BaseLayoutKey = rune(field(0, 2))
+		if code == CodepointKPEnter {
+			ev.Code = KeyEnter
+		}
+		setModifiers(1)
+		if len(params) > 2 {
+			for _, cp := range strings.Split(params[2], ":") {
+				if n, err := strconv.Atoi(cp); err == nil {
+					ev.Text += string(rune(n))
+				}
+			}
+		} else if ev.Modifiers&^(ModShift|ModLocks) == 0 && isTextCode(ev.Code) {
+			r := rune(ev.Code)
+			if ev.Modifiers.Has(ModShift) && ev.ShiftedKey != 0 {
+				r = ev.ShiftedKey
+			}
+			ev.Text = string(r)
+		}
+	case final == '~':
+		n := field(0, 0)
+		if n == 27 && len(params) == 3 {
+			// xterm modifyOtherKeys: CSI 27 ; mod ; code ~
+			ev.Code = KeyCode(unicode.ToLower(rune(field(2, 0))))
+			setModifiers(1)
+			return true
+		}
+		code, ok := tildeKeyCodes[n]
+		if !ok {
+			return false
+		}
+		ev.Code = code
+		setModifiers(1)
+	case final == '$' || final == '^':
+		// rxvt: shift ($) and ctrl (^) variants of CSI n ~
+		code, ok := tildeKeyCodes[field(0, 0)]
+		if !ok {
+			return false
+		}
+		ev.Code = code
+		ev.Modifiers = ModShift
+		if final == '^' {
+			ev.Modifiers = ModCtrl
+		}
+	case final == 'Z':
+		ev.Code, ev.Modifiers = KeyTab, ModShift
+	case final >= 'a' && final <= 'e' && body == "":
+		ev.Code, ev.Modifiers = cursorKeyCodes[final-'a'+'A'], ModShift
+	case cursorKeyCodes[final] != 0:
+		ev.Code = cursorKeyCodes[final]
+		setModifiers(1)
+	case final >= 'P' && final <= 'S':
+		ev.Code = KeyF1 - KeyCode(final-'P')
+		setModifiers(1)
+	default:
+		return false
+	}
+	return true
+}
+
+// isTextCode reports whether code is a key that types its own codepoint. Kitty
+// reports keys without text, such as keypad and media keys, in the Unicode
+// private use area.
+func isTextCode(code KeyCode) bool {
+	return code >= KeySpace && code != KeyBackspace && (code < 0xe000 || code > 0xf8ff)
+}
+
+// KeyEncoding selects the byte format produced by Encode.
+type KeyEncoding int
+
+const (
+	// EncodingLegacy produces classic control bytes and VT escape sequences.
+	// Combinations without a legacy form lose modifiers.
+	EncodingLegacy KeyEncoding = iota
+	// EncodingModifyOtherKeys is xterm modifyOtherKeys mode 2: modified keys
+	// without a legacy form are sent as CSI 27 ; mod ; code ~.
+	EncodingModifyOtherKeys
+	// EncodingKitty is the Kitty keyboard protocol with disambiguation, event
+	// types and alternate keys enabled.
+	EncodingKitty
+)
+
+// Encode produces the bytes a terminal sends for ev. It returns "" for events
+// the encoding cannot express, such as key releases outside Kitty mode.
+func Encode(ev KeyEvent, encoding KeyEncoding) string {
+	if ev.Code == KeyUnknown {
+		return ev.Text
+	}
+	if ev.Type == "" {
+		ev.Type = KeyPress
+	}
+	mods := ev.Modifiers &^ ModLocks
+	if encoding == EncodingKitty {
+		return encodeKitty(ev, mods)
+	}
+	if ev.Type == KeyRelease {
+		return ""
+	}
+
+	if seq, ok := encodeFunctional(ev.Code, mods, ""); ok {
+		return seq
+	}
+	if encoding == EncodingModifyOtherKeys && needsModifyOtherKeys(ev.Code, mods) {
+		return "\x1b[27;" + strconv.Itoa(int(mods)+1) + ";" + strconv.Itoa(int(ev.Code)) + "~"
+	}
+	return encodeLegacy(ev, mods)
+}
+
+// needsModifyOtherKeys reports whether a modified key would be ambiguous or
+// lossy in the legacy encoding.
+func needsModifyOtherKeys(code KeyCode, mods Modifiers) bool {
+	switch {
+	case mods == 0, mods == ModShift && code != KeyEnter && code != KeyBackspace && code != KeySpace:
+		return false
+	case mods == ModCtrl && code >= 'a' && code <= 'z':
+		return false
+	case mods == ModAlt:
+		return false
+	}
+	return true
+}
+
+func encodeLegacy(ev KeyEvent, mods Modifiers) string {
+	prefix := ""
+	if mods.Has(ModAlt) {
+		prefix = "\x1b"
+		mods &^= ModAlt
+	}
+	switch ev.Code {
+	case KeyEnter:
+		return prefix + "\r"
+	case KeyTab:
+		if mods.Has(ModShift) {
+			return prefix + "\x1b[Z"
+		}
+		return prefix + "\t"
+	case KeyEscape:
+		return prefix + "\x1b"
+	case KeyBackspace:
+		if mods.Has(ModCtrl) {
+			return prefix + "\x08"
+		}
+		return prefix + "\x7f"
+	case KeySpace:
+		if mods.Has(ModCtrl) {
+			return prefix + "\x00"
+		}
+		return prefix + " "
+	}
+
+	r := rune(ev.Code)
+	if mods.Has(ModCtrl) {
+		if ctrl := rawCtrlChar(string(r)); ctrl != "" {
+			return prefix + ctrl
+		}
+	}
+	if ev.Text != "" {
+		return prefix + ev.Text
+	}
+	if mods.Has(ModShift) {
+		if ev.ShiftedKey != 0 {
+			r = ev.ShiftedKey
+		} else {
+			r = unicode.ToUpper(r)
+		}
+	}
+	return prefix + string(r)
+}
+
+// encodeFunctional encodes cursor, editing and function keys in the xterm
+// "CSI 1 ; mod X" / "CSI n ; mod ~" form. event is a Kitty ":type" suffix.
+func encodeFunctional(code KeyCode, mods Modifiers, event string) (string, bool) {
+	modParam := ""
+	if mods != 0 || event != "" {
+		modParam = strconv.Itoa(int(mods)+1) + event
+	}
+	letter := func(final string) string {
+		if modParam == "" {
+			return "\x1b[" + final
+		}
+		return "\x1b[1;" + modParam + final
+	}
+	tilde := func(n int) string {
+		if modParam == "" {
+			return "\x1b[" + strconv.Itoa(n) + "~"
+		}
+		return "\x1b[" + strconv.Itoa(n) + ";" + modParam + "~"
+	}
+
+	switch code {
+	case KeyUp:
+		return letter("A"), true
+	case KeyDown:
+		return letter("B"), true
+	case KeyRight:
+		return letter("C"), true
+	case KeyLeft:
+		return letter("D"), true
+	case KeyHome:
+		return letter("H"), true
+	case KeyEnd:
+		return letter("F"), true
+	case KeyClear:
+		return letter("E"), true
+	case KeyInsert:
+		return tilde(2), true
+	case KeyDelete:
+		return tilde(3), true
+	case KeyPageUp:
+		return tilde(5), true
+	case KeyPageDown:
+		return tilde(6), true
+	}
+	if code <= KeyF1 && code >= KeyF12 {
+		n := int(KeyF1 - code)
+		if n < 4 && n != 2 {
+			if modParam == "" {
+				return "\x1bO" + string(rune('P'+n)), true
+			}
+			return letter(string(rune('P' + n))), true
+		}
+		for num, c := range tildeKeyCodes {
+			if c == code && num >= 11 {
+				return tilde(num), true
+			}
+		}
+	}
+	return "", false
+}
+
+func encodeKitty(ev KeyEvent, mods Modifiers) string {
+	event := ""
+	switch ev.Type {
+	case KeyRepeat:
+		event = ":2"
+	case KeyRelease:
+		event = ":3"
+	}
+	if seq, ok := encodeFunctional(ev.Code, ev.Modifiers, event); ok {
+		return seq
+	}
+
+	// With only disambiguation, unmodified text keys and enter, tab and
+	// backspace keep their legacy bytes on press.
+	if ev.Type != KeyRelease && mods&^ModShift == 0 {
+		switch {
+		case ev.Code == KeyEnter && mods == 0:
+			return "\r"
+		case ev.Code == KeyTab && mods == 0:
+			return "\t"
+		case ev.Code == KeyBackspace && mods == 0:
+			return "\x7f"
+		case isTextCode(ev.Code) && ev.Type == KeyPress:
+			if ev.Text != "" {
+				return ev.Text
+			}
+			if mods == 0 {
+				return string(rune(ev.Code))
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[")
+	b.WriteString(strconv.Itoa(int(ev.Code)))
+	if ev.ShiftedKey != 0 || ev.BaseLayoutKey != 0 {
+		b.WriteString(":")
+		if ev.ShiftedKey != 0 {
+			b.WriteString(strconv.Itoa(int(ev.ShiftedKey)))
+		}
+		if ev.BaseLayoutKey != 0 {
+			b.WriteString(":" + strconv.Itoa(int(ev.BaseLayoutKey)))
+		}
+	}
+	if ev.Modifiers != 0 || event != "" {
+		b.WriteString(";" + strconv.Itoa(int(ev.Modifiers)+1) + event)
+	}
+	b.WriteString("u")
+	return b.String()
+}
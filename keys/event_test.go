@@ -0,0 +1,88 @@
+package keys
+
+import "testing"
+
+func TestDecodeKeyEvent(t *testing.T) {
+	tests := []struct {
+		data string
+		id   string
+		typ  KeyEventType
+		text string
+	}{
+		{"a", "a", KeyPress, "a"},
+		{"A", "shift+a", KeyPress, "A"},
+		{"\x01", "ctrl+a", KeyPress, ""},
+		{"\x1bb", "alt+b", KeyPress, ""},
+		{"\x1b[1;3D", "alt+left", KeyPress, ""},
+		{"\x1b[Z", "shift+tab", KeyPress, ""},
+		{"\x1b[5;5~", "ctrl+pageup", KeyPress, ""},
+		{"\x1bOQ", "f2", KeyPress, ""},
+		{"\x1b[27;5;13~", "ctrl+enter", KeyPress, ""},
+		{"\x1b[97;5u", "ctrl+a", KeyPress, ""},
+		{"\x1b[97;9u", "super+a", KeyPress, ""},
+		{"\x1b[97;5:3u", "ctrl+a", KeyRelease, ""},
+		{"\x1b[97:65;2u", "shift+a", KeyPress, "A"},
+		{"\x1b[97;65u", "a", KeyPress, "a"},
+		{"\x1b[1;1:2A", "up", KeyRepeat, ""},
+	}
+	for _, tt := range tests {
+		ev := Decode(tt.data)
+		if ev.String() != tt.id || ev.Type != tt.typ || ev.Text != tt.text {
+			t.Errorf("Decode(%q) = %q %s text %q, want %q %s text %q", tt.data, ev.String(), ev.Type, ev.Text, tt.id, tt.typ, tt.text)
+		}
+		if !ev.Matches(tt.id) {
+			t.Errorf("Decode(%q) should match %q", tt.data, tt.id)
+		}
+	}
+
+	if ev := Decode("\x1b[97;69u"); !ev.Modifiers.Has(ModCapsLock|ModCtrl) || ev.String() != "ctrl+a" {
+		t.Errorf("caps lock should be kept but left out of the key id, got %+v", ev)
+	}
+	if ev := Decode("\x1b[1089::99;5u"); !ev.Matches("ctrl+c") {
+		t.Errorf("base layout key should match ctrl+c, got %+v", ev)
+	}
+	if ev := Decode("hello"); ev.Code != KeyUnknown || ev.Text != "hello" {
+		t.Errorf("multi-character input should decode as text, got %+v", ev)
+	}
+}
+
+func TestEncodeKeyEventRoundTrip(t *testing.T) {
+	events := []KeyEvent{
+		{Code: 'a', Text: "a"},
+		{Code: 'a', Modifiers: ModCtrl},
+		{Code: 'x', Modifiers: ModAlt},
+		{Code: KeyEnter},
+		{Code: KeyTab, Modifiers: ModShift},
+		{Code: KeyLeft, Modifiers: ModCtrl | ModShift},
+		{Code: KeyDelete},
+		{Code: KeyF1 - 4, Modifiers: ModAlt},
+		{Code: KeyF1 - 2},
+	}
+	for _, enc := range []KeyEncoding{EncodingLegacy, EncodingModifyOtherKeys, EncodingKitty} {
+		for _, ev := range events {
+			ev.Type = KeyPress
+			data := Encode(ev, enc)
+			if got := Decode(data); got.String() != ev.String() || got.Text != ev.Text {
+				t.Errorf("encoding %d: %s -> %q -> %s", enc, ev, data, got)
+			}
+		}
+	}
+
+	// Combinations without a legacy form need modifyOtherKeys or Kitty.
+	ctrlEnter := KeyEvent{Code: KeyEnter, Modifiers: ModCtrl}
+	if got := Encode(ctrlEnter, EncodingModifyOtherKeys); got != "\x1b[27;5;13~" {
+		t.Errorf("modifyOtherKeys ctrl+enter = %q", got)
+	}
+	if got := Encode(ctrlEnter, EncodingKitty); got != "\x1b[13;5u" {
+		t.Errorf("kitty ctrl+enter = %q", got)
+	}
+
+	release := KeyEvent{Code: 'a', Modifiers: ModCtrl | ModSuper, Type: KeyRelease}
+	if got := Encode(release, EncodingLegacy); got != "" {
+		t.Errorf("legacy cannot encode a release, got %q", got)
+	}
+	data := Encode(release, EncodingKitty)
+	if got := Decode(data); got.String() != "ctrl+super+a" || got.Type != KeyRelease {
+		t.Errorf("kitty release %q decoded as %+v", data, got)
+	}
+}
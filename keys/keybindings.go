@@ -1,5 +1,11 @@
 package keys
 
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
 type EditorAction string
 
 const (
@@ -40,6 +46,8 @@ const (
 	EditorActionRenameSession            EditorAction = "renameSession"
 	EditorActionDeleteSession            EditorAction = "deleteSession"
 	EditorActionDeleteSessionNoninvasive EditorAction = "deleteSessionNoninvasive"
+	EditorActionSuspend                  EditorAction = "suspend"
+	EditorActionExternalEditor           EditorAction = "externalEditor"
 )
 
 type EditorKeybindingsConfig map[EditorAction][]string
@@ -82,15 +90,28 @@ var defaultEditorKeybindings = map[EditorAction][]string{
 	EditorActionRenameSession:            {"ctrl+r"},
 	EditorActionDeleteSession:            {"ctrl+d"},
 	EditorActionDeleteSessionNoninvasive: {"ctrl+backspace"},
+	EditorActionSuspend:                  {"ctrl+z"},
+	EditorActionExternalEditor:           {"ctrl+g"},
 }
 
 type EditorKeybindingsManager struct {
 	actionToKeys map[EditorAction][]string
+
+	// Key sequence state, see Feed.
+	seqMu         sync.Mutex
+	seqPending    []string
+	seqTimeout    time.Duration
+	seqTimer      *time.Timer
+	seqGeneration int
+	seqIndicator  func(pending string)
+	seqOnTimeout  func(keys []string)
+	seqAbandoned  []string
 }
 
 func NewEditorKeybindingsManager(config EditorKeybindingsConfig) *EditorKeybindingsManager {
 	mgr := &EditorKeybindingsManager{
 		actionToKeys: make(map[EditorAction][]string),
+		seqTimeout:   DefaultSequenceTimeout,
 	}
 	mgr.buildMaps(config)
 	return mgr
@@ -118,6 +139,10 @@ func (m *EditorKeybindingsManager) Matches(data string, action EditorAction) boo
 		return false
 	}
 	for _, key := range keys {
+		// Sequences only match through Feed.
+		if strings.Contains(key, " ") {
+			continue
+		}
 		if MatchesKey(data, key) {
 			return true
 		}
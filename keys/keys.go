@@ -11,7 +11,7 @@ var (
 	kittyProtocolActive              = false
 	lastEventType       KeyEventType = "press"
 
-	csiURegex        = regexp.MustCompile(`^\x1b\[(\d+)(?::(\d*))?(?::(\d+))?(?:;(\d+))?(?::(\d+))?u$`)
+	csiURegex        = regexp.MustCompile(`^\x1b\[(\d+)(?::(\d*))?(?::(\d+))?(?:;(\d*))?(?::(\d+))?(?:;[\d:]*)?u$`)
 	arrowRegex       = regexp.MustCompile(`^\x1b\[1;(\d+)(?::(\d+))?([ABCD])$`)
 	funcRegex        = regexp.MustCompile(`^\x1b\[(\d+)(?:;(\d+))?(?::(\d+))?~$`)
 	homeEndRegex     = regexp.MustCompile(`^\x1b\[1;(\d+)(?::(\d+))?([HF])$`)
@@ -329,8 +329,26 @@ func ParseKittySequence(data string) *ParsedKittySequence {
 	return nil
 }
 
+// parseModifiedKey parses a Kitty sequence, or an xterm modifyOtherKeys
+// sequence, which carries the same codepoint and modifier.
+func parseModifiedKey(data string) *ParsedKittySequence {
+	if parsed := ParseKittySequence(data); parsed != nil {
+		return parsed
+	}
+	match := modifyOtherRegex.FindStringSubmatch(data)
+	if match == nil {
+		return nil
+	}
+	modValue, _ := strconv.Atoi(match[1])
+	keycode, _ := strconv.Atoi(match[2])
+	if keycode >= 'A' && keycode <= 'Z' {
+		keycode += 'a' - 'A'
+	}
+	return &ParsedKittySequence{Codepoint: keycode, Modifier: modValue - 1, EventType: KeyPress}
+}
+
 func matchesKittySequence(data string, expectedCodepoint int, expectedModifier int) bool {
-	parsed := ParseKittySequence(data)
+	parsed := parseModifiedKey(data)
 	if parsed == nil {
 		return false
 	}
@@ -760,7 +778,7 @@ func MatchesKey(data string, keyId string) bool {
 }
 
 func ParseKey(data string) string {
-	kitty := ParseKittySequence(data)
+	kitty := parseModifiedKey(data)
 	if kitty != nil {
 		var mods []string
 		effectiveMod := kitty.Modifier & ^LockMask
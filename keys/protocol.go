@@ -0,0 +1,46 @@
+package keys
+
+import "strings"
+
+// KittyFlags are the Kitty keyboard protocol progressive enhancement flags
+// pushed with CSI > flags u.
+type KittyFlags int
+
+const (
+	// KittyDisambiguate sends modified and ambiguous keys as CSI u sequences.
+	KittyDisambiguate KittyFlags = 1 << iota
+	// KittyReportEventTypes adds repeat and release events.
+	KittyReportEventTypes
+	// KittyReportAlternateKeys adds the shifted and base layout keys.
+	KittyReportAlternateKeys
+	// KittyReportAllKeys sends every key, including plain text, as a sequence.
+	KittyReportAllKeys
+	// KittyReportText adds the typed text to sequences from KittyReportAllKeys.
+	KittyReportText
+)
+
+// DefaultKittyFlags disambiguates keys and reports event types and alternate
+// keys, so shortcuts work with non-Latin keyboard layouts.
+const DefaultKittyFlags = KittyDisambiguate | KittyReportEventTypes | KittyReportAlternateKeys
+
+// KeyboardProtocol describes the keyboard encoding negotiated with the
+// terminal.
+type KeyboardProtocol struct {
+	Encoding KeyEncoding
+	// KittyFlags are the flags pushed when Encoding is EncodingKitty.
+	KittyFlags KittyFlags
+}
+
+// KeyText returns the text typed by a Kitty CSI u key sequence, as sent when
+// the terminal reports all keys as escape codes. It returns "" for releases,
+// keys without text and any other input.
+func KeyText(data string) string {
+	if !strings.HasPrefix(data, "\x1b[") || !strings.HasSuffix(data, "u") {
+		return ""
+	}
+	ev := Decode(data)
+	if ev.Type == KeyRelease || ev.Modifiers&^(ModShift|ModLocks) != 0 {
+		return ""
+	}
+	return ev.Text
+}
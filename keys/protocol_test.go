@@ -0,0 +1,42 @@
+package keys
+
+import "testing"
+
+func TestKittyFlags(t *testing.T) {
+	if DefaultKittyFlags != 7 {
+		t.Errorf("DefaultKittyFlags = %d, want 7", DefaultKittyFlags)
+	}
+	if got := KittyDisambiguate | KittyReportAllKeys | KittyReportText; got != 25 {
+		t.Errorf("disambiguate|all keys|text = %d, want 25", got)
+	}
+}
+
+func TestKeyText(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"\x1b[97;1;97u", "a"},
+		{"\x1b[98:66;2;66u", "B"},
+		{"\x1b[97;65;97u", "a"}, // caps lock
+		{"\x1b[98:66;2:3u", ""}, // release
+		{"\x1b[97;5u", ""},      // ctrl+a
+		{"\x1b[13u", ""},        // enter
+		{"a", ""},
+		{"\x1b[A", ""},
+	}
+	for _, tt := range tests {
+		if got := KeyText(tt.data); got != tt.want {
+			t.Errorf("KeyText(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestModifyOtherKeys(t *testing.T) {
+	if !MatchesKey("\x1b[27;5;97~", "ctrl+a") {
+		t.Error("modifyOtherKeys ctrl+a should match")
+	}
+	if got := ParseKey("\x1b[27;6;65~"); got != "shift+ctrl+a" {
+		t.Errorf("ParseKey = %q, want shift+ctrl+a", got)
+	}
+}
@@ -0,0 +1,270 @@
+package keys
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultSequenceTimeout is how long a pending sequence prefix waits for its next key.
+const DefaultSequenceTimeout = time.Second
+
+// A binding containing spaces, such as "ctrl+x ctrl+s" or "g g", is a key
+// sequence: its keys must be pressed one after another, each within the
+// sequence timeout of the previous one.
+
+// KeyStroke is the outcome of feeding one input chunk to the sequence state
+// machine. It answers Matches for that chunk only.
+type KeyStroke struct {
+	mgr        *EditorKeybindingsManager
+	data       string
+	pending    bool
+	generation int
+	sequence   string
+	replay     []string
+}
+
+// Pending reports whether the input started or extended a sequence prefix and
+// should be swallowed.
+func (k *KeyStroke) Pending() bool {
+	return k.pending
+}
+
+// Sequence returns the sequence binding completed by this input, or "".
+func (k *KeyStroke) Sequence() string {
+	return k.sequence
+}
+
+// Replay returns the keys of an abandoned prefix. They were swallowed while
+// pending and should now be handled as ordinary input, before this one.
+func (k *KeyStroke) Replay() []string {
+	return k.replay
+}
+
+// OnTimeout registers fn to receive the keys of this pending prefix if it
+// times out. fn runs on a timer goroutine. Without a handler the keys are
+// returned by the next Feed's Replay instead.
+func (k *KeyStroke) OnTimeout(fn func(keys []string)) {
+	if !k.pending {
+		return
+	}
+	m := k.mgr
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	if k.generation == m.seqGeneration {
+		m.seqOnTimeout = fn
+	}
+}
+
+// Matches is EditorKeybindingsManager.Matches, except that an input completing
+// a sequence matches only the actions bound to that sequence.
+func (k *KeyStroke) Matches(data string, action EditorAction) bool {
+	if k.sequence != "" {
+		return data == k.data && slices.Contains(k.mgr.GetKeys(action), k.sequence)
+	}
+	return k.mgr.Matches(data, action)
+}
+
+// KeybindingConflict reports a sequence whose prefix is also bound on its own.
+// While the sequence is configured, the shorter binding can never fire.
+type KeybindingConflict struct {
+	Prefix         string
+	PrefixAction   EditorAction
+	Sequence       string
+	SequenceAction EditorAction
+}
+
+// SetSequenceTimeout sets how long a pending prefix waits for its next key.
+// Zero waits indefinitely.
+func (m *EditorKeybindingsManager) SetSequenceTimeout(timeout time.Duration) {
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	m.seqTimeout = timeout
+}
+
+// SetSequenceIndicator registers a hook called with the pending prefix, e.g.
+// "ctrl+x", whenever it changes, and with "" once it is completed, abandoned
+// or timed out. The hook may run on a timer goroutine.
+func (m *EditorKeybindingsManager) SetSequenceIndicator(fn func(pending string)) {
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	m.seqIndicator = fn
+}
+
+// PendingSequence returns the keys typed so far of an unfinished sequence.
+func (m *EditorKeybindingsManager) PendingSequence() string {
+	m.seqMu.Lock()
+	defer m.seqMu.Unlock()
+	return describeKeys(m.seqPending)
+}
+
+// Feed advances the sequence state machine with one input chunk. Components
+// call it once per key, skip the input when the result is Pending, and use the
+// result's Matches in place of the manager's.
+func (m *EditorKeybindingsManager) Feed(data string) *KeyStroke {
+	m.seqMu.Lock()
+	before := describeKeys(m.seqPending)
+	stroke := m.feedLocked(data)
+	after := describeKeys(m.seqPending)
+	indicator := m.seqIndicator
+	m.seqMu.Unlock()
+
+	if indicator != nil && before != after {
+		indicator(after)
+	}
+	return stroke
+}
+
+func (m *EditorKeybindingsManager) feedLocked(data string) *KeyStroke {
+	stroke := &KeyStroke{mgr: m, data: data}
+	if IsKeyRelease(data) {
+		return stroke
+	}
+	if abandoned := m.seqAbandoned; abandoned != nil {
+		// A prefix timed out with no handler to take it.
+		m.seqAbandoned = nil
+		defer func() { stroke.replay = append(abandoned, stroke.replay...) }()
+	}
+
+	n := len(m.seqPending)
+	complete, extends := "", false
+	for _, bindings := range m.actionToKeys {
+		for _, binding := range bindings {
+			parts := strings.Fields(binding)
+			if len(parts) < 2 || len(parts) <= n || !MatchesKey(data, parts[n]) {
+				continue
+			}
+			if !matchesAll(m.seqPending, parts[:n]) {
+				continue
+			}
+			if len(parts) == n+1 {
+				complete = binding
+			} else {
+				extends = true
+			}
+		}
+	}
+
+	switch {
+	case complete != "":
+		stroke.sequence = complete
+		m.resetSequenceLocked()
+	case extends:
+		m.seqPending = append(m.seqPending, data)
+		m.armSequenceTimerLocked()
+		stroke.pending = true
+		stroke.generation = m.seqGeneration
+	case n > 0:
+		// The prefix went nowhere: hand its keys back and retry this key as
+		// the start of a new sequence.
+		replay := slices.Clone(m.seqPending)
+		m.resetSequenceLocked()
+		stroke = m.feedLocked(data)
+		stroke.replay = append(replay, stroke.replay...)
+	}
+	return stroke
+}
+
+func matchesAll(data []string, keyIDs []string) bool {
+	for i, d := range data {
+		if !MatchesKey(d, keyIDs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *EditorKeybindingsManager) armSequenceTimerLocked() {
+	if m.seqTimer != nil {
+		m.seqTimer.Stop()
+	}
+	m.seqGeneration++
+	if m.seqTimeout <= 0 {
+		return
+	}
+	generation := m.seqGeneration
+	m.seqTimer = time.AfterFunc(m.seqTimeout, func() {
+		m.seqMu.Lock()
+		if generation != m.seqGeneration || len(m.seqPending) == 0 {
+			m.seqMu.Unlock()
+			return
+		}
+		abandoned, onTimeout := m.seqPending, m.seqOnTimeout
+		m.resetSequenceLocked()
+		if onTimeout == nil {
+			m.seqAbandoned = append(m.seqAbandoned, abandoned...)
+		}
+		indicator := m.seqIndicator
+		m.seqMu.Unlock()
+		if indicator != nil {
+			indicator("")
+		}
+		if onTimeout != nil {
+			onTimeout(abandoned)
+		}
+	})
+}
+
+func (m *EditorKeybindingsManager) resetSequenceLocked() {
+	m.seqPending = nil
+	m.seqOnTimeout = nil
+	m.seqGeneration++
+	if m.seqTimer != nil {
+		m.seqTimer.Stop()
+		m.seqTimer = nil
+	}
+}
+
+func describeKeys(data []string) string {
+	names := make([]string, len(data))
+	for i, d := range data {
+		if name := ParseKey(d); name != "" {
+			names[i] = name
+		} else {
+			names[i] = d
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// Conflicts lists sequences whose proper prefix is also bound as a key or a
+// shorter sequence. The result is sorted by sequence.
+func (m *EditorKeybindingsManager) Conflicts() []KeybindingConflict {
+	type binding struct {
+		keys   string
+		action EditorAction
+	}
+	var all []binding
+	for action, keyIDs := range m.actionToKeys {
+		for _, k := range keyIDs {
+			all = append(all, binding{keys: strings.Join(strings.Fields(k), " "), action: action})
+		}
+	}
+
+	var conflicts []KeybindingConflict
+	for _, seq := range all {
+		for _, other := range all {
+			if strings.HasPrefix(seq.keys, other.keys+" ") {
+				conflicts = append(conflicts, KeybindingConflict{
+					Prefix:         other.keys,
+					PrefixAction:   other.action,
+					Sequence:       seq.keys,
+					SequenceAction: seq.action,
+				})
+			}
+		}
+	}
+	slices.SortFunc(conflicts, func(a, b KeybindingConflict) int {
+		if c := strings.Compare(a.Sequence, b.Sequence); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Prefix, b.Prefix); c != 0 {
+			return c
+		}
+		if c := strings.Compare(string(a.PrefixAction), string(b.PrefixAction)); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.SequenceAction), string(b.SequenceAction))
+	})
+	return conflicts
+}
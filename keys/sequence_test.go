@@ -0,0 +1,49 @@
+package keys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeySequenceTimeout(t *testing.T) {
+	mgr := NewEditorKeybindingsManager(EditorKeybindingsConfig{
+		EditorActionDeleteToLineStart: {"ctrl+x ctrl+u"},
+	})
+	mgr.SetSequenceTimeout(10 * time.Millisecond)
+	cleared := make(chan struct{})
+	mgr.SetSequenceIndicator(func(pending string) {
+		if pending == "" {
+			close(cleared)
+		}
+	})
+
+	if !mgr.Feed("\x18").Pending() {
+		t.Fatal("ctrl+x should start a sequence")
+	}
+	select {
+	case <-cleared:
+	case <-time.After(time.Second):
+		t.Fatal("pending prefix did not time out")
+	}
+	if stroke := mgr.Feed("\x15"); stroke.Sequence() != "" || stroke.Pending() {
+		t.Fatalf("ctrl+u after timeout should not complete the sequence")
+	}
+}
+
+func TestKeybindingConflicts(t *testing.T) {
+	mgr := NewEditorKeybindingsManager(EditorKeybindingsConfig{
+		EditorActionUndo:     {"ctrl+x"},
+		EditorActionSubmit:   {"ctrl+x ctrl+s"},
+		EditorActionYank:     {"ctrl+y"},
+		EditorActionCursorUp: {"up"},
+		EditorActionSelectUp: {"up"},
+	})
+	conflicts := mgr.Conflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+	c := conflicts[0]
+	if c.Prefix != "ctrl+x" || c.PrefixAction != EditorActionUndo || c.SequenceAction != EditorActionSubmit {
+		t.Fatalf("unexpected conflict %+v", c)
+	}
+}
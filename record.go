@@ -0,0 +1,483 @@
+package fasttui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/keys"
+)
+
+// keyboardProtocolEnv is the asciicast env entry recording the negotiated
+// keyboard protocol, "kitty:<flags>" or "modifyOtherKeys", so a replay
+// decodes input the same way. It is left out for legacy input.
+const keyboardProtocolEnv = "FASTTUI_KEYBOARD_PROTOCOL"
+
+func formatKeyboardProtocol(p keys.KeyboardProtocol) string {
+	switch p.Encoding {
+	case keys.EncodingKitty:
+		return "kitty:" + strconv.Itoa(int(p.KittyFlags))
+	case keys.EncodingModifyOtherKeys:
+		return "modifyOtherKeys"
+	}
+	return ""
+}
+
+func parseKeyboardProtocol(s string) keys.KeyboardProtocol {
+	name, flags, _ := strings.Cut(s, ":")
+	switch name {
+	case "kitty":
+		n, err := strconv.Atoi(flags)
+		if err != nil {
+			n = int(keys.DefaultKittyFlags)
+		}
+		return keys.KeyboardProtocol{Encoding: keys.EncodingKitty, KittyFlags: keys.KittyFlags(n)}
+	case "modifyOtherKeys":
+		return keys.KeyboardProtocol{Encoding: keys.EncodingModifyOtherKeys}
+	}
+	return keys.KeyboardProtocol{Encoding: keys.EncodingLegacy}
+}
+
+// castHeader is the first line of an asciicast v2 file.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// castEvent is an asciicast v2 event: seconds since start, "o" for output,
+// "i" for input or "r" for a resize to "COLSxROWS", and the data.
+type castEvent struct {
+	Time float64
+	Code string
+	Data string
+}
+
+func (e castEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Code, e.Data})
+}
+
+func (e *castEvent) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields[1], &e.Code); err != nil {
+		return err
+	}
+	return json.Unmarshal(fields[2], &e.Data)
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithRecordingTitle sets the title stored in the recording header.
+func WithRecordingTitle(title string) RecorderOption {
+	return func(r *Recorder) { r.title = title }
+}
+
+// WithRecordInput controls whether input events are recorded. It is on by
+// default; turn it off when input may contain secrets. A recording without
+// input can still be played back but not replayed into a TUI.
+func WithRecordInput(enabled bool) RecorderOption {
+	return func(r *Recorder) { r.recordInput = enabled }
+}
+
+// Recorder is a Terminal that passes everything through to another terminal
+// and writes an asciicast v2 recording of the session: every write as an
+// output event, keyboard input as input events and size changes as resize
+// events. Recordings play in asciinema and replay with ReplayTerminal.
+type Recorder struct {
+	term        Terminal
+	title       string
+	recordInput bool
+	now         func() time.Time
+
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+	err   error
+}
+
+// NewRecorder wraps term, writing the recording to w. The header is written
+// when the TUI starts the terminal.
+func NewRecorder(term Terminal, w io.Writer, opts ...RecorderOption) *Recorder {
+	r := &Recorder{term: term, w: w, recordInput: true, now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Err returns the first error writing the recording. Recording stops at
+// that point; the session itself is unaffected.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) Start(onInput func(data string), onResize func()) error {
+	err := r.term.Start(func(data string) {
+		if r.recordInput {
+			r.event("i", data)
+		}
+		onInput(data)
+	}, func() {
+		w, h := r.term.GetSize()
+		r.event("r", strconv.Itoa(w)+"x"+strconv.Itoa(h))
+		onResize()
+	})
+	if err != nil {
+		return err
+	}
+
+	w, h := r.term.GetSize()
+	header := castHeader{Version: 2, Width: w, Height: h, Title: r.title, Env: map[string]string{}}
+	if term := os.Getenv("TERM"); term != "" {
+		header.Env["TERM"] = term
+	}
+	if protocol := formatKeyboardProtocol(r.KeyboardProtocol()); protocol != "" {
+		header.Env[keyboardProtocolEnv] = protocol
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start = r.now()
+	header.Timestamp = r.start.Unix()
+	r.writeLine(header)
+	return nil
+}
+
+// event appends an event; events before Start are dropped.
+func (r *Recorder) event(code, data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.start.IsZero() {
+		return
+	}
+	elapsed := r.now().Sub(r.start).Seconds()
+	r.writeLine(castEvent{Time: float64(time.Duration(elapsed*1e6)) / 1e6, Code: code, Data: data})
+}
+
+func (r *Recorder) writeLine(v any) {
+	if r.err != nil {
+		return
+	}
+	line, err := json.Marshal(v)
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	r.err = err
+}
+
+// output records data the wrapped terminal writes for a cursor or screen
+// call, unless it is a plain terminal that ignores those calls.
+func (r *Recorder) output(data string) {
+	if !r.IsPlain() {
+		r.event("o", data)
+	}
+}
+
+func (r *Recorder) Stop() { r.term.Stop() }
+
+func (r *Recorder) Write(data string) {
+	r.term.Write(data)
+	r.event("o", data)
+}
+
+func (r *Recorder) GetSize() (int, int) { return r.term.GetSize() }
+
+func (r *Recorder) IsKittyProtocolActive() bool { return r.term.IsKittyProtocolActive() }
+
+func (r *Recorder) MoveBy(lines int) {
+	r.term.MoveBy(lines)
+	if lines > 0 {
+		r.output("\x1b[" + strconv.Itoa(lines) + "B")
+	} else if lines < 0 {
+		r.output("\x1b[" + strconv.Itoa(-lines) + "A")
+	}
+}
+
+func (r *Recorder) HideCursor() {
+	r.term.HideCursor()
+	r.output("\x1b[?25l")
+}
+
+func (r *Recorder) ShowCursor() {
+	r.term.ShowCursor()
+	r.output("\x1b[?25h")
+}
+
+func (r *Recorder) ClearLine() {
+	r.term.ClearLine()
+	r.output("\x1b[K")
+}
+
+func (r *Recorder) ClearFromCursor() {
+	r.term.ClearFromCursor()
+	r.output("\x1b[J")
+}
+
+func (r *Recorder) ClearScreen() {
+	r.term.ClearScreen()
+	r.output("\x1b[2J\x1b[H")
+}
+
+func (r *Recorder) SetTitle(title string) {
+	r.term.SetTitle(title)
+	r.output("\x1b]0;" + title + "\x07")
+}
+
+// IsPlain forwards to the wrapped terminal when it is a PlainTerminal.
+func (r *Recorder) IsPlain() bool {
+	pt, ok := r.term.(PlainTerminal)
+	return ok && pt.IsPlain()
+}
+
+// Suspend forwards to the wrapped terminal when it is a SuspendableTerminal.
+func (r *Recorder) Suspend() error {
+	if st, ok := r.term.(SuspendableTerminal); ok {
+		return st.Suspend()
+	}
+	return ErrSuspendUnsupported
+}
+
+// Resume forwards to the wrapped terminal when it is a SuspendableTerminal.
+func (r *Recorder) Resume() error {
+	if st, ok := r.term.(SuspendableTerminal); ok {
+		return st.Resume()
+	}
+	return ErrSuspendUnsupported
+}
+
+// KeyboardProtocol forwards to the wrapped terminal, with the same fallback
+// as TUI.KeyboardProtocol.
+func (r *Recorder) KeyboardProtocol() keys.KeyboardProtocol {
+	if kt, ok := r.term.(KeyboardTerminal); ok {
+		return kt.KeyboardProtocol()
+	}
+	if r.term.IsKittyProtocolActive() {
+		return keys.KeyboardProtocol{Encoding: keys.EncodingKitty, KittyFlags: keys.DefaultKittyFlags}
+	}
+	return keys.KeyboardProtocol{Encoding: keys.EncodingLegacy}
+}
+
+// SupportsSynchronizedOutput forwards to the wrapped terminal, reporting
+// true when it is not a CapabilityTerminal.
+func (r *Recorder) SupportsSynchronizedOutput() bool {
+	if ct, ok := r.term.(CapabilityTerminal); ok {
+		return ct.SupportsSynchronizedOutput()
+	}
+	return true
+}
+
+// OnColorSchemeChange forwards to the wrapped terminal when it is a
+// ColorSchemeTerminal.
+func (r *Recorder) OnColorSchemeChange(fn func(background string)) {
+	if ct, ok := r.term.(ColorSchemeTerminal); ok {
+		ct.OnColorSchemeChange(fn)
+	}
+}
+
+// ReplayOption configures a ReplayTerminal.
+type ReplayOption func(*ReplayTerminal)
+
+// WithReplaySpeed scales the recorded delays: 1 replays at the original
+// pace, 10 ten times faster. A speed of 0 or less replays without delays.
+func WithReplaySpeed(speed float64) ReplayOption {
+	return func(r *ReplayTerminal) { r.speed = speed }
+}
+
+// WithReplayOutput sends what the TUI writes to w, for example os.Stdout to
+// watch the replay. By default output is only kept for Output.
+func WithReplayOutput(w io.Writer) ReplayOption {
+	return func(r *ReplayTerminal) { r.out = w }
+}
+
+// ReplayTerminal is a Terminal that feeds the input and resize events of an
+// asciicast v2 recording into a TUI, so a renderer issue can be reproduced
+// from a Recorder recording.
+type ReplayTerminal struct {
+	header castHeader
+	events []castEvent
+	speed  float64
+	out    io.Writer
+
+	mu     sync.Mutex
+	width  int
+	height int
+	output strings.Builder
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewReplayTerminal reads an asciicast v2 recording.
+func NewReplayTerminal(recording io.Reader, opts ...ReplayOption) (*ReplayTerminal, error) {
+	r := &ReplayTerminal{speed: 1, stop: make(chan struct{}), done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	scanner := bufio.NewScanner(recording)
+	scanner.Buffer(nil, 16<<20)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("asciicast: empty recording")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &r.header); err != nil {
+		return nil, fmt.Errorf("asciicast header: %w", err)
+	}
+	if r.header.Version != 2 {
+		return nil, fmt.Errorf("asciicast: unsupported version %d", r.header.Version)
+	}
+	for line := 2; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var ev castEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("asciicast line %d: %w", line, err)
+		}
+		r.events = append(r.events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	r.width, r.height = r.header.Width, r.header.Height
+	return r, nil
+}
+
+// Start begins feeding recorded events to the handlers.
+func (r *ReplayTerminal) Start(onInput func(data string), onResize func()) error {
+	if r.IsKittyProtocolActive() {
+		keys.SetKittyProtocolActive(true)
+	}
+	go r.replay(onInput, onResize)
+	return nil
+}
+
+func (r *ReplayTerminal) replay(onInput func(data string), onResize func()) {
+	defer close(r.done)
+	last := 0.0
+	for _, ev := range r.events {
+		if r.speed > 0 && ev.Time > last {
+			timer := time.NewTimer(time.Duration((ev.Time - last) / r.speed * float64(time.Second)))
+			select {
+			case <-timer.C:
+			case <-r.stop:
+				timer.Stop()
+				return
+			}
+		}
+		last = max(last, ev.Time)
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+		switch ev.Code {
+		case "i":
+			onInput(ev.Data)
+		case "r":
+			cols, rows, ok := strings.Cut(ev.Data, "x")
+			w, errW := strconv.Atoi(cols)
+			h, errH := strconv.Atoi(rows)
+			if !ok || errW != nil || errH != nil {
+				continue
+			}
+			r.mu.Lock()
+			r.width, r.height = w, h
+			r.mu.Unlock()
+			onResize()
+		}
+	}
+}
+
+// Done is closed once every recorded event has been replayed, or on Stop.
+func (r *ReplayTerminal) Done() <-chan struct{} { return r.done }
+
+// Output returns everything the TUI has written during the replay.
+func (r *ReplayTerminal) Output() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.output.String()
+}
+
+// RecordedOutput returns the output events of the recording, to compare
+// with Output.
+func (r *ReplayTerminal) RecordedOutput() string {
+	var b strings.Builder
+	for _, ev := range r.events {
+		if ev.Code == "o" {
+			b.WriteString(ev.Data)
+		}
+	}
+	return b.String()
+}
+
+func (r *ReplayTerminal) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+		if r.IsKittyProtocolActive() {
+			keys.SetKittyProtocolActive(false)
+		}
+	})
+}
+
+func (r *ReplayTerminal) Write(data string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.output.WriteString(data)
+	if r.out != nil {
+		io.WriteString(r.out, data)
+	}
+}
+
+func (r *ReplayTerminal) GetSize() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.width, r.height
+}
+
+func (r *ReplayTerminal) IsKittyProtocolActive() bool {
+	return r.KeyboardProtocol().Encoding == keys.EncodingKitty
+}
+
+// KeyboardProtocol returns the keyboard protocol of the recorded session.
+func (r *ReplayTerminal) KeyboardProtocol() keys.KeyboardProtocol {
+	return parseKeyboardProtocol(r.header.Env[keyboardProtocolEnv])
+}
+
+func (r *ReplayTerminal) MoveBy(lines int) {
+	if lines > 0 {
+		r.Write("\x1b[" + strconv.Itoa(lines) + "B")
+	} else if lines < 0 {
+		r.Write("\x1b[" + strconv.Itoa(-lines) + "A")
+	}
+}
+
+func (r *ReplayTerminal) HideCursor()           { r.Write("\x1b[?25l") }
+func (r *ReplayTerminal) ShowCursor()           { r.Write("\x1b[?25h") }
+func (r *ReplayTerminal) ClearLine()            { r.Write("\x1b[K") }
+func (r *ReplayTerminal) ClearFromCursor()      { r.Write("\x1b[J") }
+func (r *ReplayTerminal) ClearScreen()          { r.Write("\x1b[2J\x1b[H") }
+func (r *ReplayTerminal) SetTitle(title string) { r.Write("\x1b]0;" + title + "\x07") }
@@ -0,0 +1,159 @@
+package fasttui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/keys"
+)
+
+// TestRecorderWritesAsciicast verifies the header and the output, input and
+// resize events of a recording, and that a ReplayTerminal feeds the input
+// and resizes back.
+func TestRecorderWritesAsciicast(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	term := &testTerminal{}
+	var cast bytes.Buffer
+	rec := NewRecorder(term, &cast, WithRecordingTitle("bug"))
+	clock := time.Unix(1700000000, 0)
+	rec.now = func() time.Time {
+		clock = clock.Add(250 * time.Millisecond)
+		return clock
+	}
+
+	rec.Write("ignored before start")
+	if err := rec.Start(func(string) {}, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	rec.Write("hi")
+	term.onInput("a")
+	term.onResize()
+	rec.MoveBy(-2)
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"version":2,"width":80,"height":24,"timestamp":1700000000,"title":"bug","env":{"TERM":"xterm-256color"}}
+[0.25,"o","hi"]
+[0.5,"i","a"]
+[0.75,"r","80x24"]
+[1,"o","\u001b[2A"]
+`
+	got := cast.String()
+	if got != want {
+		t.Fatalf("recording:\n%s\nwant:\n%s", got, want)
+	}
+
+	replay, err := NewReplayTerminal(strings.NewReader(got), WithReplaySpeed(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.RecordedOutput() != "hi\x1b[2A" {
+		t.Errorf("recorded output = %q", replay.RecordedOutput())
+	}
+	var inputs []string
+	resizes := 0
+	replay.Start(func(data string) { inputs = append(inputs, data) }, func() { resizes++ })
+	select {
+	case <-replay.Done():
+	case <-time.After(time.Second):
+		t.Fatal("replay did not finish")
+	}
+	if len(inputs) != 1 || inputs[0] != "a" || resizes != 1 {
+		t.Errorf("replayed inputs %q, %d resizes", inputs, resizes)
+	}
+	if w, h := replay.GetSize(); w != 80 || h != 24 {
+		t.Errorf("size = %dx%d", w, h)
+	}
+}
+
+// TestReplayTerminalDrivesTUI replays recorded keystrokes into a TUI at an
+// accelerated speed.
+func TestReplayTerminalDrivesTUI(t *testing.T) {
+	cast := `{"version":2,"width":20,"height":5}
+[0.5,"i","x"]
+[1.0,"r","30x6"]
+[1.5,"i","y"]
+`
+	replay, err := NewReplayTerminal(strings.NewReader(cast), WithReplaySpeed(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tui := NewTUI(replay, false)
+	comp := &inputRecorder{}
+	tui.AddChild(comp)
+	tui.SetFocus(comp)
+	tui.Start()
+	defer tui.Stop()
+
+	start := time.Now()
+	select {
+	case <-replay.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("replay did not finish")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("replay at 100x took %v", elapsed)
+	}
+	var got string
+	done := make(chan struct{})
+	tui.Post(func() { got = comp.inputs; close(done) })
+	<-done
+	if got != "xy" {
+		t.Errorf("TUI received %q", got)
+	}
+	if w, _ := replay.GetSize(); w != 30 {
+		t.Errorf("width after resize = %d", w)
+	}
+	if !strings.Contains(replay.Output(), "xy") {
+		t.Errorf("replay output lacks the rendered input: %q", replay.Output())
+	}
+}
+
+type keyboardTestTerminal struct {
+	testTerminal
+	protocol keys.KeyboardProtocol
+}
+
+func (k *keyboardTestTerminal) KeyboardProtocol() keys.KeyboardProtocol { return k.protocol }
+
+// TestRecorderKeepsKeyboardProtocol verifies that a replay reports the
+// keyboard protocol negotiated in the recorded session, Kitty flags included.
+func TestRecorderKeepsKeyboardProtocol(t *testing.T) {
+	for _, protocol := range []keys.KeyboardProtocol{
+		{Encoding: keys.EncodingLegacy},
+		{Encoding: keys.EncodingModifyOtherKeys},
+		{Encoding: keys.EncodingKitty, KittyFlags: keys.KittyDisambiguate | keys.KittyReportAllKeys | keys.KittyReportText},
+	} {
+		var cast bytes.Buffer
+		rec := NewRecorder(&keyboardTestTerminal{protocol: protocol}, &cast)
+		if err := rec.Start(func(string) {}, func() {}); err != nil {
+			t.Fatal(err)
+		}
+		replay, err := NewReplayTerminal(&cast)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kt KeyboardTerminal = replay
+		if got := kt.KeyboardProtocol(); got != protocol {
+			t.Errorf("replayed protocol = %+v, want %+v", got, protocol)
+		}
+		if replay.IsKittyProtocolActive() != (protocol.Encoding == keys.EncodingKitty) {
+			t.Errorf("%+v: IsKittyProtocolActive = %v", protocol, replay.IsKittyProtocolActive())
+		}
+	}
+}
+
+type inputRecorder struct {
+	inputs  string
+	focused bool
+}
+
+func (c *inputRecorder) Render(width int) []string { return []string{c.inputs} }
+func (c *inputRecorder) HandleInput(data string)   { c.inputs += data }
+func (c *inputRecorder) WantsKeyRelease() bool     { return false }
+func (c *inputRecorder) Invalidate()               {}
+func (c *inputRecorder) SetFocused(f bool)         { c.focused = f }
+func (c *inputRecorder) IsFocused() bool           { return c.focused }
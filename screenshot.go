@@ -0,0 +1,402 @@
+package fasttui
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/clipperhouse/uax29/v2/graphemes"
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+// ScreenshotOption configures ScreenshotHTML and ScreenshotSVG.
+type ScreenshotOption func(*screenshotConfig)
+
+type screenshotConfig struct {
+	theme      string
+	title      string
+	width      int
+	fontFamily string
+	fontSize   float64
+}
+
+// WithScreenshotTheme sets the theme whose terminal palette and export colors
+// are used. The default is style.DefaultThemeName.
+func WithScreenshotTheme(name string) ScreenshotOption {
+	return func(c *screenshotConfig) { c.theme = name }
+}
+
+// WithScreenshotTitle adds a title bar above the screen.
+func WithScreenshotTitle(title string) ScreenshotOption {
+	return func(c *screenshotConfig) { c.title = title }
+}
+
+// WithScreenshotWidth sets the screen width in columns. The default is the
+// widest line.
+func WithScreenshotWidth(cols int) ScreenshotOption {
+	return func(c *screenshotConfig) { c.width = cols }
+}
+
+// WithScreenshotFont sets the monospace font family and size in pixels.
+func WithScreenshotFont(family string, size float64) ScreenshotOption {
+	return func(c *screenshotConfig) {
+		c.fontFamily = family
+		c.fontSize = size
+	}
+}
+
+// cellStyle is the resolved look of a screen cell. Colors are "#rrggbb", or
+// "" for the screen default.
+type cellStyle struct {
+	fg, bg         string
+	bold           bool
+	italic         bool
+	underline      int // SGR 4:n style
+	underlineColor string
+	overline       bool
+	strike         bool
+	link           string
+}
+
+// screenRun is a stretch of cells on one row that share a style.
+type screenRun struct {
+	col   int
+	width int
+	text  string
+	wide  bool // a single wide grapheme
+	style cellStyle
+}
+
+// screenshot is a parsed frame together with the colors to draw it in.
+type screenshot struct {
+	screenshotConfig
+	rows    [][]screenRun
+	cols    int
+	palette *style.TerminalPalette
+	pageBg  string
+	cardBg  string
+	infoBg  string
+}
+
+func newScreenshot(lines []string, opts []ScreenshotOption) (*screenshot, error) {
+	s := &screenshot{screenshotConfig: screenshotConfig{
+		fontFamily: "ui-monospace, Menlo, Consolas, monospace",
+		fontSize:   14,
+	}}
+	for _, opt := range opts {
+		opt(&s.screenshotConfig)
+	}
+	theme := cmp.Or(s.theme, style.DefaultThemeName())
+	palette, err := style.ThemeTerminalPalette(theme)
+	if err != nil {
+		return nil, err
+	}
+	export, err := style.ExportColors(theme)
+	if err != nil {
+		return nil, err
+	}
+	s.palette = palette
+	s.pageBg = exportColorOr(export.PageBg, palette.Background)
+	s.cardBg = exportColorOr(export.CardBg, s.pageBg)
+	s.infoBg = exportColorOr(export.InfoBg, s.cardBg)
+
+	for _, line := range lines {
+		row, width := s.parseLine(line)
+		s.rows = append(s.rows, row)
+		s.cols = max(s.cols, width)
+	}
+	if s.width > 0 {
+		s.cols = s.width
+	}
+	return s, nil
+}
+
+func exportColorOr(c *string, fallback string) string {
+	if c == nil || *c == "" {
+		return fallback
+	}
+	return *c
+}
+
+// parseLine splits a rendered line into runs, tracking SGR state and OSC 8
+// hyperlinks with an AnsiCodeTracker.
+func (s *screenshot) parseLine(line string) ([]screenRun, int) {
+	var runs []screenRun
+	tracker := NewAnsiCodeTracker()
+	col := 0
+	for i := 0; i < len(line); {
+		if code, n, ok := ExtractAnsiCode(line, i); ok {
+			tracker.Process(code)
+			i += n
+			continue
+		}
+		end := i
+		for end < len(line) && line[end] != '\x1b' {
+			end++
+		}
+		if end == i {
+			end++ // lone ESC
+		}
+		cs := s.cellStyle(tracker)
+		g := graphemes.FromString(line[i:end])
+		for g.Next() {
+			text := g.Value()
+			if text[0] < 0x20 || text[0] == 0x7f {
+				continue
+			}
+			w := GraphemeWidth(text)
+			last := len(runs) - 1
+			switch {
+			case w == 0 && last >= 0:
+				runs[last].text += text
+			case w == 0:
+			case w == 1 && last >= 0 && !runs[last].wide && runs[last].style == cs:
+				runs[last].text += text
+				runs[last].width++
+			default:
+				runs = append(runs, screenRun{col: col, width: w, text: text, wide: w > 1, style: cs})
+			}
+			col += w
+		}
+		i = end
+	}
+	return runs, col
+}
+
+// osc8URL returns the URI of an OSC 8 hyperlink sequence, "" when it closes
+// the link or code is "".
+func osc8URL(code string) string {
+	body := strings.TrimPrefix(code, "\x1b]8;")
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\x07"), "\x1b\\")
+	_, uri, _ := strings.Cut(body, ";")
+	return uri
+}
+
+func (s *screenshot) cellStyle(t *AnsiCodeTracker) cellStyle {
+	cs := cellStyle{
+		fg:             s.sgrColor(t.fgColor),
+		bg:             s.sgrColor(t.bgColor),
+		bold:           t.bold,
+		italic:         t.italic,
+		underline:      t.underline,
+		underlineColor: s.sgrColor(t.underlineColor),
+		overline:       t.overline,
+		strike:         t.strikethrough,
+		link:           osc8URL(t.hyperlink),
+	}
+	if t.inverse {
+		fg, bg := cmp.Or(cs.fg, s.palette.Foreground), cmp.Or(cs.bg, s.cardBg)
+		cs.fg, cs.bg = bg, fg
+	}
+	if t.dim {
+		cs.fg = blendHex(cmp.Or(cs.fg, s.palette.Foreground), cmp.Or(cs.bg, s.cardBg), 0.4)
+	}
+	if t.hidden {
+		cs.fg = cmp.Or(cs.bg, s.cardBg)
+	}
+	return cs
+}
+
+// sgrColor maps a tracker color ("31", "38;5;208", "48;2;r;g;b") to hex,
+// taking the 16 ANSI colors from the theme's terminal palette.
+func (s *screenshot) sgrColor(code string) string {
+	if code == "" {
+		return ""
+	}
+	parts := strings.Split(code, ";")
+	n := make([]int, len(parts))
+	for i, p := range parts {
+		n[i], _ = strconv.Atoi(p)
+	}
+	switch {
+	case len(n) == 3 && n[1] == 5:
+		if n[2] < 16 {
+			return s.palette.ANSI[n[2]]
+		}
+		return style.Ansi256ToHex(n[2])
+	case len(n) == 5 && n[1] == 2:
+		return fmt.Sprintf("#%02x%02x%02x", n[2]&0xff, n[3]&0xff, n[4]&0xff)
+	case n[0] >= 30 && n[0] <= 37:
+		return s.palette.ANSI[n[0]-30]
+	case n[0] >= 40 && n[0] <= 47:
+		return s.palette.ANSI[n[0]-40]
+	case n[0] >= 90 && n[0] <= 97:
+		return s.palette.ANSI[n[0]-90+8]
+	case n[0] >= 100 && n[0] <= 107:
+		return s.palette.ANSI[n[0]-100+8]
+	}
+	return ""
+}
+
+// blendHex mixes b into a by weight w.
+func blendHex(a, b string, w float64) string {
+	ca, errA := strconv.ParseUint(strings.TrimPrefix(a, "#"), 16, 32)
+	cb, errB := strconv.ParseUint(strings.TrimPrefix(b, "#"), 16, 32)
+	if errA != nil || errB != nil {
+		return a
+	}
+	var out [3]uint64
+	for i, shift := range []uint{16, 8, 0} {
+		x, y := float64(ca>>shift&0xff), float64(cb>>shift&0xff)
+		out[i] = uint64(x + (y-x)*w + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", out[0], out[1], out[2])
+}
+
+func (cs cellStyle) css() string {
+	var b strings.Builder
+	if cs.fg != "" {
+		b.WriteString("color:" + cs.fg + ";")
+	}
+	if cs.bg != "" {
+		b.WriteString("background:" + cs.bg + ";")
+	}
+	if cs.bold {
+		b.WriteString("font-weight:bold;")
+	}
+	if cs.italic {
+		b.WriteString("font-style:italic;")
+	}
+	b.WriteString(cs.decoration())
+	return b.String()
+}
+
+// underlineCSSStyles maps SGR 4:n underline styles to text-decoration-style.
+var underlineCSSStyles = [...]string{underlineDouble: "double", underlineCurly: "wavy", underlineDotted: "dotted", underlineDashed: "dashed"}
+
+// decoration returns the text-decoration CSS declarations.
+func (cs cellStyle) decoration() string {
+	var lines []string
+	if cs.underline != underlineNone {
+		lines = append(lines, "underline")
+	}
+	if cs.overline {
+		lines = append(lines, "overline")
+	}
+	if cs.strike {
+		lines = append(lines, "line-through")
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	d := "text-decoration-line:" + strings.Join(lines, " ") + ";"
+	if cs.underline < len(underlineCSSStyles) && underlineCSSStyles[cs.underline] != "" {
+		d += "text-decoration-style:" + underlineCSSStyles[cs.underline] + ";"
+	}
+	if cs.underlineColor != "" && cs.underline != underlineNone {
+		d += "text-decoration-color:" + cs.underlineColor + ";"
+	}
+	return d
+}
+
+// ScreenshotHTML renders lines, as produced by Component.Render or
+// TUI.Frame, to a standalone HTML page with inline styles. The page uses the
+// theme's export.pageBg, the screen export.cardBg and the title bar
+// export.infoBg, falling back to the theme's terminal background. Wide
+// characters take two columns and OSC 8 hyperlinks become links.
+func ScreenshotHTML(lines []string, opts ...ScreenshotOption) ([]byte, error) {
+	s, err := newScreenshot(lines, opts)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(cmp.Or(s.title, "fasttui")))
+	b.WriteString("</head>\n")
+	fmt.Fprintf(&b, "<body style=\"margin:0;padding:24px;background:%s\">\n", s.pageBg)
+	fmt.Fprintf(&b, "<div style=\"display:inline-block;background:%s;color:%s;border-radius:8px;overflow:hidden\">\n", s.cardBg, s.palette.Foreground)
+	if s.title != "" {
+		fmt.Fprintf(&b, "<div style=\"background:%s;padding:6px 12px;font-family:%s;font-size:%gpx\">%s</div>\n",
+			s.infoBg, html.EscapeString(s.fontFamily), s.fontSize, html.EscapeString(s.title))
+	}
+	fmt.Fprintf(&b, "<pre style=\"margin:0;padding:12px;width:%dch;font-family:%s;font-size:%gpx;line-height:1.25\">",
+		s.cols, html.EscapeString(s.fontFamily), s.fontSize)
+	for i, row := range s.rows {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		for _, run := range row {
+			text := html.EscapeString(run.text)
+			if run.wide {
+				text = "<span style=\"display:inline-block;width:2ch\">" + text + "</span>"
+			}
+			if css := run.style.css(); css != "" {
+				text = "<span style=\"" + css + "\">" + text + "</span>"
+			}
+			if run.style.link != "" {
+				text = "<a href=\"" + html.EscapeString(run.style.link) + "\" style=\"color:inherit\">" + text + "</a>"
+			}
+			b.WriteString(text)
+		}
+	}
+	b.WriteString("</pre>\n</div>\n</body>\n</html>\n")
+	return b.Bytes(), nil
+}
+
+// ScreenshotSVG renders lines to an SVG image, one text element per run of
+// identically styled cells, each stretched to its column width so the grid
+// holds in any monospace font. Colors follow ScreenshotHTML.
+func ScreenshotSVG(lines []string, opts ...ScreenshotOption) ([]byte, error) {
+	s, err := newScreenshot(lines, opts)
+	if err != nil {
+		return nil, err
+	}
+	const pad = 12.0
+	cellW := s.fontSize * 0.6
+	lineH := s.fontSize * 1.25
+	top := pad
+	if s.title != "" {
+		top += lineH + pad
+	}
+	width := float64(s.cols)*cellW + 2*pad
+	height := top + float64(len(s.rows))*lineH + pad
+	n := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n", n(width), n(height), n(width), n(height))
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" rx=\"8\" fill=\"%s\"/>\n", s.cardBg)
+	if s.title != "" {
+		fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"%s\" fill=\"%s\"/>\n", n(lineH+pad), s.infoBg)
+	}
+	fmt.Fprintf(&b, "<g font-family=\"%s\" font-size=\"%s\" fill=\"%s\" xml:space=\"preserve\">\n",
+		html.EscapeString(s.fontFamily), n(s.fontSize), s.palette.Foreground)
+	if s.title != "" {
+		fmt.Fprintf(&b, "<text x=\"%s\" y=\"%s\">%s</text>\n", n(pad), n(pad/2+s.fontSize), html.EscapeString(s.title))
+	}
+	for i, row := range s.rows {
+		y := top + float64(i)*lineH
+		for _, run := range row {
+			x, w := pad+float64(run.col)*cellW, float64(run.width)*cellW
+			if run.style.bg != "" {
+				fmt.Fprintf(&b, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n", n(x), n(y), n(w), n(lineH), run.style.bg)
+			}
+			if strings.TrimSpace(run.text) == "" {
+				continue
+			}
+			var attrs strings.Builder
+			if run.style.fg != "" {
+				fmt.Fprintf(&attrs, " fill=\"%s\"", run.style.fg)
+			}
+			if run.style.bold {
+				attrs.WriteString(" font-weight=\"bold\"")
+			}
+			if run.style.italic {
+				attrs.WriteString(" font-style=\"italic\"")
+			}
+			if d := run.style.decoration(); d != "" {
+				fmt.Fprintf(&attrs, " style=\"%s\"", d)
+			}
+			text := fmt.Sprintf("<text x=\"%s\" y=\"%s\" textLength=\"%s\" lengthAdjust=\"spacingAndGlyphs\"%s>%s</text>",
+				n(x), n(y+s.fontSize), n(w), attrs.String(), html.EscapeString(run.text))
+			if run.style.link != "" {
+				text = "<a href=\"" + html.EscapeString(run.style.link) + "\">" + text + "</a>"
+			}
+			b.WriteString(text + "\n")
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.Bytes(), nil
+}
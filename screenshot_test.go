@@ -0,0 +1,60 @@
+package fasttui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+// TestScreenshotHTML verifies colors from the theme palette, links and wide
+// characters in the HTML export.
+func TestScreenshotHTML(t *testing.T) {
+	palette, err := style.ThemeTerminalPalette("dark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		"\x1b[1;31mfail\x1b[0m <ok> \x1b[38;2;1;2;3mrgb\x1b[0m",
+		"\x1b]8;;https://example.com/?a=1&b=2\x07link\x1b]8;;\x07 中文",
+		"\x1b[4:3;58;2;255;0;0mtypo\x1b[24;59m",
+	}
+	out, err := ScreenshotHTML(lines, WithScreenshotTheme("dark"), WithScreenshotTitle("demo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(out)
+	for _, want := range []string{
+		`<span style="color:` + palette.ANSI[1] + `;font-weight:bold;">fail</span> &lt;ok&gt; `,
+		`<span style="color:#010203;">rgb</span>`,
+		`<a href="https://example.com/?a=1&amp;b=2" style="color:inherit">link</a>`,
+		`<span style="display:inline-block;width:2ch">中</span>`,
+		`<span style="text-decoration-line:underline;text-decoration-style:wavy;text-decoration-color:#ff0000;">typo</span>`,
+		`width:13ch`,
+		`>demo</div>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html lacks %q:\n%s", want, html)
+		}
+	}
+}
+
+// TestScreenshotSVG verifies that runs are placed on the cell grid, with
+// wide characters taking two columns.
+func TestScreenshotSVG(t *testing.T) {
+	out, err := ScreenshotSVG([]string{"中x\x1b[7my\x1b[0m"}, WithScreenshotTheme("dark"), WithScreenshotFont("mono", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := string(out)
+	for _, want := range []string{
+		`width="48"`,
+		`<text x="12" y="22" textLength="12" lengthAdjust="spacingAndGlyphs">中</text>`,
+		`<text x="24" y="22" textLength="6" lengthAdjust="spacingAndGlyphs">x</text>`,
+		`<rect x="30" y="12" width="6" height="12.5" fill="`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("svg lacks %q:\n%s", want, svg)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package style
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// base16Colors maps theme tokens to base16 slots, following the base16
+// styling guidelines: base00-base07 run from background to foreground,
+// base08-base0F are red, orange, yellow, green, cyan, blue, magenta and brown.
+// Values may be color expressions over the slots.
+var base16Colors = map[string]string{
+	"accent":              "base0D",
+	"border":              "base03",
+	"borderAccent":        "base0D",
+	"borderMuted":         "base02",
+	"success":             "base0B",
+	"error":               "base08",
+	"warning":             "base0A",
+	"muted":               "base04",
+	"dim":                 "base03",
+	"text":                "base05",
+	"thinkingText":        "base04",
+	"selectedBg":          "base02",
+	"userMessageBg":       "base01",
+	"userMessageText":     "base05",
+	"customMessageBg":     "base01",
+	"customMessageText":   "base05",
+	"customMessageLabel":  "base0E",
+	"toolPendingBg":       "base01",
+	"toolSuccessBg":       "mix(base01, base0B, 0.12)",
+	"toolErrorBg":         "mix(base01, base08, 0.12)",
+	"toolTitle":           "base0D",
+	"toolOutput":          "base05",
+	"mdHeading":           "base0D",
+	"mdLink":              "base0C",
+	"mdLinkUrl":           "base04",
+	"mdCode":              "base0B",
+	"mdCodeBlock":         "base05",
+	"mdCodeBlockBorder":   "base03",
+	"mdQuote":             "base0C",
+	"mdQuoteBorder":       "base03",
+	"mdHr":                "base03",
+	"mdListBullet":        "base0E",
+	"toolDiffAdded":       "base0B",
+	"toolDiffRemoved":     "base08",
+	"toolDiffContext":     "base04",
+	"syntaxComment":       "base03",
+	"syntaxKeyword":       "base0E",
+	"syntaxFunction":      "base0D",
+	"syntaxVariable":      "base08",
+	"syntaxString":        "base0B",
+	"syntaxNumber":        "base09",
+	"syntaxType":          "base0A",
+	"syntaxOperator":      "base05",
+	"syntaxPunctuation":   "base05",
+	"thinkingOff":         "base03",
+	"thinkingMinimal":     "base04",
+	"thinkingLow":         "base0D",
+	"thinkingMedium":      "base0C",
+	"thinkingHigh":        "base0E",
+	"thinkingXhigh":       "base08",
+	"bashMode":            "base0B",
+	"pythonMode":          "base0A",
+	"statusLineBg":        "base01",
+	"statusLineSep":       "base03",
+	"statusLineModel":     "base0E",
+	"statusLinePath":      "base0D",
+	"statusLineGitClean":  "base0B",
+	"statusLineGitDirty":  "base0A",
+	"statusLineContext":   "base04",
+	"statusLineSpend":     "base0C",
+	"statusLineStaged":    "base0B",
+	"statusLineDirty":     "base0A",
+	"statusLineUntracked": "base08",
+	"statusLineOutput":    "base05",
+	"statusLineCost":      "base09",
+	"statusLineSubagents": "base0C",
+}
+
+// base24Colors overrides base16Colors when the scheme has the base24 slots:
+// base10 and base11 are darker backgrounds, base12-base17 bright red,
+// yellow, green, cyan, blue and magenta.
+var base24Colors = map[string]string{
+	"statusLineBg":  "base10",
+	"thinkingXhigh": "base12",
+	"mdLink":        "base15",
+}
+
+var base16SlotPattern = regexp.MustCompile(`^base[0-1][0-9A-F]$`)
+
+// base16Scheme holds both the classic flat layout ("scheme", "base00") and
+// the tinted-theming layout ("name", "palette").
+type base16Scheme struct {
+	Scheme  string            `yaml:"scheme"`
+	Name    string            `yaml:"name"`
+	Author  string            `yaml:"author"`
+	Palette map[string]string `yaml:"palette"`
+	Slots   map[string]string `yaml:",inline"`
+}
+
+// ImportBase16 builds a theme from a base16 or base24 YAML scheme, in the
+// classic flat layout or the tinted-theming "palette" layout. The slots
+// become vars so the theme stays easy to tweak. name overrides the theme
+// name, which otherwise is the scheme name in kebab case.
+func ImportBase16(data []byte, name string) (*ThemeFile, error) {
+	var scheme base16Scheme
+	if err := yaml.Unmarshal(data, &scheme); err != nil {
+		return nil, fmt.Errorf("parse base16 scheme: %w", err)
+	}
+	slots := scheme.Palette
+	if slots == nil {
+		slots = scheme.Slots
+	}
+
+	vars := make(map[string]any)
+	for k, v := range slots {
+		if len(k) == 6 {
+			k = "base" + strings.ToUpper(k[4:])
+		}
+		if !base16SlotPattern.MatchString(k) {
+			continue
+		}
+		hex := "#" + strings.ToLower(strings.TrimPrefix(strings.TrimSpace(v), "#"))
+		if _, _, _, err := parseHexRGB(hex); err != nil {
+			return nil, fmt.Errorf("base16 scheme: %s: %q is not a hex color", k, v)
+		}
+		vars[k] = hex
+	}
+	for i := range 16 {
+		if slot := fmt.Sprintf("base%02X", i); vars[slot] == nil {
+			return nil, fmt.Errorf("base16 scheme: missing %s", slot)
+		}
+	}
+
+	colors := make(map[string]any, len(base16Colors))
+	for k, v := range base16Colors {
+		colors[k] = v
+	}
+	if vars["base17"] != nil {
+		for k, v := range base24Colors {
+			colors[k] = v
+		}
+	}
+
+	if name == "" {
+		name = kebabCase(cmp.Or(scheme.Name, scheme.Scheme))
+	}
+	if name == "" {
+		return nil, fmt.Errorf("base16 scheme: missing scheme name")
+	}
+	tf := &ThemeFile{
+		Name:   name,
+		Vars:   vars,
+		Colors: colors,
+		Export: &ThemeExport{PageBg: "base00", CardBg: "base01", InfoBg: "base02"},
+	}
+	if _, err := resolveThemeColors(tf.Colors, tf.Vars); err != nil {
+		return nil, err
+	}
+	return tf, nil
+}
+
+func kebabCase(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
@@ -1,7 +1,6 @@
 package style
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
 	"os"
@@ -9,16 +8,53 @@ import (
 	"strings"
 )
 
-// ColorMode selects truecolor vs 256-color ANSI output.
+// ColorMode selects how theme colors are written: truecolor, the xterm
+// 256-color palette, the 16 ANSI colors, or no color at all.
 type ColorMode string
 
 const (
 	ColorModeTruecolor ColorMode = "truecolor"
 	ColorMode256       ColorMode = "256color"
+	ColorMode16        ColorMode = "16color"
+	// ColorModeNone writes no colors; themes fall back to bold, dim, underline
+	// and inverse so important tokens stay distinguishable.
+	ColorModeNone ColorMode = "none"
 )
 
-// DetectColorMode mirrors the TypeScript terminal heuristic.
+// DetectColorMode mirrors the TypeScript terminal heuristic. FORCE_COLOR
+// (0-3) wins, then NO_COLOR and CLICOLOR=0 disable color; CLICOLOR_FORCE
+// keeps color on even for TERM=dumb.
 func DetectColorMode() ColorMode {
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(force) {
+		case "0", "false":
+			return ColorModeNone
+		case "2":
+			return ColorMode256
+		case "3":
+			return ColorModeTruecolor
+		}
+		if mode := detectTermColorMode(); mode != ColorModeNone {
+			return mode
+		}
+		return ColorMode16
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorModeNone
+	}
+	if f := os.Getenv("CLICOLOR_FORCE"); f != "" && f != "0" {
+		if mode := detectTermColorMode(); mode != ColorModeNone {
+			return mode
+		}
+		return ColorMode16
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return ColorModeNone
+	}
+	return detectTermColorMode()
+}
+
+func detectTermColorMode() ColorMode {
 	ct := os.Getenv("COLORTERM")
 	if ct == "truecolor" || ct == "24bit" {
 		return ColorModeTruecolor
@@ -27,7 +63,12 @@ func DetectColorMode() ColorMode {
 		return ColorModeTruecolor
 	}
 	term := os.Getenv("TERM")
-	if term == "dumb" || term == "" || term == "linux" {
+	switch {
+	case term == "dumb":
+		return ColorModeNone
+	case term == "linux", strings.HasSuffix(term, "-16color"):
+		return ColorMode16
+	case term == "", strings.Contains(term, "256color"):
 		return ColorMode256
 	}
 	return ColorModeTruecolor
@@ -41,42 +82,58 @@ type ResolvedColor struct {
 }
 
 func colorToFgANSI(c ResolvedColor, mode ColorMode) (string, error) {
+	if mode == ColorModeNone {
+		return "", nil
+	}
 	if c.IsIdx {
+		if mode == ColorMode16 {
+			return ansi16Fg(ansi16FromIndex(c.Index)), nil
+		}
 		return fmt.Sprintf("\x1b[38;5;%dm", c.Index), nil
 	}
 	if c.Hex == "" {
 		return "\x1b[39m", nil
 	}
 	if strings.HasPrefix(c.Hex, "#") {
-		if mode == ColorMode256 {
-			idx := hexTo256Approx(c.Hex)
-			return fmt.Sprintf("\x1b[38;5;%dm", idx), nil
-		}
 		r, g, b, err := parseHexRGB(c.Hex)
 		if err != nil {
 			return "", err
 		}
+		switch mode {
+		case ColorMode256:
+			return fmt.Sprintf("\x1b[38;5;%dm", hexTo256Approx(c.Hex)), nil
+		case ColorMode16:
+			return ansi16Fg(nearestANSI16(r, g, b)), nil
+		}
 		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b), nil
 	}
 	return "", fmt.Errorf("unsupported resolved color %q", c.Hex)
 }
 
 func colorToBgANSI(c ResolvedColor, mode ColorMode) (string, error) {
+	if mode == ColorModeNone {
+		return "", nil
+	}
 	if c.IsIdx {
+		if mode == ColorMode16 {
+			return ansi16Bg(ansi16FromIndex(c.Index)), nil
+		}
 		return fmt.Sprintf("\x1b[48;5;%dm", c.Index), nil
 	}
 	if c.Hex == "" {
 		return "\x1b[49m", nil
 	}
 	if strings.HasPrefix(c.Hex, "#") {
-		if mode == ColorMode256 {
-			idx := hexTo256Approx(c.Hex)
-			return fmt.Sprintf("\x1b[48;5;%dm", idx), nil
-		}
 		r, g, b, err := parseHexRGB(c.Hex)
 		if err != nil {
 			return "", err
 		}
+		switch mode {
+		case ColorMode256:
+			return fmt.Sprintf("\x1b[48;5;%dm", hexTo256Approx(c.Hex)), nil
+		case ColorMode16:
+			return ansi16Bg(nearestANSI16(r, g, b)), nil
+		}
 		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r, g, b), nil
 	}
 	return "", fmt.Errorf("unsupported resolved color %q", c.Hex)
@@ -125,56 +182,13 @@ func quantize6(v int) int {
 	return (v-35)/40 + 1
 }
 
-func resolveVarRefs(name string, vars map[string]any, visited map[string]bool) (ResolvedColor, error) {
-	if visited[name] {
-		return ResolvedColor{}, fmt.Errorf("circular variable reference: %s", name)
-	}
-	visited[name] = true
-	defer delete(visited, name)
-	v, ok := vars[name]
-	if !ok {
-		return ResolvedColor{}, fmt.Errorf("unknown variable %q", name)
-	}
-	return resolveColorValueDeep(v, vars, visited)
-}
-
-func resolveColorValueDeep(v any, vars map[string]any, visited map[string]bool) (ResolvedColor, error) {
-	switch x := v.(type) {
-	case string:
-		if x == "" || strings.HasPrefix(x, "#") {
-			return ResolvedColor{Hex: x}, nil
-		}
-		return resolveVarRefs(x, vars, visited)
-	case json.Number:
-		n, err := x.Int64()
-		if err != nil {
-			return ResolvedColor{}, err
-		}
-		if n < 0 || n > 255 {
-			return ResolvedColor{}, fmt.Errorf("color index out of range: %d", n)
-		}
-		return ResolvedColor{Index: int(n), IsIdx: true}, nil
-	case float64:
-		if x != math.Trunc(x) {
-			return ResolvedColor{}, fmt.Errorf("invalid color number %v", x)
-		}
-		n := int(x)
-		if n < 0 || n > 255 {
-			return ResolvedColor{}, fmt.Errorf("color index out of range: %d", n)
-		}
-		return ResolvedColor{Index: n, IsIdx: true}, nil
-	default:
-		return ResolvedColor{}, fmt.Errorf("unsupported color type %T", v)
-	}
-}
-
 func resolveThemeColors(colors map[string]any, vars map[string]any) (map[string]ResolvedColor, error) {
+	r := newColorResolver(vars, colors)
 	out := make(map[string]ResolvedColor, len(colors))
-	visited := make(map[string]bool)
-	for k, v := range colors {
-		rc, err := resolveColorValueDeep(v, vars, visited)
+	for k := range colors {
+		rc, err := r.ref("colors." + k)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", k, err)
+			return nil, err
 		}
 		out[k] = rc
 	}
@@ -203,6 +217,11 @@ func applyColorblindAdjustment(hex string) (string, error) {
 	return fmt.Sprintf("#%02x%02x%02x", clamp255(nr), clamp255(ng), clamp255(nb)), nil
 }
 
+// relativeLuminance is the WCAG relative luminance of an sRGB colour, 0 to 1.
+func relativeLuminance(r, g, b int) float64 {
+	return 0.2126*srgbToLinear(r) + 0.7152*srgbToLinear(g) + 0.0722*srgbToLinear(b)
+}
+
 func clamp255(x float64) int {
 	if x < 0 {
 		return 0
@@ -0,0 +1,356 @@
+package style
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ThemeColorError reports the theme key ("colors.accent", "vars.base") whose
+// value could not be resolved.
+type ThemeColorError struct {
+	Key string
+	Err error
+}
+
+func (e *ThemeColorError) Error() string { return e.Key + ": " + e.Err.Error() }
+
+func (e *ThemeColorError) Unwrap() error { return e.Err }
+
+// colorResolver resolves theme color values. A string value is a hex color,
+// "" for the terminal default, a color expression such as
+// "lighten(accent, 10%)", or the name of a var or, failing that, of another
+// color token.
+type colorResolver struct {
+	vars     map[string]any
+	colors   map[string]any
+	resolved map[string]ResolvedColor
+	stack    []string
+}
+
+func newColorResolver(vars, colors map[string]any) *colorResolver {
+	return &colorResolver{vars: vars, colors: colors, resolved: make(map[string]ResolvedColor)}
+}
+
+// name resolves a bare reference, looking in vars before colors.
+func (r *colorResolver) name(name string) (ResolvedColor, error) {
+	if _, ok := r.vars[name]; ok {
+		return r.ref("vars." + name)
+	}
+	if _, ok := r.colors[name]; ok {
+		return r.ref("colors." + name)
+	}
+	return ResolvedColor{}, fmt.Errorf("unknown variable or color %q", name)
+}
+
+// ref resolves the value stored at path, "vars.<name>" or "colors.<name>".
+// Errors name the innermost key that failed.
+func (r *colorResolver) ref(path string) (ResolvedColor, error) {
+	if rc, ok := r.resolved[path]; ok {
+		return rc, nil
+	}
+	if i := slices.Index(r.stack, path); i >= 0 {
+		cycle := append(slices.Clone(r.stack[i:]), path)
+		return ResolvedColor{}, &ThemeColorError{Key: path, Err: fmt.Errorf("circular reference: %s", strings.Join(cycle, " -> "))}
+	}
+	section, name, _ := strings.Cut(path, ".")
+	values := r.colors
+	if section == "vars" {
+		values = r.vars
+	}
+	v, ok := values[name]
+	if !ok {
+		return ResolvedColor{}, fmt.Errorf("unknown variable or color %q", name)
+	}
+
+	r.stack = append(r.stack, path)
+	rc, err := r.value(v)
+	r.stack = r.stack[:len(r.stack)-1]
+	if err != nil {
+		var keyErr *ThemeColorError
+		if errors.As(err, &keyErr) {
+			return ResolvedColor{}, keyErr
+		}
+		return ResolvedColor{}, &ThemeColorError{Key: path, Err: err}
+	}
+	r.resolved[path] = rc
+	return rc, nil
+}
+
+func (r *colorResolver) value(v any) (ResolvedColor, error) {
+	switch x := v.(type) {
+	case string:
+		switch {
+		case x == "" || strings.HasPrefix(x, "#"):
+			return ResolvedColor{Hex: x}, nil
+		case strings.Contains(x, "("):
+			expr, err := parseColorExpr(x)
+			if err != nil {
+				return ResolvedColor{}, err
+			}
+			c, err := r.eval(expr)
+			if err != nil {
+				return ResolvedColor{}, fmt.Errorf("%s: %w", x, err)
+			}
+			return ResolvedColor{Hex: c.hex()}, nil
+		}
+		return r.name(x)
+	case json.Number:
+		n, err := x.Int64()
+		if err != nil {
+			return ResolvedColor{}, err
+		}
+		return paletteColor(n)
+	case float64:
+		if x != math.Trunc(x) {
+			return ResolvedColor{}, fmt.Errorf("invalid color number %v", x)
+		}
+		return paletteColor(int64(x))
+	default:
+		return ResolvedColor{}, fmt.Errorf("unsupported color type %T", v)
+	}
+}
+
+func paletteColor(n int64) (ResolvedColor, error) {
+	if n < 0 || n > 255 {
+		return ResolvedColor{}, fmt.Errorf("color index out of range: %d", n)
+	}
+	return ResolvedColor{Index: int(n), IsIdx: true}, nil
+}
+
+// colorExpr is a parsed color expression: a function call, or an atom that
+// is a hex color, a number, a percentage or a reference.
+type colorExpr struct {
+	fn   string
+	args []colorExpr
+	atom string
+}
+
+type colorExprParser struct {
+	src string
+	pos int
+}
+
+func parseColorExpr(s string) (colorExpr, error) {
+	p := &colorExprParser{src: s}
+	e, err := p.parse()
+	if err != nil {
+		return colorExpr{}, err
+	}
+	p.skipSpace()
+	if p.pos < len(s) {
+		return colorExpr{}, p.errorf("unexpected %q", s[p.pos:])
+	}
+	return e, nil
+}
+
+func (p *colorExprParser) parse() (colorExpr, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t(),", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	atom := p.src[start:p.pos]
+	if atom == "" {
+		return colorExpr{}, p.errorf("expected a value")
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '(' {
+		return colorExpr{atom: atom}, nil
+	}
+	p.pos++
+	call := colorExpr{fn: atom}
+	for {
+		arg, err := p.parse()
+		if err != nil {
+			return colorExpr{}, err
+		}
+		call.args = append(call.args, arg)
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return colorExpr{}, p.errorf("missing )")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return call, nil
+		default:
+			return colorExpr{}, p.errorf("unexpected %q", p.src[p.pos])
+		}
+	}
+}
+
+func (p *colorExprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *colorExprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid color expression %q at column %d: %s", p.src, p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// eval evaluates a color expression in OKLab, so lightness steps and mixes
+// look even across hues.
+func (r *colorResolver) eval(e colorExpr) (oklab, error) {
+	if e.fn == "" {
+		return r.color(e)
+	}
+	arity := func(lo, hi int) error {
+		if len(e.args) < lo || len(e.args) > hi {
+			if lo == hi {
+				return fmt.Errorf("%s takes %d arguments, got %d", e.fn, lo, len(e.args))
+			}
+			return fmt.Errorf("%s takes %d to %d arguments, got %d", e.fn, lo, hi, len(e.args))
+		}
+		return nil
+	}
+	switch e.fn {
+	case "lighten", "darken":
+		if err := arity(2, 2); err != nil {
+			return oklab{}, err
+		}
+		c, err := r.color(e.args[0])
+		if err != nil {
+			return oklab{}, err
+		}
+		amount, err := exprAmount(e.args[1])
+		if err != nil {
+			return oklab{}, err
+		}
+		if e.fn == "darken" {
+			amount = -amount
+		}
+		c.L = math.Max(0, math.Min(1, c.L+amount))
+		return c, nil
+	case "mix", "alpha":
+		if err := arity(2, 3); err != nil {
+			return oklab{}, err
+		}
+		if e.fn == "mix" && len(e.args) == 2 {
+			e.args = append(e.args, colorExpr{atom: "0.5"})
+		}
+		a, err := r.color(e.args[0])
+		if err != nil {
+			return oklab{}, err
+		}
+		var b oklab
+		var weight float64
+		if e.fn == "mix" {
+			// mix(a, b, weight): weight is how much of b.
+			if b, err = r.color(e.args[1]); err != nil {
+				return oklab{}, err
+			}
+			weight, err = exprAmount(e.args[2])
+		} else {
+			// alpha(color, opacity[, background]) composites color over the
+			// background, by default the "background" var or color.
+			bg := colorExpr{atom: "background"}
+			if len(e.args) == 3 {
+				bg = e.args[2]
+			}
+			b = a
+			if a, err = r.color(bg); err != nil {
+				return oklab{}, err
+			}
+			weight, err = exprAmount(e.args[1])
+		}
+		if err != nil {
+			return oklab{}, err
+		}
+		return oklab{
+			L: a.L + (b.L-a.L)*weight,
+			A: a.A + (b.A-a.A)*weight,
+			B: a.B + (b.B-a.B)*weight,
+		}, nil
+	case "contrast":
+		// contrast(bg[, dark, light]) picks whichever candidate has the
+		// higher WCAG contrast ratio against bg, black or white by default.
+		if len(e.args) != 1 && len(e.args) != 3 {
+			return oklab{}, fmt.Errorf("contrast takes 1 or 3 arguments, got %d", len(e.args))
+		}
+		bg, err := r.color(e.args[0])
+		if err != nil {
+			return oklab{}, err
+		}
+		candidates := []oklab{toOKLab(0, 0, 0), toOKLab(255, 255, 255)}
+		for i, arg := range e.args[1:] {
+			if candidates[i], err = r.color(arg); err != nil {
+				return oklab{}, err
+			}
+		}
+		if contrastRatio(candidates[0], bg) >= contrastRatio(candidates[1], bg) {
+			return candidates[0], nil
+		}
+		return candidates[1], nil
+	default:
+		return oklab{}, fmt.Errorf("unknown color function %q", e.fn)
+	}
+}
+
+// color evaluates an expression that must produce a color.
+func (r *colorResolver) color(e colorExpr) (oklab, error) {
+	if e.fn != "" {
+		return r.eval(e)
+	}
+	if strings.HasPrefix(e.atom, "#") {
+		red, green, blue, err := parseHexRGB(e.atom)
+		if err != nil {
+			return oklab{}, err
+		}
+		return toOKLab(red, green, blue), nil
+	}
+	if _, err := exprAmount(e); err == nil {
+		return oklab{}, fmt.Errorf("expected a color, got %s", e.atom)
+	}
+	rc, err := r.name(e.atom)
+	if err != nil {
+		return oklab{}, err
+	}
+	hex := rc.Hex
+	if rc.IsIdx {
+		hex = Ansi256ToHex(rc.Index)
+	}
+	if hex == "" {
+		return oklab{}, fmt.Errorf("%s is the terminal default color and has no value", e.atom)
+	}
+	red, green, blue, err := parseHexRGB(hex)
+	if err != nil {
+		return oklab{}, err
+	}
+	return toOKLab(red, green, blue), nil
+}
+
+// exprAmount parses a fraction between 0 and 1, written as "0.3" or "30%".
+func exprAmount(e colorExpr) (float64, error) {
+	if e.fn != "" {
+		return 0, fmt.Errorf("expected an amount, got %s(...)", e.fn)
+	}
+	text, percent := strings.CutSuffix(e.atom, "%")
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected an amount, got %s", e.atom)
+	}
+	if percent {
+		v /= 100
+	}
+	if v < 0 || v > 1 {
+		return 0, fmt.Errorf("amount %s is outside 0 to 1", e.atom)
+	}
+	return v, nil
+}
+
+// contrastRatio is the WCAG contrast ratio between two colors, 1 to 21.
+func contrastRatio(a, b oklab) float64 {
+	la, lb := a.luminance(), b.luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
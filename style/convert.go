@@ -0,0 +1,359 @@
+package style
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ThemeExportFormat names a terminal or editor color scheme format.
+type ThemeExportFormat string
+
+const (
+	ThemeFormatAlacritty ThemeExportFormat = "alacritty" // TOML, alacritty.toml [colors]
+	ThemeFormatKitty     ThemeExportFormat = "kitty"     // kitty.conf color settings
+	ThemeFormatWezTerm   ThemeExportFormat = "wezterm"   // TOML color scheme file
+	ThemeFormatITerm2    ThemeExportFormat = "iterm2"    // .itermcolors property list
+	ThemeFormatVSCode    ThemeExportFormat = "vscode"    // color theme JSON
+)
+
+// ThemeExportFormats lists the formats supported by [ExportTheme].
+var ThemeExportFormats = []ThemeExportFormat{
+	ThemeFormatAlacritty, ThemeFormatKitty, ThemeFormatWezTerm, ThemeFormatITerm2, ThemeFormatVSCode,
+}
+
+// TerminalPalette is a terminal color scheme derived from a theme. Colors are
+// "#rrggbb".
+type TerminalPalette struct {
+	Name                string
+	Light               bool
+	Foreground          string
+	Background          string
+	Cursor              string
+	SelectionForeground string
+	SelectionBackground string
+	// ANSI holds black, red, green, yellow, blue, magenta, cyan and white,
+	// followed by their bright variants.
+	ANSI [16]string
+}
+
+// ansiSlotTokens are the theme tokens tried, in order, for the red to cyan
+// ANSI colors. A token is used when its hue is close to the slot's.
+var ansiSlotTokens = [6][]ThemeColor{
+	{ColorError, ColorToolDiffRemoved, ColorSyntaxVariable},
+	{ColorSuccess, ColorToolDiffAdded, ColorSyntaxString},
+	{ColorWarning, ColorSyntaxType, ColorSyntaxNumber},
+	{ColorSyntaxFunction, ColorMdLink, ColorAccent},
+	{ColorSyntaxKeyword, ColorCustomMessageLabel, ColorAccent},
+	{ColorMdLink, ColorSyntaxType, ColorAccent},
+}
+
+// maxSlotHueDistance is how far (radians) a theme color's hue may be from an
+// ANSI slot's hue and still fill it.
+const maxSlotHueDistance = math.Pi / 5
+
+// brightLightness is the OKLab lightness added for the bright ANSI colors.
+const brightLightness = 0.08
+
+// ThemeTerminalPalette derives a terminal palette from a theme. The
+// background is export.pageBg, else userMessageBg. Each ANSI hue comes from
+// the theme's semantic tokens (error for red, success for green, ...) or the
+// closest theme color by OKLab hue; hues the theme lacks are synthesized.
+func ThemeTerminalPalette(themeName string) (*TerminalPalette, error) {
+	p, _, err := themeTerminalPalette(themeName)
+	return p, err
+}
+
+func themeTerminalPalette(themeName string) (*TerminalPalette, map[string]ResolvedColor, error) {
+	if themeName == "" {
+		themeName = DefaultThemeName()
+	}
+	tf, err := loadThemeFileOrPath(themeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := newColorResolver(tf.Vars, tf.Colors)
+	resolved, err := resolveThemeColors(tf.Colors, tf.Vars)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bg := resolved[string(BgUserMessage)]
+	if tf.Export != nil && tf.Export.PageBg != nil {
+		if bg, err = r.value(tf.Export.PageBg); err != nil {
+			return nil, nil, fmt.Errorf("export.pageBg: %w", err)
+		}
+	}
+	p := &TerminalPalette{Name: tf.Name, Background: strings.ToLower(resolvedToCSS(bg, "#000000"))}
+	background, _ := hexOKLab(p.Background)
+	p.Light = background.luminance() > 0.179
+
+	defaultText := "#e5e5e7"
+	if p.Light {
+		defaultText = "#000000"
+	}
+	hex := func(key string) string { return strings.ToLower(resolvedToCSS(resolved[key], defaultText)) }
+	p.Foreground = hex(string(ColorText))
+	p.Cursor = hex(string(ColorAccent))
+	p.SelectionForeground = p.Foreground
+	p.SelectionBackground = strings.ToLower(resolvedToCSS(resolved[string(BgSelected)], p.Background))
+
+	if p.Light {
+		p.ANSI[0], p.ANSI[7], p.ANSI[8], p.ANSI[15] = p.Foreground, p.SelectionBackground, hex(string(ColorMuted)), p.Background
+	} else {
+		p.ANSI[0], p.ANSI[7], p.ANSI[8], p.ANSI[15] = p.SelectionBackground, hex(string(ColorMuted)), hex(string(ColorDim)), p.Foreground
+	}
+	// Hues the theme has no color for get the average lightness and
+	// chroma of the ones it has.
+	var missing []int
+	var sumL, sumC float64
+	for slot := range 6 {
+		hex, ok := paletteSlotColor(slot, resolved, defaultText)
+		if !ok {
+			missing = append(missing, slot)
+			continue
+		}
+		c, _ := hexOKLab(hex)
+		sumL, sumC = sumL+c.L, sumC+c.chroma()
+		p.ANSI[slot+1] = hex
+	}
+	for _, slot := range missing {
+		reference := ansiSlotReference(slot)
+		l, chroma := reference.L, reference.chroma()
+		if found := 6 - len(missing); found > 0 {
+			l, chroma = sumL/float64(found), sumC/float64(found)
+		}
+		h := reference.hue()
+		p.ANSI[slot+1] = oklab{L: l, A: chroma * math.Cos(h), B: chroma * math.Sin(h)}.hex()
+	}
+	for slot := range 6 {
+		c, _ := hexOKLab(p.ANSI[slot+1])
+		c.L = math.Min(1, c.L+brightLightness)
+		p.ANSI[slot+9] = c.hex()
+	}
+	return p, resolved, nil
+}
+
+// ansiSlotReference is the xterm bright color for an ANSI hue slot (0 is red).
+func ansiSlotReference(slot int) oklab {
+	c, _ := hexOKLab(Ansi256ToHex(slot + 9))
+	return c
+}
+
+// paletteSlotColor picks the theme color for an ANSI hue slot, if one is
+// close enough in hue.
+func paletteSlotColor(slot int, resolved map[string]ResolvedColor, defaultText string) (string, bool) {
+	reference := ansiSlotReference(slot)
+	hueDistance := func(c oklab) float64 {
+		d := math.Abs(c.hue() - reference.hue())
+		return math.Min(d, 2*math.Pi-d)
+	}
+	for _, token := range ansiSlotTokens[slot] {
+		hex := strings.ToLower(resolvedToCSS(resolved[string(token)], defaultText))
+		if c, ok := hexOKLab(hex); ok && c.chroma() >= greyChroma && hueDistance(c) <= maxSlotHueDistance {
+			return hex, true
+		}
+	}
+	keys := make([]string, 0, len(resolved))
+	for k := range resolved {
+		if ValidThemeColor(k) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	best, bestDist := "", maxSlotHueDistance
+	for _, k := range keys {
+		hex := strings.ToLower(resolvedToCSS(resolved[k], defaultText))
+		if c, ok := hexOKLab(hex); ok && c.chroma() >= greyChroma && hueDistance(c) < bestDist {
+			best, bestDist = hex, hueDistance(c)
+		}
+	}
+	return best, best != ""
+}
+
+func hexOKLab(hex string) (oklab, bool) {
+	r, g, b, err := parseHexRGB(hex)
+	if err != nil {
+		return oklab{}, false
+	}
+	return toOKLab(r, g, b), true
+}
+
+// ExportTheme converts a theme, by name or .json path, to a terminal or
+// editor color scheme.
+func ExportTheme(themeName string, format ThemeExportFormat) ([]byte, error) {
+	p, resolved, err := themeTerminalPalette(themeName)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ThemeFormatAlacritty:
+		return p.alacritty(), nil
+	case ThemeFormatKitty:
+		return p.kitty(), nil
+	case ThemeFormatWezTerm:
+		return p.wezterm(), nil
+	case ThemeFormatITerm2:
+		return p.iterm2(), nil
+	case ThemeFormatVSCode:
+		return p.vscode(resolved)
+	}
+	return nil, fmt.Errorf("unknown theme export format %q", format)
+}
+
+var ansiColorNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+func (p *TerminalPalette) alacritty() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s, exported from fasttui\n\n", p.Name)
+	fmt.Fprintf(&b, "[colors.primary]\nbackground = %q\nforeground = %q\n\n", p.Background, p.Foreground)
+	fmt.Fprintf(&b, "[colors.cursor]\ncursor = %q\ntext = %q\n\n", p.Cursor, p.Background)
+	fmt.Fprintf(&b, "[colors.selection]\nbackground = %q\ntext = %q\n", p.SelectionBackground, p.SelectionForeground)
+	for i, section := range []string{"normal", "bright"} {
+		fmt.Fprintf(&b, "\n[colors.%s]\n", section)
+		for j, name := range ansiColorNames {
+			fmt.Fprintf(&b, "%s = %q\n", name, p.ANSI[i*8+j])
+		}
+	}
+	return b.Bytes()
+}
+
+func (p *TerminalPalette) kitty() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s, exported from fasttui\n\n", p.Name)
+	fmt.Fprintf(&b, "foreground %s\nbackground %s\n", p.Foreground, p.Background)
+	fmt.Fprintf(&b, "cursor %s\ncursor_text_color %s\n", p.Cursor, p.Background)
+	fmt.Fprintf(&b, "selection_foreground %s\nselection_background %s\n\n", p.SelectionForeground, p.SelectionBackground)
+	for i, c := range p.ANSI {
+		fmt.Fprintf(&b, "color%d %s\n", i, c)
+	}
+	return b.Bytes()
+}
+
+func (p *TerminalPalette) wezterm() []byte {
+	quoted := func(colors []string) string {
+		parts := make([]string, len(colors))
+		for i, c := range colors {
+			parts[i] = fmt.Sprintf("%q", c)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "[colors]\nforeground = %q\nbackground = %q\n", p.Foreground, p.Background)
+	fmt.Fprintf(&b, "cursor_bg = %q\ncursor_border = %q\ncursor_fg = %q\n", p.Cursor, p.Cursor, p.Background)
+	fmt.Fprintf(&b, "selection_bg = %q\nselection_fg = %q\n", p.SelectionBackground, p.SelectionForeground)
+	fmt.Fprintf(&b, "ansi = %s\nbrights = %s\n\n", quoted(p.ANSI[:8]), quoted(p.ANSI[8:]))
+	fmt.Fprintf(&b, "[metadata]\nname = %q\n", p.Name)
+	return b.Bytes()
+}
+
+func (p *TerminalPalette) iterm2() []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	entry := func(key, hex string) {
+		r, g, bl, _ := parseHexRGB(hex)
+		fmt.Fprintf(&b, "\t<key>%s</key>\n\t<dict>\n", key)
+		fmt.Fprintf(&b, "\t\t<key>Alpha Component</key>\n\t\t<real>1</real>\n")
+		fmt.Fprintf(&b, "\t\t<key>Blue Component</key>\n\t\t<real>%.6f</real>\n", float64(bl)/255)
+		fmt.Fprintf(&b, "\t\t<key>Color Space</key>\n\t\t<string>sRGB</string>\n")
+		fmt.Fprintf(&b, "\t\t<key>Green Component</key>\n\t\t<real>%.6f</real>\n", float64(g)/255)
+		fmt.Fprintf(&b, "\t\t<key>Red Component</key>\n\t\t<real>%.6f</real>\n", float64(r)/255)
+		b.WriteString("\t</dict>\n")
+	}
+	for i, c := range p.ANSI {
+		entry(fmt.Sprintf("Ansi %d Color", i), c)
+	}
+	entry("Background Color", p.Background)
+	entry("Cursor Color", p.Cursor)
+	entry("Cursor Text Color", p.Background)
+	entry("Foreground Color", p.Foreground)
+	entry("Selected Text Color", p.SelectionForeground)
+	entry("Selection Color", p.SelectionBackground)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes()
+}
+
+// vscodeTokenScopes maps syntax tokens to TextMate scopes.
+var vscodeTokenScopes = []struct {
+	color ThemeColor
+	scope []string
+}{
+	{ColorSyntaxComment, []string{"comment", "punctuation.definition.comment"}},
+	{ColorSyntaxKeyword, []string{"keyword", "storage.type", "storage.modifier"}},
+	{ColorSyntaxFunction, []string{"entity.name.function", "support.function"}},
+	{ColorSyntaxVariable, []string{"variable", "meta.definition.variable"}},
+	{ColorSyntaxString, []string{"string"}},
+	{ColorSyntaxNumber, []string{"constant.numeric", "constant.language"}},
+	{ColorSyntaxType, []string{"entity.name.type", "support.type", "entity.name.class"}},
+	{ColorSyntaxOperator, []string{"keyword.operator"}},
+	{ColorSyntaxPunctuation, []string{"punctuation"}},
+}
+
+func (p *TerminalPalette) vscode(resolved map[string]ResolvedColor) ([]byte, error) {
+	fg := func(c ThemeColor) string { return strings.ToLower(resolvedToCSS(resolved[string(c)], p.Foreground)) }
+	bg := func(c ThemeBg) string { return strings.ToLower(resolvedToCSS(resolved[string(c)], p.Background)) }
+	type tokenColor struct {
+		Name     string            `json:"name"`
+		Scope    []string          `json:"scope"`
+		Settings map[string]string `json:"settings"`
+	}
+	kind := "dark"
+	if p.Light {
+		kind = "light"
+	}
+	workbench := map[string]string{
+		"editor.background":                        p.Background,
+		"editor.foreground":                        p.Foreground,
+		"editor.selectionBackground":               p.SelectionBackground,
+		"editorCursor.foreground":                  p.Cursor,
+		"editorLineNumber.foreground":              fg(ColorDim),
+		"editorLineNumber.activeForeground":        fg(ColorMuted),
+		"editorLink.activeForeground":              fg(ColorMdLink),
+		"focusBorder":                              fg(ColorBorderAccent),
+		"panel.border":                             fg(ColorBorder),
+		"statusBar.background":                     bg(BgStatusLine),
+		"statusBar.foreground":                     fg(ColorStatusLineModel),
+		"editorError.foreground":                   fg(ColorError),
+		"editorWarning.foreground":                 fg(ColorWarning),
+		"gitDecoration.addedResourceForeground":    fg(ColorToolDiffAdded),
+		"gitDecoration.deletedResourceForeground":  fg(ColorToolDiffRemoved),
+		"gitDecoration.modifiedResourceForeground": fg(ColorWarning),
+		"terminal.background":                      p.Background,
+		"terminal.foreground":                      p.Foreground,
+		"terminalCursor.foreground":                p.Cursor,
+		"terminal.selectionBackground":             p.SelectionBackground,
+	}
+	for i, c := range p.ANSI {
+		name := ansiColorNames[i%8]
+		name = strings.ToUpper(name[:1]) + name[1:]
+		if i >= 8 {
+			name = "Bright" + name
+		}
+		workbench["terminal.ansi"+name] = c
+	}
+	var tokens []tokenColor
+	for _, t := range vscodeTokenScopes {
+		tokens = append(tokens, tokenColor{
+			Name:     string(t.color),
+			Scope:    t.scope,
+			Settings: map[string]string{"foreground": fg(t.color)},
+		})
+	}
+	out, err := json.MarshalIndent(map[string]any{
+		"name":        p.Name,
+		"type":        kind,
+		"colors":      workbench,
+		"tokenColors": tokens,
+	}, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
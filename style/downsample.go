@@ -0,0 +1,179 @@
+package style
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// oklab is a color in the OKLab perceptual color space, where Euclidean
+// distance tracks perceived difference.
+type oklab struct {
+	L, A, B float64
+}
+
+func srgbToLinear(c int) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func toOKLab(r, g, b int) oklab {
+	lr, lg, lb := srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+	l := math.Cbrt(0.4122214708*lr + 0.5363325363*lg + 0.0514459929*lb)
+	m := math.Cbrt(0.2119034982*lr + 0.6806995451*lg + 0.1073969566*lb)
+	s := math.Cbrt(0.0883024619*lr + 0.2817188376*lg + 0.6299787005*lb)
+	return oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+func linearToSRGB(v float64) int {
+	if v <= 0.0031308 {
+		return clamp255(v * 12.92 * 255)
+	}
+	return clamp255((1.055*math.Pow(v, 1/2.4) - 0.055) * 255)
+}
+
+// rgb converts back to sRGB, clipping colors outside the sRGB gamut.
+func (c oklab) rgb() (r, g, b int) {
+	l := math.Pow(c.L+0.3963377774*c.A+0.2158037573*c.B, 3)
+	m := math.Pow(c.L-0.1055613458*c.A-0.0638541728*c.B, 3)
+	s := math.Pow(c.L-0.0894841775*c.A-1.2914855480*c.B, 3)
+	return linearToSRGB(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		linearToSRGB(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		linearToSRGB(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s)
+}
+
+func (c oklab) hex() string {
+	r, g, b := c.rgb()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// luminance is the WCAG relative luminance of the color.
+func (c oklab) luminance() float64 {
+	return relativeLuminance(c.rgb())
+}
+
+func (c oklab) chroma() float64 {
+	return math.Hypot(c.A, c.B)
+}
+
+func (c oklab) hue() float64 {
+	return math.Atan2(c.B, c.A)
+}
+
+// ansi16Palette is the 16-color palette in OKLab, using the same RGB values
+// as Ansi256ToHex.
+var ansi16Palette = func() [16]oklab {
+	var p [16]oklab
+	for i := range p {
+		r, g, b, _ := parseHexRGB(Ansi256ToHex(i))
+		p[i] = toOKLab(r, g, b)
+	}
+	return p
+}()
+
+// Tuning for nearestANSI16: colors below greyChroma map to the grey ramp,
+// and hueWeight scales squared hue distance (radians) against lightness.
+const (
+	greyChroma = 0.04
+	hueWeight  = 0.1
+)
+
+// nearestANSI16 returns the ANSI color (0-15) perceptually closest to r, g, b.
+// Plain OKLab distance pulls muted theme colors onto the palette's greys, so
+// near-neutral colors pick from black, white and the greys, and the rest match
+// hue first and lightness second in OKLCh.
+func nearestANSI16(r, g, b int) int {
+	target := toOKLab(r, g, b)
+	grey := target.chroma() < greyChroma
+	best, bestDist := 0, math.Inf(1)
+	for i, c := range ansi16Palette {
+		if grey != (c.chroma() < greyChroma) {
+			continue
+		}
+		dl := target.L - c.L
+		d := dl * dl
+		if !grey {
+			dh := math.Abs(target.hue() - c.hue())
+			if dh > math.Pi {
+				dh = 2*math.Pi - dh
+			}
+			d += hueWeight * dh * dh
+		}
+		if d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// ansi16FromIndex maps a 256-color index onto the 16 ANSI colors.
+func ansi16FromIndex(index int) int {
+	if index >= 0 && index < 16 {
+		return index
+	}
+	r, g, b, _ := parseHexRGB(Ansi256ToHex(index))
+	return nearestANSI16(r, g, b)
+}
+
+func ansi16Fg(index int) string {
+	if index < 8 {
+		return "\x1b[" + strconv.Itoa(30+index) + "m"
+	}
+	return "\x1b[" + strconv.Itoa(90+index-8) + "m"
+}
+
+func ansi16Bg(index int) string {
+	if index < 8 {
+		return "\x1b[" + strconv.Itoa(40+index) + "m"
+	}
+	return "\x1b[" + strconv.Itoa(100+index-8) + "m"
+}
+
+// emphasis is an SGR attribute standing in for a color in ColorModeNone.
+type emphasis struct {
+	on, off string
+}
+
+var (
+	emphasisBold      = emphasis{"\x1b[1m", "\x1b[22m"}
+	emphasisDim       = emphasis{"\x1b[2m", "\x1b[22m"}
+	emphasisUnderline = emphasis{"\x1b[4m", "\x1b[24m"}
+	emphasisInverse   = emphasis{"\x1b[7m", "\x1b[27m"}
+)
+
+// monochromeFg lists the foreground tokens that keep an emphasis without
+// color. Tokens not listed render as plain text.
+var monochromeFg = map[ThemeColor]emphasis{
+	ColorAccent:             emphasisBold,
+	ColorBorderAccent:       emphasisBold,
+	ColorError:              emphasisBold,
+	ColorWarning:            emphasisBold,
+	ColorCustomMessageLabel: emphasisBold,
+	ColorToolTitle:          emphasisBold,
+	ColorMdHeading:          emphasisBold,
+	ColorSyntaxKeyword:      emphasisBold,
+	ColorToolDiffAdded:      emphasisBold,
+	ColorStatusLineModel:    emphasisBold,
+	ColorMuted:              emphasisDim,
+	ColorDim:                emphasisDim,
+	ColorBorderMuted:        emphasisDim,
+	ColorThinkingText:       emphasisDim,
+	ColorSyntaxComment:      emphasisDim,
+	ColorToolDiffRemoved:    emphasisDim,
+	ColorMdLink:             emphasisUnderline,
+	ColorMdLinkURL:          emphasisUnderline,
+}
+
+// monochromeBg lists the background tokens that keep an emphasis without
+// color.
+var monochromeBg = map[ThemeBg]emphasis{
+	BgSelected:   emphasisInverse,
+	BgStatusLine: emphasisInverse,
+}
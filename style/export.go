@@ -3,13 +3,37 @@ package style
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
-// DetectTerminalBackground returns "dark" or "light" using COLORFGBG when present (simple heuristic).
+// terminalBackground holds the background kind reported by the terminal.
+var terminalBackground atomic.Value
+
+// SetTerminalBackground records the background reported by the terminal,
+// "dark" or "light", e.g. from an OSC 11 reply or a DEC mode 2031 colour
+// scheme notification. It takes precedence over COLORFGBG; "" clears it.
+func SetTerminalBackground(kind string) {
+	terminalBackground.Store(kind)
+}
+
+// BackgroundForColor classifies a background colour as "light" or "dark" by
+// its relative luminance. The threshold is where black and white text have
+// equal contrast against it.
+func BackgroundForColor(r, g, b uint8) string {
+	if relativeLuminance(int(r), int(g), int(b)) > 0.179 {
+		return "light"
+	}
+	return "dark"
+}
+
+// DetectTerminalBackground returns "dark" or "light": the background reported
+// by the terminal when known, else COLORFGBG when present (simple heuristic).
 func DetectTerminalBackground() string {
+	if kind, _ := terminalBackground.Load().(string); kind != "" {
+		return kind
+	}
 	colorfgbg := os.Getenv("COLORFGBG")
 	if colorfgbg != "" {
 		parts := strings.Split(colorfgbg, ";")
@@ -64,27 +88,15 @@ func IsLightTheme(themeName string) bool {
 	if themeName == "" {
 		themeName = "dark"
 	}
-	var tf *ThemeFile
-	var err error
-	if data, ok := BuiltinThemeJSON(themeName); ok {
-		tf, err = ParseThemeJSON(data)
-	} else {
-		path := filepath.Join(ThemesDir(), themeName+".json")
-		raw, rerr := os.ReadFile(path)
-		if rerr != nil {
-			return false
-		}
-		tf, err = ParseThemeJSON(raw)
-	}
-	if err != nil || tf == nil {
+	tf, err := LoadThemeFile(themeName)
+	if err != nil {
 		return false
 	}
 	v, ok := tf.Colors["userMessageBg"]
 	if !ok {
 		return false
 	}
-	visited := make(map[string]bool)
-	rc, err := resolveColorValueDeep(v, tf.Vars, visited)
+	rc, err := newColorResolver(tf.Vars, tf.Colors).value(v)
 	if err != nil {
 		return false
 	}
@@ -145,8 +157,7 @@ func exportResolveValue(v any, vars map[string]any) (*string, error) {
 		}
 		varName := strings.TrimPrefix(s, "$")
 		if _, has := vars[varName]; has {
-			visited := make(map[string]bool)
-			rc, err := resolveVarRefs(varName, vars, visited)
+			rc, err := newColorResolver(vars, nil).name(varName)
 			if err != nil {
 				return nil, err
 			}
@@ -155,8 +166,7 @@ func exportResolveValue(v any, vars map[string]any) (*string, error) {
 		}
 		return &s, nil
 	}
-	visited := make(map[string]bool)
-	rc, err := resolveColorValueDeep(v, vars, visited)
+	rc, err := newColorResolver(vars, nil).value(v)
 	if err != nil {
 		return nil, err
 	}
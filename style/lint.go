@@ -0,0 +1,412 @@
+package style
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WCAGAAContrast is the minimum WCAG AA contrast ratio for normal text.
+const WCAGAAContrast = 4.5
+
+// LintSeverity classifies a [LintIssue].
+type LintSeverity string
+
+const (
+	// LintError marks a theme that does not load.
+	LintError LintSeverity = "error"
+	// LintWarning marks a theme that loads but may be hard to read.
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is one problem found by [LintThemeJSON].
+type LintIssue struct {
+	Severity LintSeverity
+	// Path is the offending key, e.g. "colors.accent"; empty for the whole theme.
+	Path    string
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Path, i.Message)
+}
+
+// LintColorModes are the color modes [LintThemeJSON] checks contrast in by
+// default. [ColorModeNone] has no colors to check.
+var LintColorModes = []ColorMode{ColorModeTruecolor, ColorMode256, ColorMode16}
+
+// contrastPairs are the foreground tokens drawn on a background token.
+var contrastPairs = []struct {
+	fg ThemeColor
+	bg ThemeBg
+}{
+	{ColorUserMessageText, BgUserMessage},
+	{ColorCustomMessageText, BgCustomMessage},
+	{ColorText, BgSelected},
+	{ColorStatusLineModel, BgStatusLine},
+	{ColorStatusLinePath, BgStatusLine},
+	{ColorStatusLineGitClean, BgStatusLine},
+	{ColorStatusLineGitDirty, BgStatusLine},
+	{ColorStatusLineContext, BgStatusLine},
+	{ColorToolOutput, BgToolPending},
+	{ColorToolOutput, BgToolSuccess},
+	{ColorToolOutput, BgToolError},
+	{ColorToolDiffAdded, BgToolSuccess},
+	{ColorToolDiffRemoved, BgToolSuccess},
+	{ColorToolDiffContext, BgToolSuccess},
+	{ColorToolDiffAdded, BgToolError},
+	{ColorToolDiffRemoved, BgToolError},
+	{ColorToolDiffContext, BgToolError},
+}
+
+// LintTheme lints the theme stored under name, or the JSON file at name when
+// it ends in ".json".
+func LintTheme(name string, modes ...ColorMode) ([]LintIssue, error) {
+	data, err := readThemeJSONOrPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return LintThemeJSON(data, modes...), nil
+}
+
+// LintThemeJSON checks theme JSON against the embedded schema, suggests
+// names for unknown keys, resolves every color, and warns about fg/bg pairs
+// below [WCAGAAContrast] in each of modes (default [LintColorModes]).
+// Issues are sorted by path.
+func LintThemeJSON(data []byte, modes ...ColorMode) []LintIssue {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return []LintIssue{{Severity: LintError, Message: fmt.Sprintf("parse theme json: %v", err)}}
+	}
+	issues := themeSchema().validate(themeSchema().root, doc, "")
+	if slices.ContainsFunc(issues, func(issue LintIssue) bool { return issue.Severity == LintError }) {
+		return sortIssues(issues)
+	}
+
+	tf, err := ParseThemeJSON(data)
+	if err == nil {
+		tf, err = ResolveThemeExtends(tf)
+	}
+	var resolved map[string]ResolvedColor
+	if err == nil {
+		resolved, err = resolveThemeColors(tf.Colors, tf.Vars)
+	}
+	if err != nil {
+		issue := LintIssue{Severity: LintError, Message: err.Error()}
+		var keyErr *ThemeColorError
+		if errors.As(err, &keyErr) {
+			issue.Path, issue.Message = keyErr.Key, keyErr.Err.Error()
+		}
+		return []LintIssue{issue}
+	}
+
+	if len(modes) == 0 {
+		modes = LintColorModes
+	}
+	for _, pair := range contrastPairs {
+		var low []string
+		for _, mode := range modes {
+			fg, okFg := renderedRGB(resolved[string(pair.fg)], mode)
+			bg, okBg := renderedRGB(resolved[string(pair.bg)], mode)
+			if !okFg || !okBg {
+				continue
+			}
+			if ratio := contrastRatio(fg, bg); ratio < WCAGAAContrast {
+				low = append(low, fmt.Sprintf("%.2f:1 in %s", ratio, mode))
+			}
+		}
+		if len(low) > 0 {
+			issues = append(issues, LintIssue{
+				Severity: LintWarning,
+				Path:     "colors." + string(pair.fg),
+				Message:  fmt.Sprintf("contrast on %s is %s; WCAG AA needs %.1f:1", pair.bg, strings.Join(low, ", "), WCAGAAContrast),
+			})
+		}
+	}
+	return sortIssues(issues)
+}
+
+func sortIssues(issues []LintIssue) []LintIssue {
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+// renderedRGB returns the color a terminal shows for c in mode, assuming the
+// xterm palette. The terminal default color ("") is unknown.
+func renderedRGB(c ResolvedColor, mode ColorMode) (oklab, bool) {
+	hex := c.Hex
+	switch {
+	case mode == ColorModeNone:
+		return oklab{}, false
+	case c.IsIdx && mode == ColorMode16:
+		hex = Ansi256ToHex(ansi16FromIndex(c.Index))
+	case c.IsIdx:
+		hex = Ansi256ToHex(c.Index)
+	case hex == "":
+		return oklab{}, false
+	case mode == ColorMode256:
+		hex = Ansi256ToHex(hexTo256Approx(hex))
+	case mode == ColorMode16:
+		r, g, b, err := parseHexRGB(hex)
+		if err != nil {
+			return oklab{}, false
+		}
+		hex = Ansi256ToHex(nearestANSI16(r, g, b))
+	}
+	r, g, b, err := parseHexRGB(hex)
+	if err != nil {
+		return oklab{}, false
+	}
+	return toOKLab(r, g, b), true
+}
+
+// jsonSchema validates documents against the subset of JSON Schema used by
+// theme-schema.json: type, enum, minimum, maximum, properties, required,
+// additionalProperties, oneOf, not, if/then and local $ref. Properties marked
+// deprecated produce a warning.
+type jsonSchema struct {
+	root map[string]any
+}
+
+var themeSchema = sync.OnceValue(func() *jsonSchema {
+	data, err := themeEmbedded.ReadFile("theme/theme-schema.json")
+	if err != nil {
+		panic(err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		panic(err)
+	}
+	return &jsonSchema{root: root}
+})
+
+func (s *jsonSchema) validate(schema map[string]any, value any, path string) []LintIssue {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := s.resolveRef(ref)
+		if !ok {
+			return []LintIssue{{Severity: LintError, Path: path, Message: fmt.Sprintf("schema: unresolved $ref %q", ref)}}
+		}
+		return s.validate(target, value, path)
+	}
+	fail := func(format string, args ...any) []LintIssue {
+		return []LintIssue{{Severity: LintError, Path: path, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	if typ, ok := schema["type"].(string); ok && !jsonTypeMatches(typ, value) {
+		return fail("expected %s, got %s", typ, jsonTypeName(value))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, jsonScalar(value)) {
+		return fail("%v is not one of %v", jsonScalar(value), enum)
+	}
+	if n, ok := value.(json.Number); ok {
+		f, _ := n.Float64()
+		if lo, ok := schema["minimum"].(float64); ok && f < lo {
+			return fail("%v is below the minimum %v", n, lo)
+		}
+		if hi, ok := schema["maximum"].(float64); ok && f > hi {
+			return fail("%v is above the maximum %v", n, hi)
+		}
+	}
+
+	var issues []LintIssue
+	if branches, ok := schema["oneOf"].([]any); ok {
+		issues = append(issues, s.validateOneOf(branches, value, path)...)
+	}
+	if not, ok := schema["not"].(map[string]any); ok && len(s.validate(not, value, path)) == 0 {
+		issues = append(issues, fail("matches a schema it must not match")...)
+	}
+	if cond, ok := schema["if"].(map[string]any); ok && len(s.validate(cond, value, path)) == 0 {
+		if then, ok := schema["then"].(map[string]any); ok {
+			issues = append(issues, s.validate(then, value, path)...)
+		}
+	}
+	if obj, ok := value.(map[string]any); ok {
+		issues = append(issues, s.validateObject(schema, obj, path)...)
+	}
+	return issues
+}
+
+func (s *jsonSchema) validateObject(schema map[string]any, obj map[string]any, path string) []LintIssue {
+	var issues []LintIssue
+	props, _ := schema["properties"].(map[string]any)
+	if required, ok := schema["required"].([]any); ok {
+		var missing []string
+		for _, r := range required {
+			if name, _ := r.(string); name != "" {
+				if _, ok := obj[name]; !ok {
+					missing = append(missing, name)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			issues = append(issues, LintIssue{Severity: LintError, Path: path, Message: "missing required keys: " + strings.Join(missing, ", ")})
+		}
+	}
+	for key, v := range obj {
+		child := key
+		if path != "" {
+			child = path + "." + key
+		}
+		if prop, ok := props[key].(map[string]any); ok {
+			if deprecated, _ := prop["deprecated"].(bool); deprecated {
+				msg := "deprecated key"
+				if desc, _ := prop["description"].(string); desc != "" {
+					msg += ": " + strings.TrimPrefix(desc, "Deprecated: ")
+				}
+				issues = append(issues, LintIssue{Severity: LintWarning, Path: child, Message: msg})
+			}
+			issues = append(issues, s.validate(prop, v, child)...)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra && props != nil {
+				msg := fmt.Sprintf("unknown key %q", key)
+				if suggestion := closestKey(key, props); suggestion != "" {
+					msg += fmt.Sprintf("; did you mean %q?", suggestion)
+				}
+				issues = append(issues, LintIssue{Severity: LintError, Path: child, Message: msg})
+			}
+		case map[string]any:
+			issues = append(issues, s.validate(extra, v, child)...)
+		}
+	}
+	return issues
+}
+
+// validateOneOf requires exactly one branch to match. When none does, the
+// issues of a branch of the right type are more useful than a summary.
+func (s *jsonSchema) validateOneOf(branches []any, value any, path string) []LintIssue {
+	var matched int
+	var types []string
+	var closest []LintIssue
+	for _, b := range branches {
+		branch, _ := b.(map[string]any)
+		if typ, ok := branch["type"].(string); ok {
+			types = append(types, typ)
+			if jsonTypeMatches(typ, value) {
+				closest = s.validate(branch, value, path)
+				if len(closest) == 0 {
+					matched++
+				}
+				continue
+			}
+		}
+		if len(s.validate(branch, value, path)) == 0 {
+			matched++
+		}
+	}
+	switch {
+	case matched == 1:
+		return nil
+	case matched > 1:
+		return []LintIssue{{Severity: LintError, Path: path, Message: "matches more than one allowed form"}}
+	case closest != nil:
+		return closest
+	}
+	return []LintIssue{{Severity: LintError, Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))}}
+}
+
+func (s *jsonSchema) resolveRef(ref string) (map[string]any, bool) {
+	rest, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+	var node any = s.root
+	for part := range strings.SplitSeq(rest, "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		node = m[part]
+	}
+	target, ok := node.(map[string]any)
+	return target, ok
+}
+
+func jsonTypeMatches(typ string, value any) bool {
+	switch typ {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+	return typ == jsonTypeName(value)
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonScalar makes numbers comparable with schema enums, which are decoded
+// without UseNumber.
+func jsonScalar(value any) any {
+	if n, ok := value.(json.Number); ok {
+		f, _ := n.Float64()
+		return f
+	}
+	return value
+}
+
+// closestKey returns the known key nearest to key by edit distance, or "" if
+// none is close enough to be a likely typo.
+func closestKey(key string, known map[string]any) string {
+	best, bestDist := "", len(key)/3+2
+	for k := range known {
+		d := editDistance(strings.ToLower(key), strings.ToLower(k))
+		if d < bestDist || (d == bestDist && best != "" && k < best) {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
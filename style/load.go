@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // ThemesDir returns the directory for user-defined themes (override with FASTTUI_THEMES_DIR).
@@ -20,20 +22,57 @@ func ThemesDir() string {
 	return filepath.Join(dir, "fasttui", "themes")
 }
 
-// LoadThemeFile loads a theme by name from embedded builtins first, then ThemesDir.
+// LoadThemeFile loads a theme by name from embedded builtins first, then
+// ThemesDir, merged over the theme it extends.
 func LoadThemeFile(name string) (*ThemeFile, error) {
-	if data, ok := BuiltinThemeJSON(name); ok {
-		return ParseThemeJSON(data)
+	return loadThemeFile(name, nil)
+}
+
+func loadThemeFile(name string, chain []string) (*ThemeFile, error) {
+	data, err := readThemeJSON(name)
+	if err != nil {
+		return nil, err
 	}
-	path := filepath.Join(ThemesDir(), name+".json")
-	data, err := os.ReadFile(path)
+	tf, err := ParseThemeJSON(data)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("theme not found: %s", name)
-		}
 		return nil, err
 	}
-	return ParseThemeJSON(data)
+	return resolveThemeExtends(tf, append(chain, name))
+}
+
+// loadThemeFileOrPath is LoadThemeFile, except that a name ending in ".json"
+// is loaded from that file.
+func loadThemeFileOrPath(name string) (*ThemeFile, error) {
+	data, err := readThemeJSONOrPath(name)
+	if err != nil {
+		return nil, err
+	}
+	tf, err := ParseThemeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return resolveThemeExtends(tf, []string{name})
+}
+
+// readThemeJSONOrPath reads the file at name when it ends in ".json", and
+// the theme stored under name otherwise.
+func readThemeJSONOrPath(name string) ([]byte, error) {
+	if strings.HasSuffix(name, ".json") {
+		return os.ReadFile(name)
+	}
+	return readThemeJSON(name)
+}
+
+// readThemeJSON returns the JSON of a builtin theme, or of one in ThemesDir.
+func readThemeJSON(name string) ([]byte, error) {
+	if data, ok := BuiltinThemeJSON(name); ok {
+		return data, nil
+	}
+	data, err := os.ReadFile(filepath.Join(ThemesDir(), name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("theme not found: %s", name)
+	}
+	return data, err
 }
 
 // LoadTheme is shorthand for [LoadThemeFile] + [NewTheme] with options.
@@ -71,3 +110,55 @@ func ListThemeNames() ([]string, error) {
 	sort.Strings(out)
 	return out, nil
 }
+
+// DefaultThemeWatchInterval is how often [WatchThemesDir] polls for changes.
+const DefaultThemeWatchInterval = 500 * time.Millisecond
+
+// WatchThemesDir polls [ThemesDir] every interval and calls onChange with the
+// theme name of each *.json file that was added or modified. onChange runs on
+// the watcher goroutine. Call the returned function to stop watching.
+func WatchThemesDir(interval time.Duration, onChange func(name string)) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultThemeWatchInterval
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		seen := themeFileModTimes()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			current := themeFileModTimes()
+			for name, mod := range current {
+				if prev, ok := seen[name]; !ok || !prev.Equal(mod) {
+					onChange(name)
+				}
+			}
+			seen = current
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+func themeFileModTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+	entries, err := os.ReadDir(ThemesDir())
+	if err != nil {
+		return times
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			times[name] = info.ModTime()
+		}
+	}
+	return times
+}
@@ -3,6 +3,7 @@ package style
 import (
 	"fmt"
 	"maps"
+	"strconv"
 	"strings"
 )
 
@@ -39,13 +40,20 @@ func WithColorBlindMode(on bool) ThemeOption {
 type Theme struct {
 	fg      map[string]string
 	bg      map[string]string
+	fgOff   map[string]string // resets for monochrome emphasis, keyed like fg
+	bgOff   map[string]string
 	mode    ColorMode
 	preset  SymbolPreset
 	symbols map[string]string
 }
 
-// NewTheme builds a [Theme] from validated theme data.
+// NewTheme builds a [Theme] from validated theme data, resolving Extends
+// first if set.
 func NewTheme(tf *ThemeFile, opts ...ThemeOption) (*Theme, error) {
+	tf, err := ResolveThemeExtends(tf)
+	if err != nil {
+		return nil, err
+	}
 	var cfg ThemeConfig
 	for _, o := range opts {
 		o(&cfg)
@@ -68,6 +76,8 @@ func NewTheme(tf *ThemeFile, opts ...ThemeOption) (*Theme, error) {
 	}
 	fg := make(map[string]string)
 	bg := make(map[string]string)
+	fgOff := make(map[string]string)
+	bgOff := make(map[string]string)
 	for key, rc := range resolved {
 		if _, isBg := themeBgKeySet[key]; isBg {
 			s, err := colorToBgANSI(rc, mode)
@@ -75,6 +85,10 @@ func NewTheme(tf *ThemeFile, opts ...ThemeOption) (*Theme, error) {
 				return nil, fmt.Errorf("bg %s: %w", key, err)
 			}
 			bg[key] = s
+			if mode == ColorModeNone {
+				e := monochromeBg[ThemeBg(key)]
+				bg[key], bgOff[key] = e.on, e.off
+			}
 			continue
 		}
 		if _, isFg := themeFgKeySet[key]; isFg {
@@ -83,6 +97,10 @@ func NewTheme(tf *ThemeFile, opts ...ThemeOption) (*Theme, error) {
 				return nil, fmt.Errorf("fg %s: %w", key, err)
 			}
 			fg[key] = s
+			if mode == ColorModeNone {
+				e := monochromeFg[ThemeColor(key)]
+				fg[key], fgOff[key] = e.on, e.off
+			}
 		}
 	}
 	preset := SymbolPresetUnicode
@@ -105,25 +123,33 @@ func NewTheme(tf *ThemeFile, opts ...ThemeOption) (*Theme, error) {
 		}
 	}
 	return &Theme{
-		fg: fg, bg: bg, mode: mode, preset: preset, symbols: symbols,
+		fg: fg, bg: bg, fgOff: fgOff, bgOff: bgOff, mode: mode, preset: preset, symbols: symbols,
 	}, nil
 }
 
 // Fg paints text with a foreground theme token and resets only the foreground color.
+// In [ColorModeNone] it applies the token's emphasis (bold, dim, underline), if any.
 func (t *Theme) Fg(color ThemeColor, text string) string {
 	ansi, ok := t.fg[string(color)]
 	if !ok {
 		panic(fmt.Sprintf("unknown theme color: %s", color))
 	}
+	if t.mode == ColorModeNone {
+		return ansi + text + t.fgOff[string(color)]
+	}
 	return ansi + text + "\x1b[39m"
 }
 
 // Bg paints text with a background theme token and resets only the background color.
+// In [ColorModeNone] selection-like backgrounds become inverse video.
 func (t *Theme) Bg(bg ThemeBg, text string) string {
 	ansi, ok := t.bg[string(bg)]
 	if !ok {
 		panic(fmt.Sprintf("unknown theme background: %s", bg))
 	}
+	if t.mode == ColorModeNone {
+		return ansi + text + t.bgOff[string(bg)]
+	}
 	return ansi + text + "\x1b[49m"
 }
 
@@ -142,6 +168,38 @@ func (t *Theme) Underline(text string) string {
 	return "\x1b[4m" + text + "\x1b[24m"
 }
 
+// UnderlineStyle is an underline style set with SGR 4:n.
+type UnderlineStyle int
+
+const (
+	UnderlineSingle UnderlineStyle = iota + 1
+	UnderlineDouble
+	UnderlineCurly
+	UnderlineDotted
+	UnderlineDashed
+)
+
+// StyledUnderline underlines text in the given style, colored with a theme
+// token (SGR 58), and resets with 24;59. In [ColorMode16] and
+// [ColorModeNone], where terminals rarely support either, it falls back to
+// a plain uncolored underline.
+func (t *Theme) StyledUnderline(style UnderlineStyle, color ThemeColor, text string) string {
+	if t.mode == ColorMode16 || t.mode == ColorModeNone {
+		return t.Underline(text)
+	}
+	code := "\x1b[4:" + strconv.Itoa(int(style)) + "m"
+	if params, ok := strings.CutPrefix(t.FgANSI(color), "\x1b[38;"); ok {
+		code += "\x1b[58;" + params
+	}
+	return code + text + "\x1b[24;59m"
+}
+
+// CurlyUnderline marks text with a curly underline in a theme color, as
+// editors do for spell-check (ColorWarning) and diagnostics (ColorError).
+func (t *Theme) CurlyUnderline(color ThemeColor, text string) string {
+	return t.StyledUnderline(UnderlineCurly, color, text)
+}
+
 // Strikethrough wraps text with ANSI strikethrough.
 func (t *Theme) Strikethrough(text string) string {
 	return "\x1b[9m" + text + "\x1b[29m"
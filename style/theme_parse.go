@@ -2,8 +2,11 @@ package style
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -11,6 +14,7 @@ import (
 type ThemeFile struct {
 	Schema  string         `json:"$schema,omitempty"`
 	Name    string         `json:"name"`
+	Extends string         `json:"extends,omitempty"`
 	Vars    map[string]any `json:"vars,omitempty"`
 	Colors  map[string]any `json:"colors"`
 	Export  *ThemeExport   `json:"export,omitempty"`
@@ -30,13 +34,15 @@ type ThemeSymbols struct {
 	Overrides map[string]string `json:"overrides,omitempty"`
 }
 
-// ParseThemeJSON validates and decodes theme JSON bytes.
+// ParseThemeJSON validates and decodes theme JSON bytes. A theme that extends
+// another may omit color tokens; [ResolveThemeExtends] fills them in.
 func ParseThemeJSON(data []byte) (*ThemeFile, error) {
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.UseNumber()
 	var raw struct {
 		Schema  string          `json:"$schema,omitempty"`
 		Name    string          `json:"name"`
+		Extends string          `json:"extends,omitempty"`
 		Vars    json.RawMessage `json:"vars,omitempty"`
 		Colors  json.RawMessage `json:"colors"`
 		Export  *ThemeExport    `json:"export,omitempty"`
@@ -55,18 +61,15 @@ func ParseThemeJSON(data []byte) (*ThemeFile, error) {
 	if err := json.Unmarshal(raw.Colors, &colors); err != nil {
 		return nil, fmt.Errorf("theme %q: colors: %w", raw.Name, err)
 	}
-	var missing []string
-	for _, k := range requiredThemeColorKeys {
-		if _, ok := colors[k]; !ok {
-			missing = append(missing, k)
+	if raw.Extends == "" {
+		if err := checkRequiredColors(raw.Name, colors); err != nil {
+			return nil, err
 		}
 	}
-	if len(missing) > 0 {
-		return nil, fmt.Errorf("theme %q: missing required color tokens: %s", raw.Name, strings.Join(missing, ", "))
-	}
 	tf := &ThemeFile{
 		Schema:  raw.Schema,
 		Name:    raw.Name,
+		Extends: raw.Extends,
 		Colors:  colors,
 		Export:  raw.Export,
 		Symbols: raw.Symbols,
@@ -85,3 +88,60 @@ func ParseThemeJSON(data []byte) (*ThemeFile, error) {
 	}
 	return tf, nil
 }
+
+func checkRequiredColors(name string, colors map[string]any) error {
+	var missing []string
+	for _, k := range requiredThemeColorKeys {
+		if _, ok := colors[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("theme %q: missing required color tokens: %s", name, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ResolveThemeExtends merges tf over the theme it extends, loaded with
+// [LoadThemeFile]: vars and colors are merged key by key, export and symbols
+// are inherited when tf has none. The result has no Extends.
+func ResolveThemeExtends(tf *ThemeFile) (*ThemeFile, error) {
+	return resolveThemeExtends(tf, []string{tf.Name})
+}
+
+// resolveThemeExtends merges tf over its parent. chain lists the themes being
+// loaded, ending with tf, to detect cycles.
+func resolveThemeExtends(tf *ThemeFile, chain []string) (*ThemeFile, error) {
+	if tf.Extends == "" {
+		return tf, nil
+	}
+	if slices.Contains(chain, tf.Extends) {
+		return nil, fmt.Errorf("theme %q: extends cycle: %s", tf.Name, strings.Join(append(chain, tf.Extends), " -> "))
+	}
+	parent, err := loadThemeFile(tf.Extends, chain)
+	if err != nil {
+		return nil, fmt.Errorf("theme %q: extends: %w", tf.Name, err)
+	}
+	merged := &ThemeFile{
+		Schema:  tf.Schema,
+		Name:    tf.Name,
+		Vars:    mergeThemeValues(parent.Vars, tf.Vars),
+		Colors:  mergeThemeValues(parent.Colors, tf.Colors),
+		Export:  cmp.Or(tf.Export, parent.Export),
+		Symbols: cmp.Or(tf.Symbols, parent.Symbols),
+	}
+	if err := checkRequiredColors(tf.Name, merged.Colors); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func mergeThemeValues(base, over map[string]any) map[string]any {
+	if len(base) == 0 && len(over) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(base)+len(over))
+	maps.Copy(out, base)
+	maps.Copy(out, over)
+	return out
+}
@@ -1,9 +1,13 @@
 package style
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseDarkBuiltin(t *testing.T) {
@@ -54,3 +58,377 @@ func TestExportColorsLight(t *testing.T) {
 		t.Fatalf("infoBg: %v", ec.InfoBg)
 	}
 }
+
+func TestDetectTerminalBackgroundPrefersReportedColor(t *testing.T) {
+	t.Setenv("COLORFGBG", "0;15")
+	t.Cleanup(func() { SetTerminalBackground("") })
+
+	if got := DetectTerminalBackground(); got != "light" {
+		t.Fatalf("COLORFGBG: got %q", got)
+	}
+	SetTerminalBackground(BackgroundForColor(0x28, 0x2c, 0x34))
+	if got := DefaultThemeName(); got != "dark" {
+		t.Fatalf("reported dark background: got %q", got)
+	}
+	if BackgroundForColor(0xfd, 0xf6, 0xe3) != "light" || BackgroundForColor(0x80, 0x80, 0x80) != "light" || BackgroundForColor(0x60, 0x60, 0x60) != "dark" {
+		t.Fatal("unexpected luminance classification")
+	}
+}
+
+func TestDetectColorModeEnv(t *testing.T) {
+	for _, key := range []string{"FORCE_COLOR", "NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "COLORTERM", "WT_SESSION"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	tests := []struct {
+		env  map[string]string
+		want ColorMode
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, ColorMode256},
+		{map[string]string{"TERM": "linux"}, ColorMode16},
+		{map[string]string{"TERM": "dumb"}, ColorModeNone},
+		{map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, ColorModeNone},
+		{map[string]string{"TERM": "xterm-256color", "CLICOLOR": "0"}, ColorModeNone},
+		{map[string]string{"TERM": "dumb", "FORCE_COLOR": "1"}, ColorMode16},
+		{map[string]string{"TERM": "dumb", "NO_COLOR": "1", "FORCE_COLOR": "3"}, ColorModeTruecolor},
+		{map[string]string{"TERM": "xterm-256color", "FORCE_COLOR": "0"}, ColorModeNone},
+	}
+	for _, tt := range tests {
+		for k, v := range tt.env {
+			t.Setenv(k, v)
+		}
+		if got := DetectColorMode(); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.env, got, tt.want)
+		}
+		for k := range tt.env {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestColorMode16NearestColor(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want int
+	}{
+		{"#000000", 0}, {"#ffffff", 15}, {"#e06c75", 9}, {"#98c379", 10}, {"#61afef", 12}, {"#5c6370", 8}, {"#1e1e2e", 0},
+		{"#e5c07b", 11}, {"#859900", 3},
+	}
+	for _, tt := range tests {
+		r, g, b, _ := parseHexRGB(tt.hex)
+		if got := nearestANSI16(r, g, b); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.hex, got, tt.want)
+		}
+	}
+	if s, _ := colorToFgANSI(ResolvedColor{Index: 196, IsIdx: true}, ColorMode16); s != "\x1b[91m" {
+		t.Errorf("index 196 -> %q", s)
+	}
+	if s, _ := colorToBgANSI(ResolvedColor{Hex: "#000080"}, ColorMode16); s != "\x1b[44m" {
+		t.Errorf("navy bg -> %q", s)
+	}
+}
+
+func TestMonochromeThemeEmphasis(t *testing.T) {
+	th, err := LoadTheme("dark", WithColorMode(ColorModeNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := th.Fg(ColorError, "x"); got != "\x1b[1mx\x1b[22m" {
+		t.Fatalf("error token: %q", got)
+	}
+	if got := th.Fg(ColorText, "x"); got != "x" {
+		t.Fatalf("plain token: %q", got)
+	}
+	if got := th.Bg(BgSelected, "x"); got != "\x1b[7mx\x1b[27m" {
+		t.Fatalf("selected bg: %q", got)
+	}
+}
+
+func TestWatchThemesDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FASTTUI_THEMES_DIR", dir)
+	changed := make(chan string, 4)
+	stop := WatchThemesDir(10*time.Millisecond, func(name string) { changed <- name })
+	defer stop()
+
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "custom.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case name := <-changed:
+		if name != "custom" {
+			t.Fatalf("changed %q", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("new theme file was not reported")
+	}
+}
+
+func TestThemeExtendsAndColorExpressions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FASTTUI_THEMES_DIR", dir)
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("tinted", `{
+		"name": "tinted",
+		"extends": "dark",
+		"vars": {"background": "#000000", "brand": "#3366cc"},
+		"colors": {
+			"accent": "brand",
+			"mdLink": "lighten(accent, 10%)",
+			"mdHr": "mix(#000000, #ffffff, 0.5)",
+			"selectedBg": "alpha(brand, 0)",
+			"mdCode": "contrast(#111111)"
+		}
+	}`)
+
+	theme, err := LoadTheme("tinted", WithColorMode(ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dark, err := LoadTheme("dark", WithColorMode(ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.FgANSI(ColorMdHeading) != dark.FgANSI(ColorMdHeading) {
+		t.Error("unset tokens should be inherited from dark")
+	}
+	colors, err := ResolvedThemeColors("tinted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if colors["accent"] != "#3366cc" || colors["mdCode"] != "#ffffff" || colors["selectedBg"] != "#000000" {
+		t.Errorf("colors = accent %s mdCode %s selectedBg %s", colors["accent"], colors["mdCode"], colors["selectedBg"])
+	}
+	if r, g, b, _ := parseHexRGB(colors["mdHr"]); r != g || g != b || r < 0x60 || r > 0x80 {
+		t.Errorf("perceptual mid grey = %s", colors["mdHr"])
+	}
+	if link, _, _, _ := parseHexRGB(colors["mdLink"]); link <= 0x33 {
+		t.Errorf("lighten(accent) = %s", colors["mdLink"])
+	}
+
+	write("loop-a", `{"name": "loop-a", "extends": "loop-b", "colors": {}}`)
+	write("loop-b", `{"name": "loop-b", "extends": "loop-a", "colors": {}}`)
+	if _, err := LoadThemeFile("loop-a"); err == nil || !strings.Contains(err.Error(), "loop-a -> loop-b -> loop-a") {
+		t.Errorf("extends cycle error = %v", err)
+	}
+
+	tests := []struct {
+		colors string
+		want   string
+	}{
+		{`"mdQuote": "mix(mdHr, #ffffff)", "mdHr": "darken(mdQuote, 5%)"`, "circular reference: colors.md"},
+		{`"mdQuote": "lighten(nosuch, 10%)"`, `colors.mdQuote: lighten(nosuch, 10%): unknown variable or color "nosuch"`},
+		{`"mdQuote": "lighten(accent, 150%)"`, "amount 150% is outside 0 to 1"},
+		{`"mdQuote": "mix(accent"`, "missing )"},
+		{`"mdQuote": "alpha(accent, 0.5)"`, `unknown variable or color "background"`},
+	}
+	for _, tt := range tests {
+		write("broken", `{"name": "broken", "extends": "dark", "colors": {`+tt.colors+`}}`)
+		_, err := LoadTheme("broken")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.colors, err, tt.want)
+		}
+	}
+}
+
+func TestLintThemeJSON(t *testing.T) {
+	for _, name := range BuiltinThemeNames() {
+		issues, err := LintTheme(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, issue := range issues {
+			if issue.Severity == LintError {
+				t.Errorf("%s: %s", name, issue)
+			}
+		}
+	}
+
+	lint := func(body string, modes ...ColorMode) []string {
+		var out []string
+		for _, issue := range LintThemeJSON([]byte(body), modes...) {
+			out = append(out, issue.String())
+		}
+		return out
+	}
+	got := lint(`{"name": "x", "extends": "dark", "colours": {}, "colors": {"acent": "#ffffff", "mdLink": 300}}`)
+	want := []string{
+		`error: colors.acent: unknown key "acent"; did you mean "accent"?`,
+		`error: colors.mdLink: 300 is above the maximum 255`,
+		`error: colours: unknown key "colours"; did you mean "colors"?`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("schema issues = %q", got)
+	}
+	if got := lint(`{"name": "x", "colors": {"accent": "#ffffff"}}`); len(got) != 1 || !strings.Contains(got[0], "missing required keys: border,") {
+		t.Errorf("missing keys = %q", got)
+	}
+	if got := lint(`{"name": "x", "extends": "dark", "colors": {"mdLink": "lighten(nosuch, 1%)"}}`); len(got) != 1 || !strings.HasPrefix(got[0], "error: colors.mdLink: ") {
+		t.Errorf("resolve error = %q", got)
+	}
+
+	got = lint(`{"name": "x", "extends": "dark", "colors": {"link": "#0088fa", "toolText": ""}}`, ColorModeTruecolor)
+	if !slices.Contains(got, `warning: colors.link: deprecated key: use mdLink`) ||
+		!slices.ContainsFunc(got, func(s string) bool { return strings.HasPrefix(s, "warning: colors.toolText: deprecated key") }) {
+		t.Errorf("deprecated keys not reported: %q", got)
+	}
+
+	got = lint(`{"name": "x", "extends": "dark", "colors": {"userMessageText": "#777777", "userMessageBg": "#555555"}}`, ColorModeTruecolor)
+	if !slices.ContainsFunc(got, func(s string) bool {
+		return strings.HasPrefix(s, "warning: colors.userMessageText: contrast on userMessageBg is 1.") && strings.HasSuffix(s, "in truecolor; WCAG AA needs 4.5:1")
+	}) {
+		t.Errorf("low contrast not reported: %q", got)
+	}
+}
+
+func TestExportTheme(t *testing.T) {
+	want := map[ThemeExportFormat]string{
+		ThemeFormatAlacritty: "[colors.primary]\nbackground = \"#18181e\"",
+		ThemeFormatKitty:     "background #18181e",
+		ThemeFormatWezTerm:   "background = \"#18181e\"",
+		ThemeFormatITerm2:    "<key>Background Color</key>",
+		ThemeFormatVSCode:    "\"terminal.background\": \"#18181e\"",
+	}
+	for _, format := range ThemeExportFormats {
+		out, err := ExportTheme("dark", format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !strings.Contains(string(out), want[format]) {
+			t.Errorf("%s export lacks %q:\n%s", format, want[format], out)
+		}
+	}
+	if _, err := ExportTheme("dark", "xterm"); err == nil {
+		t.Error("unknown format should fail")
+	}
+	path := filepath.Join(t.TempDir(), "custom.json")
+	if err := os.WriteFile(path, []byte(`{"name": "custom", "extends": "dark", "colors": {"error": "#ff0000"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := ExportTheme(path, ThemeFormatKitty); err != nil || !strings.Contains(string(out), "color1 #ff0000") {
+		t.Errorf("export from file = %v:\n%s", err, out)
+	}
+
+	p, err := ThemeTerminalPalette("light")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Light || p.ANSI[0] == p.Background {
+		t.Errorf("light palette = %+v", p)
+	}
+}
+
+func TestImportBase16(t *testing.T) {
+	base16 := `scheme: "Tomorrow Night"
+author: "Chris Kempson"
+base00: "1d1f21"
+base01: "282a2e"
+base02: "373b41"
+base03: "969896"
+base04: "b4b7b4"
+base05: "c5c8c6"
+base06: "e0e0e0"
+base07: "ffffff"
+base08: "cc6666"
+base09: "de935f"
+base0A: "f0c674"
+base0B: "b5bd68"
+base0C: "8abeb7"
+base0D: "81a2be"
+base0E: "b294bb"
+base0F: "a3685a"
+`
+	tf, err := ImportBase16([]byte(base16), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.Name != "tomorrow-night" || tf.Vars["base0D"] != "#81a2be" {
+		t.Errorf("imported %q with vars %v", tf.Name, tf.Vars)
+	}
+	data, err := json.Marshal(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range LintThemeJSON(data) {
+		if issue.Severity == LintError {
+			t.Error(issue)
+		}
+	}
+	theme, err := NewTheme(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := theme.FgANSI(ColorAccent); got != "\x1b[38;2;129;162;190m" {
+		t.Errorf("accent = %q", got)
+	}
+
+	base24 := `system: "base24"
+name: "Dracula"
+variant: "dark"
+palette:
+  base00: "#282a36"
+  base01: "#363447"
+  base02: "#44475a"
+  base03: "#6272a4"
+  base04: "#9ea8c7"
+  base05: "#f8f8f2"
+  base06: "#f0f1f4"
+  base07: "#ffffff"
+  base08: "#ff5555"
+  base09: "#ffb86c"
+  base0a: "#f1fa8c"
+  base0B: "#50fa7b"
+  base0C: "#8be9fd"
+  base0D: "#80bfff"
+  base0E: "#ff79c6"
+  base0F: "#bd93f9"
+  base10: "#1e2029"
+  base11: "#16171d"
+  base12: "#f28c8c"
+  base13: "#eef5a3"
+  base14: "#a3f5b8"
+  base15: "#baedf7"
+  base16: "#80bfff"
+  base17: "#ff91cf"
+`
+	tf, err = ImportBase16([]byte(base24), "drac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tf.Name != "drac" || tf.Colors["statusLineBg"] != "base10" || tf.Vars["base0A"] != "#f1fa8c" {
+		t.Errorf("base24 import = %q %v %v", tf.Name, tf.Colors["statusLineBg"], tf.Vars["base0A"])
+	}
+
+	missing := strings.Replace(base16, "base0F: \"a3685a\"\n", "", 1)
+	if _, err := ImportBase16([]byte(missing), ""); err == nil || !strings.Contains(err.Error(), "missing base0F") {
+		t.Errorf("missing slot error = %v", err)
+	}
+}
+
+func TestCurlyUnderline(t *testing.T) {
+	th, err := LoadTheme("dark", WithColorMode(ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := strings.TrimPrefix(th.FgANSI(ColorError), "\x1b[38;")
+	if got, want := th.CurlyUnderline(ColorError, "teh"), "\x1b[4:3m\x1b[58;"+params+"teh\x1b[24;59m"; got != want {
+		t.Errorf("curly underline = %q, want %q", got, want)
+	}
+	if got := th.StyledUnderline(UnderlineDotted, ColorWarning, "x"); !strings.HasPrefix(got, "\x1b[4:4m\x1b[58;2;") {
+		t.Errorf("dotted underline = %q", got)
+	}
+
+	th16, err := LoadTheme("dark", WithColorMode(ColorMode16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := th16.CurlyUnderline(ColorError, "teh"); got != "\x1b[4mteh\x1b[24m" {
+		t.Errorf("16-color curly underline = %q", got)
+	}
+}
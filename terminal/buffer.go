@@ -196,20 +196,21 @@ func (s *StdinBuffer) processCSI() bool {
 }
 
 func (s *StdinBuffer) processOSC() bool {
-	// OSC sequences: ESC ] ... (ESC \ or BEL)
-	if idx := strings.Index(s.buffer, ESC+"\\"); idx != -1 {
-		s.emitData(s.buffer[:idx+2])
-		s.buffer = s.buffer[idx+2:]
-		s.state = StateNormal
-		return true
-	}
+	// OSC sequences: ESC ] ... (ESC \ or BEL), whichever comes first
+	end := -1
 	if idx := strings.Index(s.buffer, "\x07"); idx != -1 {
-		s.emitData(s.buffer[:idx+1])
-		s.buffer = s.buffer[idx+1:]
-		s.state = StateNormal
-		return true
+		end = idx + 1
 	}
-	return false
+	if idx := strings.Index(s.buffer, ESC+"\\"); idx != -1 && (end == -1 || idx < end) {
+		end = idx + 2
+	}
+	if end == -1 {
+		return false
+	}
+	s.emitData(s.buffer[:end])
+	s.buffer = s.buffer[end:]
+	s.state = StateNormal
+	return true
 }
 
 func (s *StdinBuffer) processDCS() bool {
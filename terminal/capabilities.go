@@ -0,0 +1,260 @@
+package terminal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+// DefaultProbeTimeout is how long Start waits for the terminal to answer the
+// capability queries.
+const DefaultProbeTimeout = 500 * time.Millisecond
+
+// capabilityQueries are sent at Start. DA1 goes last: every terminal answers
+// it and replies arrive in order, so its reply ends the probe.
+const (
+	queryKittyKeyboard = "\x1b[?u"
+	queryCapabilities  = "\x1b[>0q" + // XTVERSION
+		"\x1b[>c" + // DA2
+		"\x1b[?2026$p" + // DECRQM synchronized output
+		"\x1b[?2027$p" + // DECRQM grapheme clustering
+		"\x1b[?2031$p" + // DECRQM colour scheme updates
+		"\x1b]10;?\x1b\\" + // foreground colour
+		"\x1b]11;?\x1b\\" + // background colour
+		"\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\" // Kitty graphics
+	queryDA1 = "\x1b[c"
+)
+
+var (
+	da2ResponsePattern      = regexp.MustCompile(`^\x1b\[>([\d;]*)c$`)
+	xtversionPattern        = regexp.MustCompile(`^\x1bP>\|(.*)\x1b\\$`)
+	decrpmPattern           = regexp.MustCompile(`^\x1b\[\?(\d+);(\d+)\$y$`)
+	oscColorPattern         = regexp.MustCompile(`^\x1b\](10|11);(.*?)(?:\x07|\x1b\\)$`)
+	kittyGraphicsPattern    = regexp.MustCompile(`^\x1b_Gi=31;(.*)\x1b\\$`)
+	colorSchemePattern      = regexp.MustCompile(`^\x1b\[\?997;([12])n$`)
+	rgbSpecComponentPattern = regexp.MustCompile(`^[0-9a-fA-F]{1,4}$`)
+)
+
+// ModeSupport is a DECRPM reply to a DECRQM mode query.
+type ModeSupport int
+
+const (
+	ModeNotRecognized ModeSupport = iota
+	ModeSet
+	ModeReset
+	ModePermanentlySet
+	ModePermanentlyReset
+)
+
+// Supported reports whether the mode is recognised and can be enabled.
+func (m ModeSupport) Supported() bool {
+	return m == ModeSet || m == ModeReset || m == ModePermanentlySet
+}
+
+// RGB is an 8-bit colour reported by the terminal.
+type RGB struct {
+	R, G, B uint8
+}
+
+// Capabilities are the terminal features discovered by the Start probe.
+type Capabilities struct {
+	// Responded reports that the terminal answered the DA1 query. When false
+	// the other fields are unknown rather than unsupported.
+	Responded bool
+	// PrimaryAttributes and SecondaryAttributes are the DA1 and DA2 parameters.
+	PrimaryAttributes   []int
+	SecondaryAttributes []int
+	// Version is the XTVERSION reply, e.g. "kitty(0.35.2)" or "WezTerm 20240203".
+	Version            string
+	SynchronizedOutput ModeSupport // DEC mode 2026
+	GraphemeClustering ModeSupport // DEC mode 2027
+	ColorSchemeUpdates ModeSupport // DEC mode 2031
+	// Foreground and Background are the OSC 10/11 replies, nil when unknown.
+	Foreground    *RGB
+	Background    *RGB
+	KittyKeyboard bool
+	KittyGraphics bool
+}
+
+// WithProbeTimeout sets how long Start waits for capability replies. Zero
+// skips the probe; the Kitty keyboard protocol is then never enabled.
+func WithProbeTimeout(timeout time.Duration) ProcessTerminalOption {
+	return func(p *ProcessTerminal) {
+		p.probeTimeout = timeout
+	}
+}
+
+// Capabilities returns what the Start probe found so far. Replies arriving
+// after the timeout are still recorded.
+func (p *ProcessTerminal) Capabilities() Capabilities {
+	p.capsMu.Lock()
+	defer p.capsMu.Unlock()
+	return p.caps
+}
+
+// SupportsSynchronizedOutput reports whether DEC mode 2026 may be used. It is
+// true unless the terminal answered the probe without recognising the mode.
+func (p *ProcessTerminal) SupportsSynchronizedOutput() bool {
+	caps := p.Capabilities()
+	return !caps.Responded || caps.SynchronizedOutput.Supported()
+}
+
+// startProbe sends the capability queries. Their replies are consumed by
+// handleProbeResponse until the DA1 reply arrives.
+func (p *ProcessTerminal) startProbe() {
+	p.setupStdinBuffer()
+	if p.probeTimeout <= 0 {
+		close(p.probeDone)
+		return
+	}
+	p.probing.Store(true)
+	query := queryCapabilities + queryDA1
+	if p.kittyFlags != 0 {
+		query = queryKittyKeyboard + query
+	}
+	p.print(query)
+}
+
+// waitProbe blocks until the probe finished or timed out.
+func (p *ProcessTerminal) waitProbe() {
+	select {
+	case <-p.probeDone:
+	case <-time.After(p.probeTimeout):
+	}
+}
+
+// handleProbeResponse records a reply to a capability query. It reports
+// whether seq was a reply and must not reach the input handler.
+func (p *ProcessTerminal) handleProbeResponse(seq string) bool {
+	p.capsMu.Lock()
+	defer p.capsMu.Unlock()
+
+	switch {
+	case kittyResponsePattern.MatchString(seq):
+		p.caps.KittyKeyboard = true
+		if p.kittyFlags != 0 && !p.isKittyProtocolActive {
+			p.enableKittyProtocol()
+		}
+	case da1ResponsePattern.MatchString(seq):
+		p.caps.Responded = true
+		p.caps.PrimaryAttributes = parseParams(seq[3 : len(seq)-1])
+		p.probing.Store(false)
+		if !p.isKittyProtocolActive && p.modifyOtherKeys {
+			p.modifyOtherKeysActive = true
+			p.enableKeyboardProtocol()
+		}
+		close(p.probeDone)
+	case da2ResponsePattern.MatchString(seq):
+		p.caps.SecondaryAttributes = parseParams(seq[3 : len(seq)-1])
+	case xtversionPattern.MatchString(seq):
+		p.caps.Version = xtversionPattern.FindStringSubmatch(seq)[1]
+	case decrpmPattern.MatchString(seq):
+		match := decrpmPattern.FindStringSubmatch(seq)
+		value, _ := strconv.Atoi(match[2])
+		switch match[1] {
+		case "2026":
+			p.caps.SynchronizedOutput = ModeSupport(value)
+		case "2027":
+			p.caps.GraphemeClustering = ModeSupport(value)
+		case "2031":
+			p.caps.ColorSchemeUpdates = ModeSupport(value)
+		}
+	case oscColorPattern.MatchString(seq):
+		match := oscColorPattern.FindStringSubmatch(seq)
+		if rgb, ok := parseColorSpec(match[2]); ok {
+			if match[1] == "10" {
+				p.caps.Foreground = &rgb
+			} else {
+				p.caps.Background = &rgb
+				style.SetTerminalBackground(style.BackgroundForColor(rgb.R, rgb.G, rgb.B))
+			}
+		}
+	case kittyGraphicsPattern.MatchString(seq):
+		p.caps.KittyGraphics = kittyGraphicsPattern.FindStringSubmatch(seq)[1] == "OK"
+	default:
+		return false
+	}
+	return true
+}
+
+func parseParams(s string) []int {
+	var params []int
+	for part := range strings.SplitSeq(s, ";") {
+		if n, err := strconv.Atoi(part); err == nil {
+			params = append(params, n)
+		}
+	}
+	return params
+}
+
+// parseColorSpec parses an X11 colour reply: "rgb:rrrr/gggg/bbbb" with one to
+// four hex digits per channel, or "#rrggbb".
+func parseColorSpec(spec string) (RGB, bool) {
+	if hex, ok := strings.CutPrefix(spec, "#"); ok && len(hex) == 6 {
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return RGB{}, false
+		}
+		return RGB{uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+	}
+	body, ok := strings.CutPrefix(spec, "rgb:")
+	if !ok {
+		body, ok = strings.CutPrefix(spec, "rgba:")
+	}
+	parts := strings.Split(body, "/")
+	if !ok || len(parts) < 3 {
+		return RGB{}, false
+	}
+	var channels [3]uint8
+	for i := range channels {
+		if !rgbSpecComponentPattern.MatchString(parts[i]) {
+			return RGB{}, false
+		}
+		v, _ := strconv.ParseUint(parts[i], 16, 16)
+		maxValue := uint64(1)<<(4*len(parts[i])) - 1
+		channels[i] = uint8((v*255 + maxValue/2) / maxValue)
+	}
+	return RGB{channels[0], channels[1], channels[2]}, true
+}
+
+// WithColorSchemeUpdates subscribes to the terminal's light/dark colour scheme
+// notifications (DEC mode 2031) so the background reported to
+// style.DetectTerminalBackground follows the system theme.
+func WithColorSchemeUpdates(enabled bool) ProcessTerminalOption {
+	return func(p *ProcessTerminal) {
+		p.colorSchemeUpdates = enabled
+	}
+}
+
+// OnColorSchemeChange registers fn to run with "dark" or "light" whenever the
+// terminal reports a colour scheme change. fn runs on the input goroutine.
+func (p *ProcessTerminal) OnColorSchemeChange(fn func(background string)) {
+	p.capsMu.Lock()
+	defer p.capsMu.Unlock()
+	p.colorSchemeHandler = fn
+}
+
+// handleColorSchemeReport consumes a DEC mode 2031 notification,
+// CSI ? 997 ; 1 n for dark or CSI ? 997 ; 2 n for light.
+func (p *ProcessTerminal) handleColorSchemeReport(seq string) bool {
+	match := colorSchemePattern.FindStringSubmatch(seq)
+	if match == nil {
+		return false
+	}
+	background := "dark"
+	if match[1] == "2" {
+		background = "light"
+	}
+	style.SetTerminalBackground(background)
+
+	p.capsMu.Lock()
+	handler := p.colorSchemeHandler
+	p.capsMu.Unlock()
+	if handler != nil {
+		handler(background)
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package terminal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+func TestCapabilitiesProbe(t *testing.T) {
+	p := NewProcessTerminal(WithModifyOtherKeys(false))
+	negotiate(t, p, "\x1bP>|kitty(0.35.2)\x1b\\"+
+		"\x1b[>1;4000;21c"+
+		"\x1b[?2026;2$y"+
+		"\x1b[?2027;0$y"+
+		"\x1b]10;rgb:dcdc/dfdf/e4e4\x1b\\"+
+		"\x1b]11;rgb:28/2c/34\x07"+
+		"\x1b_Gi=31;OK\x1b\\"+
+		"\x1b[?62;22;52c")
+
+	caps := p.Capabilities()
+	if !caps.Responded || caps.Version != "kitty(0.35.2)" || len(caps.PrimaryAttributes) != 3 || caps.SecondaryAttributes[1] != 4000 {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+	if !caps.SynchronizedOutput.Supported() || caps.GraphemeClustering.Supported() || !caps.KittyGraphics {
+		t.Fatalf("unexpected modes %+v", caps)
+	}
+	if *caps.Foreground != (RGB{0xdc, 0xdf, 0xe4}) || *caps.Background != (RGB{0x28, 0x2c, 0x34}) {
+		t.Fatalf("colours = %v %v", caps.Foreground, caps.Background)
+	}
+	select {
+	case <-p.probeDone:
+	default:
+		t.Fatal("DA1 reply should end the probe")
+	}
+}
+
+func TestSynchronizedOutputSupport(t *testing.T) {
+	p := NewProcessTerminal(WithModifyOtherKeys(false))
+	if !p.SupportsSynchronizedOutput() {
+		t.Fatal("unknown support should keep synchronized output on")
+	}
+	negotiate(t, p, "\x1b[?62c")
+	if p.SupportsSynchronizedOutput() {
+		t.Fatal("a terminal that ignored DECRQM 2026 should not get synchronized output")
+	}
+}
+
+func TestProbeTimeout(t *testing.T) {
+	p := NewProcessTerminal(WithProbeTimeout(20 * time.Millisecond))
+	start := time.Now()
+	p.waitProbe()
+	if time.Since(start) > time.Second || p.Capabilities().Responded {
+		t.Fatal("waitProbe should give up after the timeout")
+	}
+}
+
+func TestColorSchemeUpdates(t *testing.T) {
+	p := NewProcessTerminal(WithModifyOtherKeys(false), WithColorSchemeUpdates(true))
+	var reports []string
+	p.OnColorSchemeChange(func(background string) { reports = append(reports, background) })
+	negotiate(t, p, "\x1b]11;rgb:ffff/ffff/ffff\x1b\\\x1b[?62c\x1b[?997;1n")
+
+	if len(reports) != 1 || reports[0] != "dark" || style.DetectTerminalBackground() != "dark" {
+		t.Fatalf("reports = %v, background = %s", reports, style.DetectTerminalBackground())
+	}
+	p.handleColorSchemeReport("\x1b[?997;2n")
+	if style.DefaultThemeName() != "light" {
+		t.Fatal("light notification should switch the default theme")
+	}
+}
+
+func TestBackgroundColorFeedsDefaultTheme(t *testing.T) {
+	p := NewProcessTerminal(WithModifyOtherKeys(false))
+	negotiate(t, p, "\x1b]11;rgb:fdfd/f6f6/e3e3\x1b\\\x1b[?62c")
+	if style.DefaultThemeName() != "light" {
+		t.Fatalf("light background should pick the light theme")
+	}
+}
@@ -0,0 +1,83 @@
+package terminal
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/keys"
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+// negotiate runs the keyboard protocol query against the given terminal
+// replies and returns what was written to stdout.
+func negotiate(t *testing.T, p *ProcessTerminal, replies string) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		keys.SetKittyProtocolActive(false)
+		style.SetTerminalBackground("")
+	})
+
+	received := make(chan string, 10)
+	p.inputHandler = func(data string) { received <- data }
+	p.startProbe()
+	p.buffer.Process([]byte(replies + "x"))
+
+	select {
+	case data := <-received:
+		if data != "x" {
+			t.Fatalf("protocol reply leaked to input handler: %q", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("input was not forwarded")
+	}
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestKeyboardProtocolKitty(t *testing.T) {
+	p := NewProcessTerminal(WithKittyFlags(keys.KittyDisambiguate | keys.KittyReportAllKeys | keys.KittyReportText))
+	out := negotiate(t, p, "\x1b[?0u\x1b[?62;22c")
+
+	if !strings.HasPrefix(out, "\x1b[?u") || !strings.HasSuffix(out, "\x1b[>25u") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	got := p.KeyboardProtocol()
+	if got.Encoding != keys.EncodingKitty || got.KittyFlags != 25 || !keys.IsKittyProtocolActive() {
+		t.Fatalf("protocol = %+v", got)
+	}
+}
+
+func TestKeyboardProtocolModifyOtherKeysFallback(t *testing.T) {
+	p := NewProcessTerminal()
+	out := negotiate(t, p, "\x1b[?62;22c")
+
+	if !strings.HasSuffix(out, "\x1b[>4;2m") || strings.Contains(out, "\x1b[>7u") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if got := p.KeyboardProtocol(); got.Encoding != keys.EncodingModifyOtherKeys || p.IsKittyProtocolActive() {
+		t.Fatalf("protocol = %+v", got)
+	}
+}
+
+func TestKeyboardProtocolLegacy(t *testing.T) {
+	p := NewProcessTerminal(WithKittyFlags(0), WithModifyOtherKeys(false))
+	out := negotiate(t, p, "\x1b[?62;22c")
+	if strings.Contains(out, "\x1b[?u") || strings.Contains(out, "\x1b[>4;2m") {
+		t.Fatalf("no keyboard protocol should be negotiated, got %q", out)
+	}
+	if got := p.KeyboardProtocol(); got.Encoding != keys.EncodingLegacy {
+		t.Fatalf("protocol = %+v", got)
+	}
+}
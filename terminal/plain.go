@@ -0,0 +1,52 @@
+package terminal
+
+import (
+	"os"
+	"strings"
+)
+
+// DetectPlainMode reports whether the process should fall back to plain line output:
+// stdin or stdout is not a terminal (pipe, file, CI log) or TERM is "dumb".
+func DetectPlainMode() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return true
+	}
+	return !isTerminal(int(os.Stdout.Fd())) || !isTerminal(int(os.Stdin.Fd()))
+}
+
+// lineSplitter turns line-buffered stdin into input events.
+// Each complete line is delivered as a bracketed paste followed by enter,
+// so editors and inputs insert the text verbatim and then submit it.
+type lineSplitter struct {
+	pending strings.Builder
+	emit    func(data string)
+}
+
+func (l *lineSplitter) Process(data []byte) {
+	for _, b := range data {
+		if b != '\n' {
+			l.pending.WriteByte(b)
+			continue
+		}
+		l.flush()
+	}
+}
+
+// Close emits a trailing line that was not terminated by a newline before EOF.
+func (l *lineSplitter) Close() {
+	if l.pending.Len() > 0 {
+		l.flush()
+	}
+}
+
+func (l *lineSplitter) flush() {
+	line := strings.TrimSuffix(l.pending.String(), "\r")
+	l.pending.Reset()
+	if l.emit == nil {
+		return
+	}
+	if line != "" {
+		l.emit("\x1b[200~" + line + "\x1b[201~")
+	}
+	l.emit("\r")
+}
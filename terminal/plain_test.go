@@ -0,0 +1,24 @@
+package terminal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineSplitterEmitsPasteAndEnter(t *testing.T) {
+	var got []string
+	l := &lineSplitter{emit: func(data string) { got = append(got, data) }}
+
+	l.Process([]byte("hello wo"))
+	l.Process([]byte("rld\r\n\nlast"))
+	l.Close()
+
+	want := []string{
+		"\x1b[200~hello world\x1b[201~", "\r",
+		"\r",
+		"\x1b[200~last\x1b[201~", "\r",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
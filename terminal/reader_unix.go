@@ -0,0 +1,87 @@
+//go:build !windows
+
+package terminal
+
+import (
+	"io"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// stdinReader reads terminal input and can be interrupted from another
+// goroutine: Read waits in select(2) on stdin and a wake pipe, so a canceled
+// Read never consumes input meant for the next owner of the TTY. select is
+// used rather than poll because macOS poll does not support ttys.
+type stdinReader struct {
+	fd   int
+	wake [2]int
+}
+
+func newStdinReader(fd int) (*stdinReader, error) {
+	r := &stdinReader{fd: fd}
+	if err := unix.Pipe(r.wake[:]); err != nil {
+		return nil, err
+	}
+	for _, w := range r.wake {
+		syscall.CloseOnExec(w)
+		if err := unix.SetNonblock(w, true); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Read waits for input and reads it, or returns errReadCanceled once Cancel
+// is called.
+func (r *stdinReader) Read(buf []byte) (int, error) {
+	for {
+		var fds unix.FdSet
+		fds.Set(r.fd)
+		fds.Set(r.wake[0])
+		_, err := unix.Select(max(r.fd, r.wake[0])+1, &fds, nil, nil, nil)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if fds.IsSet(r.wake[0]) {
+			r.drain()
+			return 0, errReadCanceled
+		}
+		n, err := unix.Read(r.fd, buf)
+		switch {
+		case err == unix.EINTR || err == unix.EAGAIN:
+			continue
+		case err != nil:
+			return 0, err
+		case n == 0:
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+}
+
+// Cancel interrupts a pending or the next Read and reports true: the reader
+// can always be canceled.
+func (r *stdinReader) Cancel() bool {
+	unix.Write(r.wake[1], []byte{0})
+	return true
+}
+
+// drain discards wake-ups left over from a Cancel that found no Read.
+func (r *stdinReader) drain() {
+	var buf [16]byte
+	for {
+		if n, _ := unix.Read(r.wake[0], buf[:]); n <= 0 {
+			return
+		}
+	}
+}
+
+func (r *stdinReader) Close() {
+	unix.Close(r.wake[0])
+	unix.Close(r.wake[1])
+}
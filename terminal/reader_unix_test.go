@@ -0,0 +1,100 @@
+//go:build !windows
+
+package terminal
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStdinReaderCancel(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	reader, err := newStdinReader(int(r.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 16))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	reader.Cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, errReadCanceled) {
+			t.Fatalf("Read after Cancel = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cancel did not interrupt Read")
+	}
+
+	// Input written after the cancel is still there for the next reader
+	w.Write([]byte("x"))
+	buf := make([]byte, 16)
+	if n, err := reader.Read(buf); err != nil || string(buf[:n]) != "x" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+}
+
+// TestSuspendStopsReadingStdin checks that no input is consumed between
+// Suspend and Resume, so the shell or a child process gets every key.
+func TestSuspendStopsReadingStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	p := NewProcessTerminal(WithPlainMode(true))
+	p.stdinFD = int(r.Fd())
+	received := make(chan string, 10)
+	if err := p.Start(func(data string) { received <- data }, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Stop()
+
+	expect := func(want string) {
+		t.Helper()
+		for {
+			select {
+			case data := <-received:
+				if strings.Contains(data, want) {
+					return
+				}
+				if data != "\r" {
+					t.Fatalf("received %q, want %q", data, want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("input %q was not forwarded", want)
+			}
+		}
+	}
+	w.Write([]byte("before\n"))
+	expect("before")
+
+	if err := p.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("shell\n"))
+	buf := make([]byte, 16)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "shell\n" {
+		t.Fatalf("the shell read %q, %v", buf[:n], err)
+	}
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+	expect("after")
+}
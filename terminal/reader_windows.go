@@ -0,0 +1,37 @@
+//go:build windows
+
+package terminal
+
+import (
+	"io"
+
+	"golang.org/x/sys/windows"
+)
+
+// stdinReader reads console input. Windows console reads cannot be
+// interrupted, so a Read pending at Suspend still takes the next key.
+type stdinReader struct {
+	fd windows.Handle
+}
+
+func newStdinReader(fd int) (*stdinReader, error) {
+	return &stdinReader{fd: windows.Handle(fd)}, nil
+}
+
+func (r *stdinReader) Read(buf []byte) (int, error) {
+	var n uint32
+	if err := windows.ReadFile(r.fd, buf, &n, nil); err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return int(n), nil
+}
+
+// Cancel reports false: a pending console read cannot be interrupted.
+func (r *stdinReader) Cancel() bool { return false }
+
+func (r *stdinReader) drain() {}
+
+func (r *stdinReader) Close() {}
@@ -1,14 +1,24 @@
 package terminal
 
 import (
+	"errors"
 	"os"
 	"regexp"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/yeeaiclub/fasttui/keys"
 )
 
-var kittyResponsePattern = regexp.MustCompile(`^\x1b\[\?(\d+)u$`)
+// errReadCanceled is returned by stdinReader.Read after Cancel.
+var errReadCanceled = errors.New("terminal: read canceled")
+
+var (
+	kittyResponsePattern = regexp.MustCompile(`^\x1b\[\?(\d+)u$`)
+	da1ResponsePattern   = regexp.MustCompile(`^\x1b\[\?[\d;]*c$`)
+)
 
 type ProcessTerminal struct {
 	buffer                *StdinBuffer
@@ -16,6 +26,22 @@ type ProcessTerminal struct {
 	stdinFD               int
 	stdoutFD              int
 	isKittyProtocolActive bool
+	kittyFlags            keys.KittyFlags
+	modifyOtherKeys       bool
+	modifyOtherKeysActive bool
+	probeTimeout          time.Duration
+	probeDone             chan struct{}
+	probing               atomic.Bool
+	capsMu                sync.Mutex
+	caps                  Capabilities
+	colorSchemeUpdates    bool
+	colorSchemeHandler    func(background string)
+	plain                 bool
+	lines                 *lineSplitter
+	suspended             atomic.Bool
+	readMu                sync.Mutex
+	reader                *stdinReader
+	readDone              chan struct{}
 	inputHandler          func(data string)
 	stdinDataBuffer       func(data []byte)
 	wasRaw                bool
@@ -26,17 +52,61 @@ type ProcessTerminal struct {
 	stopResizeSignal      func()
 }
 
-func NewProcessTerminal() *ProcessTerminal {
+// ProcessTerminalOption configures a ProcessTerminal.
+type ProcessTerminalOption func(*ProcessTerminal)
+
+// WithPlainMode forces plain line mode on or off instead of relying on DetectPlainMode.
+func WithPlainMode(enabled bool) ProcessTerminalOption {
+	return func(p *ProcessTerminal) {
+		p.plain = enabled
+	}
+}
+
+// WithKittyFlags sets the Kitty keyboard protocol flags pushed when the
+// terminal supports it. Zero leaves the Kitty protocol off. The default is
+// keys.DefaultKittyFlags.
+func WithKittyFlags(flags keys.KittyFlags) ProcessTerminalOption {
+	return func(p *ProcessTerminal) {
+		p.kittyFlags = flags
+	}
+}
+
+// WithModifyOtherKeys enables or disables the xterm modifyOtherKeys mode 2
+// fallback used when the Kitty protocol is unavailable. Enabled by default.
+func WithModifyOtherKeys(enabled bool) ProcessTerminalOption {
+	return func(p *ProcessTerminal) {
+		p.modifyOtherKeys = enabled
+	}
+}
+
+func NewProcessTerminal(opts ...ProcessTerminalOption) *ProcessTerminal {
 	buffer := NewStdinBuffer()
-	return &ProcessTerminal{
+	p := &ProcessTerminal{
 		buffer:                buffer,
 		stdinFD:               int(os.Stdin.Fd()),
 		stdoutFD:              int(os.Stdout.Fd()),
 		isKittyProtocolActive: false,
+		kittyFlags:            keys.DefaultKittyFlags,
+		modifyOtherKeys:       true,
+		probeTimeout:          DefaultProbeTimeout,
+		probeDone:             make(chan struct{}),
+		plain:                 DetectPlainMode(),
 		wasRaw:                false,
 		resizeSignalChan:      make(chan os.Signal, 1),
 		stopChan:              make(chan struct{}),
 	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// IsPlain reports whether the terminal runs in plain line mode: no raw mode,
+// no cursor addressing, line-buffered input.
+func (p *ProcessTerminal) IsPlain() bool {
+	return p.plain
 }
 
 func (p *ProcessTerminal) GetSize() (int, int) {
@@ -51,10 +121,25 @@ func (p *ProcessTerminal) IsKittyProtocolActive() bool {
 	return p.isKittyProtocolActive
 }
 
+// KeyboardProtocol reports the keyboard encoding negotiated at Start.
+func (p *ProcessTerminal) KeyboardProtocol() keys.KeyboardProtocol {
+	switch {
+	case p.isKittyProtocolActive:
+		return keys.KeyboardProtocol{Encoding: keys.EncodingKitty, KittyFlags: p.kittyFlags}
+	case p.modifyOtherKeysActive:
+		return keys.KeyboardProtocol{Encoding: keys.EncodingModifyOtherKeys}
+	}
+	return keys.KeyboardProtocol{Encoding: keys.EncodingLegacy}
+}
+
 func (p *ProcessTerminal) Start(onInput func(data string), onResize func()) error {
 	p.inputHandler = onInput
 	p.resizeHandler = onResize
 
+	if p.plain {
+		return p.startPlain()
+	}
+
 	// Save previous state and enable raw mode on STDIN (not stdout!)
 	rawState, err := enableRawMode(p.stdinFD)
 	if err != nil {
@@ -65,42 +150,81 @@ func (p *ProcessTerminal) Start(onInput func(data string), onResize func()) erro
 	// Enable bracketed paste mode - terminal will wrap pastes in \x1b[200~ ... \x1b[201~
 	p.print("\x1b[?2004h")
 
-	// Query and enable Kitty keyboard protocol
-	p.queryAndEnableKittyProtocol()
+	// Probe capabilities, including the Kitty keyboard protocol
+	p.startProbe()
+	p.enableNotifications()
 
 	// Set up resize signal handling
 	p.stopResizeSignal = registerResizeSignal(p)
 	go p.handleResizeSignal()
 
 	// Start reading input in background
-	go p.readInputLoop()
+	if err := p.startReading(); err != nil {
+		_ = disableRawMode(p.rawState)
+		p.rawState = nil
+		return err
+	}
+
+	// Give the terminal a moment to answer so the first frame can use what
+	// it supports
+	p.waitProbe()
 	return nil
 }
 
-func (p *ProcessTerminal) readInputLoop() {
+// startReading starts the goroutine that reads stdin, unless it is running.
+func (p *ProcessTerminal) startReading() error {
+	p.readMu.Lock()
+	defer p.readMu.Unlock()
+	if p.readDone != nil {
+		return nil
+	}
+	if p.reader == nil {
+		reader, err := newStdinReader(p.stdinFD)
+		if err != nil {
+			return err
+		}
+		p.reader = reader
+	}
+	p.reader.drain()
+	p.readDone = make(chan struct{})
+	go p.readInputLoop(p.readDone)
+	return nil
+}
+
+// stopReading interrupts the pending read and waits for the reading goroutine
+// to exit, so that no input is consumed once it returns.
+func (p *ProcessTerminal) stopReading() {
+	p.readMu.Lock()
+	defer p.readMu.Unlock()
+	if p.readDone == nil {
+		return
+	}
+	if p.reader.Cancel() {
+		<-p.readDone
+	}
+	p.readDone = nil
+}
+
+func (p *ProcessTerminal) readInputLoop(done chan struct{}) {
+	defer close(done)
 	buf := make([]byte, 1024)
 	for {
-		select {
-		case <-p.stopChan:
+		n, err := p.reader.Read(buf)
+		if errors.Is(err, errReadCanceled) {
 			return
-		default:
-			// Set a read deadline to allow checking stopChan periodically
-			os.Stdin.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
-			n, err := os.Stdin.Read(buf)
-			if err != nil {
-				// Check if it's a timeout error (expected)
-				if os.IsTimeout(err) {
-					continue
-				}
-				// Other errors mean we should stop
-				return
+		}
+		if err != nil {
+			// EOF or a broken stdin: no more input will come
+			if p.lines != nil {
+				p.lines.Close()
 			}
-			if n > 0 {
-				data := make([]byte, n)
-				copy(data, buf[:n])
-				if p.stdinDataBuffer != nil {
-					p.stdinDataBuffer(data)
-				}
+			return
+		}
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			if p.stdinDataBuffer != nil {
+				p.stdinDataBuffer(data)
 			}
 		}
 	}
@@ -122,26 +246,18 @@ func (p *ProcessTerminal) handleResizeSignal() {
 // setupStdinBuffer sets up StdinBuffer to split batched input into individual sequences.
 // This ensures components receive single events, making matchesKey/isKeyRelease work correctly.
 //
-// Also watches for Kitty protocol response and enables it when detected.
+// Also consumes the replies to the capability probe, which enables the Kitty
+// protocol or modifyOtherKeys accordingly.
 func (p *ProcessTerminal) setupStdinBuffer() {
 	p.buffer = NewStdinBuffer()
 
 	// Forward individual sequences to the input handler
 	p.buffer.OnData = func(seq string) {
-		// Check for Kitty protocol response (only if not already enabled)
-		if !p.isKittyProtocolActive {
-			match := kittyResponsePattern.FindStringSubmatch(seq)
-			if len(match) > 1 {
-				p.isKittyProtocolActive = true
-
-				// Enable Kitty keyboard protocol (push flags)
-				// Flag 1 = disambiguate escape codes
-				// Flag 2 = report event types (press/repeat/release)
-				// Flag 4 = report alternate keys (shifted key, base layout key)
-				// Base layout key enables shortcuts to work with non-Latin keyboard layouts
-				p.print("\x1b[>7u")
-				return // Don't forward protocol response to TUI
-			}
+		if p.probing.Load() && p.handleProbeResponse(seq) {
+			return // Don't forward protocol responses to TUI
+		}
+		if p.colorSchemeUpdates && p.handleColorSchemeReport(seq) {
+			return
 		}
 		if p.inputHandler != nil {
 			p.inputHandler(seq)
@@ -163,30 +279,149 @@ func (p *ProcessTerminal) setupStdinBuffer() {
 	}
 }
 
-// queryAndEnableKittyProtocol queries terminal for Kitty keyboard protocol support and enables if available.
-//
-// Sends CSI ? u to query current flags. If terminal responds with CSI ? <flags> u,
-// it supports the protocol and we enable it with CSI > 7 u.
-//
-// The response is detected in setupStdinBuffer's data handler.
-func (p *ProcessTerminal) queryAndEnableKittyProtocol() {
-	p.setupStdinBuffer()
-	p.print("\x1b[?u")
+// enableKittyProtocol switches to the Kitty keyboard protocol after the
+// terminal answered CSI ? u with its current flags.
+func (p *ProcessTerminal) enableKittyProtocol() {
+	p.isKittyProtocolActive = true
+	keys.SetKittyProtocolActive(true)
+	p.enableKeyboardProtocol()
+}
+
+// enableNotifications turns on the terminal reports that arrive unrequested.
+func (p *ProcessTerminal) enableNotifications() {
+	if p.colorSchemeUpdates {
+		p.print("\x1b[?2031h")
+	}
+}
+
+// disableNotifications undoes enableNotifications.
+func (p *ProcessTerminal) disableNotifications() {
+	if p.colorSchemeUpdates {
+		p.print("\x1b[?2031l")
+	}
+}
+
+// enableKeyboardProtocol pushes the negotiated Kitty flags or sets
+// modifyOtherKeys mode 2.
+func (p *ProcessTerminal) enableKeyboardProtocol() {
+	if p.isKittyProtocolActive {
+		p.print("\x1b[>" + strconv.Itoa(int(p.kittyFlags)) + "u")
+	} else if p.modifyOtherKeysActive {
+		p.print("\x1b[>4;2m")
+	}
+}
+
+// disableKeyboardProtocol pops the Kitty flags or resets modifyOtherKeys.
+func (p *ProcessTerminal) disableKeyboardProtocol() {
+	if p.isKittyProtocolActive {
+		p.print("\x1b[<u")
+	} else if p.modifyOtherKeysActive {
+		p.print("\x1b[>4m")
+	}
+}
+
+// startPlain reads stdin line by line and skips every terminal mode switch.
+func (p *ProcessTerminal) startPlain() error {
+	p.lines = &lineSplitter{
+		emit: func(data string) {
+			if p.inputHandler != nil {
+				p.inputHandler(data)
+			}
+		},
+	}
+	p.stdinDataBuffer = p.lines.Process
+	return p.startReading()
+}
+
+// Suspend restores the terminal to its pre-Start state (cooked mode, no bracketed
+// paste, Kitty flags popped, cursor visible) and stops reading stdin until Resume.
+// Used for job control and when handing the TTY to a child process.
+func (p *ProcessTerminal) Suspend() error {
+	if p.suspended.Swap(true) {
+		return nil
+	}
+
+	// Stop reading so no keystrokes are stolen from the new owner
+	p.stopReading()
+
+	if p.plain {
+		return nil
+	}
+
+	p.print("\x1b[?2004l")
+	p.disableKeyboardProtocol()
+	p.disableNotifications()
+	p.print("\x1b[?25h")
+	p.print("\x1b[0m")
+
+	if p.rawState != nil {
+		return disableRawMode(p.rawState)
+	}
+	return nil
+}
+
+// Resume re-enables raw mode, bracketed paste and the Kitty flags after Suspend
+// and resumes reading stdin. The caller is responsible for redrawing the screen.
+func (p *ProcessTerminal) Resume() error {
+	if !p.suspended.Load() {
+		return nil
+	}
+
+	if !p.plain {
+		rawState, err := enableRawMode(p.stdinFD)
+		if err != nil {
+			return err
+		}
+		p.rawState = rawState
+
+		p.print("\x1b[?2004h")
+		p.enableKeyboardProtocol()
+		p.enableNotifications()
+	}
+
+	if err := p.startReading(); err != nil {
+		return err
+	}
+	p.suspended.Store(false)
+	return nil
 }
 
 func (p *ProcessTerminal) Stop() {
 	p.stopOnce.Do(func() {
 		// Signal goroutines to stop
 		close(p.stopChan)
+		p.stopReading()
+		if p.reader != nil {
+			p.reader.Close()
+		}
+
+		// Modes were already restored by Suspend
+		if p.suspended.Load() {
+			p.isKittyProtocolActive = false
+			p.modifyOtherKeysActive = false
+			keys.SetKittyProtocolActive(false)
+			p.rawState = nil
+		}
+
+		if p.plain {
+			p.stdinDataBuffer = nil
+			p.inputHandler = nil
+			p.resizeHandler = nil
+			return
+		}
 
 		// Disable bracketed paste mode
 		p.print("\x1b[?2004l")
 
-		// Disable Kitty keyboard protocol (pop the flags we pushed) - only if we enabled it
+		// Disable the Kitty keyboard protocol (pop the flags we pushed) or
+		// modifyOtherKeys - only if we enabled it
+		p.disableKeyboardProtocol()
+		p.disableNotifications()
 		if p.isKittyProtocolActive {
-			p.print("\x1b[<u")
-			p.isKittyProtocolActive = false
+			keys.SetKittyProtocolActive(false)
 		}
+		p.isKittyProtocolActive = false
+		p.modifyOtherKeysActive = false
 
 		// Show cursor (ensure it's visible after exit)
 		p.print("\x1b[?25h")
@@ -219,8 +454,6 @@ func (p *ProcessTerminal) Stop() {
 
 		// Restore terminal state
 		if p.rawState != nil {
-			// Clear any read deadline before restoring
-			os.Stdin.SetReadDeadline(time.Time{})
 			_ = disableRawMode(p.rawState)
 			p.rawState = nil
 		}
@@ -232,6 +465,9 @@ func (p *ProcessTerminal) Write(data string) {
 }
 
 func (p *ProcessTerminal) MoveBy(lines int) {
+	if p.plain {
+		return
+	}
 	if lines > 0 {
 		p.print("\x1b[" + strconv.Itoa(lines) + "B")
 	} else if lines < 0 {
@@ -242,26 +478,44 @@ func (p *ProcessTerminal) MoveBy(lines int) {
 }
 
 func (p *ProcessTerminal) HideCursor() {
+	if p.plain {
+		return
+	}
 	p.print("\x1b[?25l")
 }
 
 func (p *ProcessTerminal) ShowCursor() {
+	if p.plain {
+		return
+	}
 	p.print("\x1b[?25h")
 }
 
 func (p *ProcessTerminal) ClearLine() {
+	if p.plain {
+		return
+	}
 	p.print("\x1b[K")
 }
 
 func (p *ProcessTerminal) ClearFromCursor() {
+	if p.plain {
+		return
+	}
 	p.print("\x1b[J")
 }
 
 func (p *ProcessTerminal) ClearScreen() {
+	if p.plain {
+		return
+	}
 	p.print("\x1b[2J\x1b[H") // Clear screen and move to home (1,1)
 }
 
 func (p *ProcessTerminal) SetTitle(title string) {
+	if p.plain {
+		return
+	}
 	// OSC 0;title BEL - set terminal window title
 	p.print("\x1b]0;" + title + "\x07")
 }
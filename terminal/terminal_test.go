@@ -8,6 +8,9 @@ import (
 
 func TestProcessTerminal(t *testing.T) {
 	term := NewProcessTerminal()
+	if term.IsPlain() {
+		t.Skip("stdin/stdout is not an interactive terminal")
+	}
 
 	fmt.Println("=== ProcessTerminal Test ===")
 	fmt.Println("Press Ctrl+C to exit")
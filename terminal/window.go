@@ -46,3 +46,8 @@ func getTerminalSize(fd int) (width, height int, err error) {
 	height = int(info.Window.Bottom - info.Window.Top + 1)
 	return width, height, nil
 }
+
+func isTerminal(fd int) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(fd), &mode) == nil
+}
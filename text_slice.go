@@ -22,12 +22,14 @@ func SliceWithWidth(line string, startCol int, length int, strict bool) SliceRes
 	currentCol := 0
 	i := 0
 	pendingAnsi := ""
+	tracker := NewAnsiCodeTracker()
 
 	for i < len(line) {
 		code, codeLen, ok := ExtractAnsiCode(line, i)
 		if ok {
 			if currentCol >= startCol && currentCol < endCol {
 				result.WriteString(code)
+				tracker.Process(code)
 			} else if currentCol < startCol {
 				pendingAnsi += code
 			}
@@ -53,6 +55,7 @@ func SliceWithWidth(line string, startCol int, length int, strict bool) SliceRes
 			if inRange && fits {
 				if pendingAnsi != "" {
 					result.WriteString(pendingAnsi)
+					updateTrackerFromText(pendingAnsi, tracker)
 					pendingAnsi = ""
 				}
 				result.WriteString(grapheme)
@@ -69,6 +72,10 @@ func SliceWithWidth(line string, startCol int, length int, strict bool) SliceRes
 		}
 	}
 
+	// Close a hyperlink that continues past the slice.
+	if tracker.hyperlink != "" {
+		result.WriteString(HyperlinkClose)
+	}
 	return SliceResult{text: result.String(), width: resultWidth}
 }
 
@@ -81,6 +88,7 @@ func ExtractSegments(line string, beforeEnd int, afterStart int, afterLen int, s
 	pendingAnsiBefore := ""
 	afterStarted := false
 	afterEnd := afterStart + afterLen
+	beforeLink := false // before ends inside a hyperlink
 
 	tracker := NewAnsiCodeTracker()
 
@@ -118,6 +126,7 @@ func ExtractSegments(line string, beforeEnd int, afterStart int, afterLen int, s
 				}
 				before.WriteString(grapheme)
 				beforeWidth += w
+				beforeLink = tracker.hyperlink != ""
 			} else if currentCol >= afterStart && currentCol < afterEnd {
 				fits := !strictAfter || currentCol+w <= afterEnd
 				if fits {
@@ -154,5 +163,12 @@ func ExtractSegments(line string, beforeEnd int, afterStart int, afterLen int, s
 		}
 	}
 
+	// Close hyperlinks so they don't run into whatever is placed between or after.
+	if beforeLink {
+		before.WriteString(HyperlinkClose)
+	}
+	if afterStarted && tracker.hyperlink != "" {
+		after.WriteString(HyperlinkClose)
+	}
 	return before.String(), beforeWidth, after.String(), afterWidth
 }
\ No newline at end of file
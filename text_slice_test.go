@@ -163,3 +163,16 @@ func TestSliceByColumn_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestSliceByColumn_Hyperlinks(t *testing.T) {
+	line := "ab" + Hyperlink("cdef", "https://example.com") + "gh"
+	open := "\x1b]8;;https://example.com\x07"
+
+	assert.Equal(t, open+"cd"+HyperlinkClose, SliceByColumn(line, 2, 2, false))
+	assert.Equal(t, open+"ef"+HyperlinkClose+"g", SliceByColumn(line, 4, 3, false))
+	assert.Equal(t, "ab", SliceByColumn(line, 0, 2, false))
+
+	before, _, after, _ := ExtractSegments(line, 3, 5, 2, false)
+	assert.Equal(t, "ab"+open+"c"+HyperlinkClose, before)
+	assert.Equal(t, open+"f"+HyperlinkClose+"g", after)
+}
@@ -50,10 +50,12 @@ func TruncateToWidth(text string, maxWidth int, ellipsis string, pad bool) strin
 	b := acquireBuilder()
 	defer releaseBuilder(b)
 	currentWidth := 0
+	tracker := NewAnsiCodeTracker()
 
 	for _, seg := range segments {
 		if seg.segType == segmentTypeAnsi {
 			b.WriteString(seg.value)
+			tracker.Process(seg.value)
 			continue
 		}
 
@@ -72,7 +74,11 @@ func TruncateToWidth(text string, maxWidth int, ellipsis string, pad bool) strin
 		currentWidth += graphemeWidth
 	}
 
-	// Add reset code before ellipsis to prevent styling leaking into it
+	// Add reset code before ellipsis to prevent styling leaking into it,
+	// closing a hyperlink cut off mid-text as well
+	if tracker.hyperlink != "" {
+		b.WriteString(HyperlinkClose)
+	}
 	truncated := b.String() + "\x1b[0m" + ellipsis
 
 	if pad {
@@ -302,3 +302,11 @@ func BenchmarkTruncateToWidth_WithANSI(b *testing.B) {
 		TruncateToWidth(text, 30, "...", false)
 	}
 }
+
+func TestTruncateToWidth_ClosesHyperlink(t *testing.T) {
+	got := TruncateToWidth(Hyperlink("a long link", "https://example.com"), 6, "...", false)
+	want := "\x1b]8;;https://example.com\x07a l" + HyperlinkClose + "\x1b[0m..."
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
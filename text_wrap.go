@@ -112,6 +112,7 @@ func splitPureASCII(s string) []string {
 
 // WrapAnsiText wraps text to the given width,
 //  preserving ANSI escape codes (colors, bold, etc.).
+// OSC 8 hyperlinks are closed at each line break and reopened on the next line.
 func WrapAnsiText(text string, width int) []string {
 	if text == "" {
 		return []string{""}
@@ -129,6 +130,10 @@ func WrapAnsiText(text string, width int) []string {
 		wrapped := wrapSingleLine(prefix+inputLine, width)
 		result = append(result, wrapped...)
 		updateTrackerFromText(inputLine, tracker)
+		// Close a hyperlink that continues on the next line; the prefix reopens it.
+		if tracker.hyperlink != "" {
+			result[len(result)-1] += HyperlinkClose
+		}
 	}
 
 	if len(result) == 0 {
@@ -35,3 +35,32 @@ func TestSplitIntoTokensWithAnsi_PreservesUTF8(t *testing.T) {
 		t.Fatalf("token = %q want %q", toks[0], s)
 	}
 }
+
+func TestWrapAnsiText_ReopensHyperlinks(t *testing.T) {
+	open := "\x1b]8;;https://example.com\x07"
+	lines := WrapAnsiText("see "+open+"\x1b[4mone two three\x1b[24m"+HyperlinkClose+" done", 9)
+	want := []string{
+		"see " + open + "\x1b[4mone\x1b[24m" + HyperlinkClose,
+		"\x1b[4m" + open + "two three\x1b[24m" + HyperlinkClose,
+		"done",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got  %q\nwant %q", lines, want)
+	}
+
+	lines = WrapAnsiText(open+"a\nb"+HyperlinkClose, 80)
+	if lines[0] != open+"a"+HyperlinkClose || lines[1] != open+"b"+HyperlinkClose {
+		t.Fatalf("link across newline: %q", lines)
+	}
+}
+
+func TestWrapAnsiText_RestoresExtendedUnderline(t *testing.T) {
+	lines := WrapAnsiText("\x1b[4:3;58;5;196mteh quikc\x1b[24;59m", 5)
+	want := []string{
+		"\x1b[4:3;58;5;196mteh\x1b[24m",
+		"\x1b[4:3;58;5;196mquikc\x1b[24;59m",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got  %q\nwant %q", lines, want)
+	}
+}
@@ -1,13 +1,18 @@
 package fasttui
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	"github.com/yeeaiclub/fasttui/keys"
+	"github.com/yeeaiclub/fasttui/style"
 )
 
 var (
@@ -18,6 +23,13 @@ var (
 	cellSizePartialPattern  = regexp.MustCompile(`\x1b(\[6?;?[\d;]*)?$`)
 )
 
+// ErrSuspendUnsupported is returned by Suspend and ReleaseTerminal when the terminal
+// does not implement SuspendableTerminal or the platform has no job control.
+var ErrSuspendUnsupported = errors.New("fasttui: suspend not supported")
+
+// ErrTerminalReleased is returned by ReleaseTerminal when the terminal is already released.
+var ErrTerminalReleased = errors.New("fasttui: terminal already released")
+
 type TUI struct {
 	Container
 	stopped  atomic.Bool
@@ -43,26 +55,122 @@ type TUI struct {
 	inputBuffer          strings.Builder
 
 	clearOnShrink bool
+	noSyncOutput  bool // terminal reported no support for synchronized output
+
+	onColorScheme func(themeName string) // called when the terminal switches light/dark
+	theme         *style.Theme           // last theme applied with SetTheme
+
+	plainMode      bool // append-only output for pipes and dumb terminals
+	plainKeepAnsi  bool // keep SGR codes in plain output instead of stripping them
+	plainCommitted int  // number of lines already written in plain mode
+
+	suspendKey     bool        // handle the suspend keybinding (ctrl+z) before the focused component
+	suspended      atomic.Bool // terminal is released to the shell or a child process
+	renderMu       sync.Mutex  // serializes rendering with releasing the terminal
+	jobSignals     chan os.Signal
+	stopJobSignals func()
+
+	repanic      *bool                      // re-panic after restoring the terminal; nil picks by Run/Start
+	running      bool                       // started by Run, which returns the panic instead
+	crashErr     atomic.Pointer[PanicError] // panic recovered on the event loop
+	lastFrame    []string                   // most recent frame, for crash reports
+	crashInput   bool                       // keep recentInputs
+	recentInputs []string                   // last inputs handled, redacted, for crash reports
 
 	eventLoopDone chan struct{}
+	finished      chan struct{} // closed once the terminal is restored
+}
+
+// TUIOption configures a TUI.
+type TUIOption func(*TUI)
+
+// WithPlainMode forces plain output on or off. By default it follows the terminal's
+// PlainTerminal implementation, if any.
+func WithPlainMode(enabled bool) TUIOption {
+	return func(t *TUI) {
+		t.plainMode = enabled
+	}
+}
+
+// WithPlainKeepAnsi keeps ANSI styling in plain output instead of stripping it.
+func WithPlainKeepAnsi(keep bool) TUIOption {
+	return func(t *TUI) {
+		t.plainKeepAnsi = keep
+	}
+}
+
+// WithSuspendKey makes the TUI handle the suspend keybinding (ctrl+z by default)
+// by calling Suspend, before the focused component sees the input.
+func WithSuspendKey(enabled bool) TUIOption {
+	return func(t *TUI) {
+		t.suspendKey = enabled
+	}
+}
+
+// WithColorSchemeChange registers fn to run on the event loop when the terminal
+// reports a light/dark colour scheme change, with the theme name
+// style.DefaultThemeName now picks. Apply the theme there; the TUI then
+// invalidates all components and redraws.
+func WithColorSchemeChange(fn func(themeName string)) TUIOption {
+	return func(t *TUI) {
+		t.onColorScheme = fn
+	}
+}
+
+// WithRepanic controls whether the TUI re-panics after a panic on the event
+// loop has been recovered, the terminal restored and the crash report written.
+// By default a TUI started with Start re-panics, so the program crashes as it
+// would without recovery, while Run returns the panic as a *PanicError.
+func WithRepanic(enabled bool) TUIOption {
+	return func(t *TUI) {
+		t.repanic = &enabled
+	}
 }
 
-func NewTUI(terminal Terminal, showHardwareCursor bool) *TUI {
+// WithCrashReportInput controls whether the last inputs are kept for crash
+// reports. Only key names are kept; typed text and pastes are reduced to
+// their length. On by default; turn it off when even that may reveal too much.
+func WithCrashReportInput(enabled bool) TUIOption {
+	return func(t *TUI) {
+		t.crashInput = enabled
+	}
+}
+
+func NewTUI(terminal Terminal, showHardwareCursor bool, opts ...TUIOption) *TUI {
 	t := &TUI{
 		eventChan:          make(chan tuiEvent, 128),
 		stopChan:           make(chan struct{}),
 		terminal:           terminal,
 		showHardwareCursor: showHardwareCursor,
 		previousLines:      nil,
+		crashInput:         true,
+	}
+	if pt, ok := terminal.(PlainTerminal); ok {
+		t.plainMode = pt.IsPlain()
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(t)
+		}
 	}
 	t.eventLoopDone = make(chan struct{})
 	close(t.eventLoopDone)
+	t.finished = make(chan struct{})
+	close(t.finished)
 	return t
 }
 
+// IsPlain reports whether the TUI renders in plain append-only mode.
+// Components use it to skip animations such as spinners.
+func (t *TUI) IsPlain() bool {
+	return t.plainMode
+}
+
 func (t *TUI) Start() {
 	t.eventLoopDone = make(chan struct{})
+	t.finished = make(chan struct{})
 	t.start()
+	t.stopJobSignals = t.watchJobSignals()
 	go t.eventLoop()
 }
 
@@ -72,7 +180,36 @@ func (t *TUI) Stop() {
 	}
 	close(t.stopChan)
 	<-t.eventLoopDone
+	if t.plainMode && t.crashErr.Load() == nil {
+		t.renderPlain(true)
+	}
+	t.restoreTerminal()
+}
+
+// Run starts the TUI and blocks until it is stopped. It returns a *PanicError when
+// a component panicked on the event loop; the terminal is restored in both cases.
+func (t *TUI) Run() error {
+	t.running = true
+	t.Start()
+	<-t.finished
+	return t.Err()
+}
+
+// Err returns the panic recovered on the event loop, if any.
+func (t *TUI) Err() error {
+	if err := t.crashErr.Load(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *TUI) restoreTerminal() {
+	if t.stopJobSignals != nil {
+		t.stopJobSignals()
+		t.stopJobSignals = nil
+	}
 	t.terminal.Stop()
+	close(t.finished)
 }
 
 func (t *TUI) TriggerRender() {
@@ -93,6 +230,7 @@ func (t *TUI) ForceRender() {
 
 func (t *TUI) eventLoop() {
 	defer close(t.eventLoopDone)
+	defer t.recoverPanic()
 
 	pendingRender := false
 	forceRender := false
@@ -119,6 +257,9 @@ func (t *TUI) eventLoop() {
 				pendingRender = true
 			case eventQuery:
 				t.handleQueryRequest(ev)
+			case eventCall:
+				ev.fn()
+				pendingRender = true
 			}
 		}
 
@@ -147,7 +288,15 @@ func (t *TUI) forceRender() {
 }
 
 func (t *TUI) doRender() {
-	if t.stopped.Load() {
+	t.renderMu.Lock()
+	defer t.renderMu.Unlock()
+
+	if t.stopped.Load() || t.suspended.Load() {
+		return
+	}
+
+	if t.plainMode {
+		t.renderPlain(false)
 		return
 	}
 
@@ -165,6 +314,7 @@ func (t *TUI) doRender() {
 
 	newLines := t.renderComponent(width)
 	row, col := extractCursorPosition(newLines, height)
+	t.lastFrame = newLines
 
 	newLines = appendSegmentResetCodes(newLines)
 	fullRender := t.getFullRender(newLines, height, row, col, width)
@@ -272,7 +422,7 @@ func (t *TUI) renderChangedLines(width, height, firstChangedIdx, lastChangedIdx
 	// Render from first changed line to end
 	// Build buffer with all updates wrapped in synchronized output
 	var buffer strings.Builder
-	buffer.WriteString(SyncOutputBegin) // Begin synchronized output
+	buffer.WriteString(t.syncOutputBegin()) // Begin synchronized output
 
 	// Calculate the bottom row index of the previous viewport
 	// Used to determine if scrolling is needed when moving to a target row
@@ -331,11 +481,8 @@ func (t *TUI) renderChangedLines(width, height, firstChangedIdx, lastChangedIdx
 
 		line := newLines[i]
 		if !containsImage(line) && VisibleWidth(line) > width {
-			LogCrashInfo(width, i, line, newLines)
-			crashLogPath := GetCrashLogPath()
-
-			t.Stop()
-			panic(BuildWidthExceedErrorMsg(i, VisibleWidth(line), width, crashLogPath))
+			// Recovered by the event loop, which restores the terminal and writes the crash report
+			panic(BuildWidthExceedErrorMsg(i, VisibleWidth(line), width, GetCrashLogPath()))
 		}
 		buffer.WriteString(line)
 	}
@@ -365,7 +512,7 @@ func (t *TUI) renderChangedLines(width, height, firstChangedIdx, lastChangedIdx
 		buffer.WriteString("A")
 	}
 
-	buffer.WriteString(SyncOutputEnd) // End synchronized output
+	buffer.WriteString(t.syncOutputEnd()) // End synchronized output
 
 	// Write entire buffer at once
 	t.terminal.Write(buffer.String())
@@ -375,7 +522,7 @@ func (t *TUI) renderChangedLines(width, height, firstChangedIdx, lastChangedIdx
 // clearTrailingLines clears extra lines in terminal to prevent content scrolling
 func (t *TUI) clearTrailingLines(cursorOffset int, extraLines int, height int, fullRender FullRenderer) bool {
 	var buffer strings.Builder
-	buffer.WriteString(SyncOutputBegin)
+	buffer.WriteString(t.syncOutputBegin())
 
 	// Move to end of new content (clamp to 0 for empty content)
 	if cursorOffset > 0 {
@@ -410,11 +557,51 @@ func (t *TUI) clearTrailingLines(cursorOffset int, extraLines int, height int, f
 		buffer.WriteString("A")
 	}
 
-	buffer.WriteString(SyncOutputEnd)
+	buffer.WriteString(t.syncOutputEnd())
 	t.terminal.Write(buffer.String())
 	return false
 }
 
+// renderPlain writes committed lines without any cursor movement. A line is committed
+// once a later frame changes only lines below it; the final frame is flushed on Stop.
+// Committed lines are never rewritten, so content that changes above them is dropped.
+func (t *TUI) renderPlain(final bool) {
+	width, height := t.terminal.GetSize()
+	newLines := t.renderComponent(width)
+	extractCursorPosition(newLines, height)
+
+	commitEnd := len(newLines)
+	if !final {
+		if t.previousLines == nil {
+			t.previousLines = newLines
+			return
+		}
+		firstChangedIdx, _ := findChangedLineRange(t.previousLines, newLines)
+		if firstChangedIdx == -1 {
+			return
+		}
+		commitEnd = min(firstChangedIdx, len(newLines))
+	}
+	t.previousLines = newLines
+
+	if commitEnd <= t.plainCommitted {
+		return
+	}
+
+	var buffer strings.Builder
+	for _, line := range newLines[t.plainCommitted:commitEnd] {
+		if t.plainKeepAnsi {
+			buffer.WriteString(line)
+			buffer.WriteString("\x1b[0m")
+		} else {
+			buffer.WriteString(strings.TrimRight(StripAnsi(line), " "))
+		}
+		buffer.WriteString("\n")
+	}
+	t.terminal.Write(buffer.String())
+	t.plainCommitted = commitEnd
+}
+
 func (t *TUI) renderComponent(width int) []string {
 	newLines := t.Render(width)
 	return newLines
@@ -431,12 +618,12 @@ type FullRenderer struct {
 
 func (f FullRenderer) Render(clear bool) {
 	var buffer strings.Builder
-	buffer.WriteString(SyncOutputBegin) // Begin synchronized output
+	buffer.WriteString(f.tui.syncOutputBegin()) // Begin synchronized output
 	if clear {
 		buffer.WriteString("\x1b[3J\x1b[2J\x1b[H") // Clear scrollback, screen, and home
 	}
 	buffer.WriteString(strings.Join(f.newLines, "\r\n"))
-	buffer.WriteString(SyncOutputEnd) // End synchronized output
+	buffer.WriteString(f.tui.syncOutputEnd()) // End synchronized output
 	f.tui.terminal.Write(buffer.String())
 
 	f.tui.cursorRow = max(0, len(f.newLines)-1)
@@ -469,7 +656,7 @@ func (t *TUI) getFullRender(newLines []string, height int, row int, col int, wid
 
 func (t *TUI) start() error {
 	t.stopped.Store(false)
-	return t.terminal.Start(
+	err := t.terminal.Start(
 		func(data string) {
 			t.HandleInput(data)
 		},
@@ -477,6 +664,126 @@ func (t *TUI) start() error {
 			t.TriggerRender()
 		},
 	)
+	if ct, ok := t.terminal.(CapabilityTerminal); ok {
+		t.noSyncOutput = !ct.SupportsSynchronizedOutput()
+	}
+	if ct, ok := t.terminal.(ColorSchemeTerminal); ok {
+		ct.OnColorSchemeChange(func(string) {
+			t.Post(t.applyColorScheme)
+		})
+	}
+	return err
+}
+
+// applyColorScheme reacts to a light/dark switch of the terminal. Without a
+// WithColorSchemeChange handler, a theme set with SetTheme is replaced by the
+// builtin light or dark theme in the same color mode and symbol preset.
+func (t *TUI) applyColorScheme() {
+	name := style.DefaultThemeName()
+	switch {
+	case t.onColorScheme != nil:
+		t.onColorScheme(name)
+	case t.theme != nil:
+		theme, err := style.LoadTheme(name, style.WithColorMode(t.theme.ColorMode()), style.WithSymbolPreset(t.theme.SymbolPreset()))
+		if err == nil {
+			t.applyTheme(theme)
+			return
+		}
+	}
+	t.Invalidate()
+}
+
+// SetTheme applies theme to every ThemeAware component in the tree, invalidates
+// all components and redraws the whole screen. Safe to call from any goroutine.
+func (t *TUI) SetTheme(theme *style.Theme) {
+	if theme == nil {
+		return
+	}
+	t.Post(func() { t.applyTheme(theme) })
+}
+
+// Theme returns the theme last applied with SetTheme, or nil.
+func (t *TUI) Theme() *style.Theme {
+	respChan := make(chan any, 1)
+	select {
+	case t.eventChan <- tuiEvent{kind: eventQuery, data: "getTheme", response: respChan}:
+		theme, _ := (<-respChan).(*style.Theme)
+		return theme
+	case <-t.stopChan:
+		return t.theme
+	}
+}
+
+// Frame returns the most recently rendered lines, for example to pass to
+// ScreenshotHTML or ScreenshotSVG.
+func (t *TUI) Frame() []string {
+	respChan := make(chan any, 1)
+	select {
+	case t.eventChan <- tuiEvent{kind: eventQuery, data: "getFrame", response: respChan}:
+		frame, _ := (<-respChan).([]string)
+		return frame
+	case <-t.stopChan:
+		return slices.Clone(t.lastFrame)
+	}
+}
+
+func (t *TUI) applyTheme(theme *style.Theme) {
+	t.theme = theme
+	for _, child := range t.GetChildren() {
+		setComponentTheme(child, theme)
+	}
+	t.Invalidate()
+	t.forceRender()
+}
+
+// setComponentTheme applies theme to c and, for containers, to its children.
+func setComponentTheme(c Component, theme *style.Theme) {
+	if ta, ok := c.(ThemeAware); ok {
+		ta.SetTheme(theme)
+	}
+	if parent, ok := c.(interface{ GetChildren() []Component }); ok {
+		for _, child := range parent.GetChildren() {
+			setComponentTheme(child, theme)
+		}
+	}
+}
+
+// WatchTheme reloads the named custom theme from style.ThemesDir whenever its
+// file changes and applies it with SetTheme, for theme development. Load
+// errors are passed to onError, which may be nil, and keep the current theme.
+// Call the returned function to stop watching.
+func (t *TUI) WatchTheme(name string, onError func(error), opts ...style.ThemeOption) (stop func()) {
+	return style.WatchThemesDir(style.DefaultThemeWatchInterval, func(changed string) {
+		if changed != name {
+			return
+		}
+		theme, err := style.LoadTheme(name, opts...)
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			return
+		}
+		t.SetTheme(theme)
+	})
+}
+
+// syncOutputBegin returns SyncOutputBegin, or "" when the terminal doesn't
+// support synchronized output.
+func (t *TUI) syncOutputBegin() string {
+	if t.noSyncOutput {
+		return ""
+	}
+	return SyncOutputBegin
+}
+
+// syncOutputEnd returns SyncOutputEnd, or "" when the terminal doesn't
+// support synchronized output.
+func (t *TUI) syncOutputEnd() string {
+	if t.noSyncOutput {
+		return ""
+	}
+	return SyncOutputEnd
 }
 
 func (t *TUI) handleQueryRequest(ev tuiEvent) {
@@ -485,6 +792,10 @@ func (t *TUI) handleQueryRequest(ev tuiEvent) {
 		ev.response <- t.showHardwareCursor
 	case ev.data == "getFullRedraws":
 		ev.response <- t.fullRedrawCount
+	case ev.data == "getTheme":
+		ev.response <- t.theme
+	case ev.data == "getFrame":
+		ev.response <- slices.Clone(t.lastFrame)
 	case ev.data == "queryCellSize":
 		t.cellSizeQueryPending = true
 		t.terminal.Write("\x1b[16t")
@@ -529,6 +840,18 @@ func (t *TUI) setFocus(component Component) {
 	}
 }
 
+// Post runs fn on the event loop goroutine and re-renders afterwards. Background
+// work (async autocomplete, network results) uses it to update components safely.
+func (t *TUI) Post(fn func()) {
+	if fn == nil {
+		return
+	}
+	select {
+	case t.eventChan <- tuiEvent{kind: eventCall, fn: fn}:
+	case <-t.stopChan:
+	}
+}
+
 func (t *TUI) HandleInput(data string) {
 	select {
 	case t.eventChan <- tuiEvent{kind: eventInput, data: data}:
@@ -537,6 +860,13 @@ func (t *TUI) HandleInput(data string) {
 }
 
 func (t *TUI) handleInput(data string) {
+	t.recordInput(data)
+
+	if t.suspendKey && keys.GetEditorKeybindings().Matches(data, keys.EditorActionSuspend) {
+		_ = t.Suspend()
+		return
+	}
+
 	if t.cellSizeQueryPending {
 		t.inputBuffer.WriteString(data)
 		filtered := t.parseCellSizeResponse()
@@ -554,6 +884,43 @@ func (t *TUI) handleInput(data string) {
 	}
 }
 
+// Suspend hands the terminal back to the shell and stops the process with SIGTSTP,
+// as the shell would on ctrl+z. Once the process is continued (SIGCONT) the terminal
+// modes are re-enabled and the screen is fully redrawn.
+func (t *TUI) Suspend() error {
+	return t.ReleaseTerminal(t.stopProcess)
+}
+
+// ReleaseTerminal restores the terminal to cooked mode, runs fn, then reclaims the
+// terminal and forces a full redraw. Use it to hand the TTY to a child process such
+// as an editor or pager. Rendering is paused while fn runs.
+func (t *TUI) ReleaseTerminal(fn func() error) error {
+	st, ok := t.terminal.(SuspendableTerminal)
+	if !ok {
+		return ErrSuspendUnsupported
+	}
+
+	t.renderMu.Lock()
+	if !t.suspended.CompareAndSwap(false, true) {
+		t.renderMu.Unlock()
+		return ErrTerminalReleased
+	}
+	err := st.Suspend()
+	t.renderMu.Unlock()
+
+	if err == nil && fn != nil {
+		err = fn()
+	}
+
+	resumeErr := st.Resume()
+	t.suspended.Store(false)
+	t.ForceRender()
+	if err != nil {
+		return err
+	}
+	return resumeErr
+}
+
 func (t *TUI) parseCellSizeResponse() string {
 	data := t.inputBuffer.String()
 
@@ -601,7 +968,7 @@ func (t *TUI) setShowHardwareCursor(enabled bool) {
 		return
 	}
 	t.showHardwareCursor = enabled
-	if !enabled {
+	if !enabled && !t.plainMode {
 		t.terminal.HideCursor()
 	}
 }
@@ -613,6 +980,19 @@ func (t *TUI) SetClearOnShrink(enabled bool) {
 	}
 }
 
+// KeyboardProtocol reports the keyboard encoding the terminal negotiated.
+// Terminals that don't implement KeyboardTerminal report the Kitty protocol
+// with the default flags when it is active, legacy encoding otherwise.
+func (t *TUI) KeyboardProtocol() keys.KeyboardProtocol {
+	if kt, ok := t.terminal.(KeyboardTerminal); ok {
+		return kt.KeyboardProtocol()
+	}
+	if t.terminal.IsKittyProtocolActive() {
+		return keys.KeyboardProtocol{Encoding: keys.EncodingKitty, KittyFlags: keys.DefaultKittyFlags}
+	}
+	return keys.KeyboardProtocol{Encoding: keys.EncodingLegacy}
+}
+
 func (t *TUI) QueryCellSize() {
 	if !t.terminal.IsKittyProtocolActive() {
 		return
@@ -0,0 +1,126 @@
+package fasttui
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yeeaiclub/fasttui/style"
+)
+
+type noSyncTerminal struct {
+	recordingTerminal
+}
+
+func (n *noSyncTerminal) SupportsSynchronizedOutput() bool { return false }
+
+func TestRenderSkipsUnsupportedSynchronizedOutput(t *testing.T) {
+	term := &noSyncTerminal{}
+	tui := NewTUI(term, false)
+	tui.AddChild(&concurrencyLineComponent{lines: []string{"test"}})
+	tui.Start()
+	defer tui.Stop()
+
+	time.Sleep(15 * time.Millisecond)
+	tui.ForceRender()
+	time.Sleep(15 * time.Millisecond)
+
+	out := term.String()
+	if !strings.Contains(out, "test") || strings.Contains(out, SyncOutputBegin) {
+		t.Fatalf("expected output without synchronized output markers, got %q", out)
+	}
+}
+
+type schemeTerminal struct {
+	recordingTerminal
+	onChange func(background string)
+}
+
+func (s *schemeTerminal) OnColorSchemeChange(fn func(background string)) { s.onChange = fn }
+
+type invalidationCounter struct {
+	concurrencyLineComponent
+	invalidations atomic.Int32
+}
+
+func (c *invalidationCounter) Invalidate() { c.invalidations.Add(1) }
+
+func TestColorSchemeChangeInvalidatesComponents(t *testing.T) {
+	t.Cleanup(func() { style.SetTerminalBackground("") })
+	term := &schemeTerminal{}
+	themes := make(chan string, 1)
+	tui := NewTUI(term, false, WithColorSchemeChange(func(name string) { themes <- name }))
+	comp := &invalidationCounter{concurrencyLineComponent: concurrencyLineComponent{lines: []string{"x"}}}
+	tui.AddChild(comp)
+	tui.Start()
+	defer tui.Stop()
+
+	style.SetTerminalBackground("light")
+	term.onChange("light")
+	select {
+	case name := <-themes:
+		if name != "light" {
+			t.Fatalf("theme = %q", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("colour scheme change was not delivered")
+	}
+	time.Sleep(15 * time.Millisecond)
+	if comp.invalidations.Load() == 0 {
+		t.Fatal("components should be invalidated")
+	}
+}
+
+type themedComponent struct {
+	concurrencyLineComponent
+	theme atomic.Pointer[style.Theme]
+}
+
+func (c *themedComponent) SetTheme(theme *style.Theme) { c.theme.Store(theme) }
+
+func TestSetThemeReachesNestedComponents(t *testing.T) {
+	term := &recordingTerminal{}
+	tui := NewTUI(term, false)
+	nested := &themedComponent{concurrencyLineComponent: concurrencyLineComponent{lines: []string{"x"}}}
+	group := &Container{}
+	group.AddChild(nested)
+	tui.AddChild(group)
+	tui.Start()
+	defer tui.Stop()
+
+	theme, err := style.LoadTheme("light", style.WithColorMode(style.ColorModeTruecolor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tui.SetTheme(theme)
+	if tui.Theme() != theme || nested.theme.Load() != theme {
+		t.Fatal("theme was not applied to the nested component")
+	}
+	if !strings.Contains(term.String(), "\x1b[2J\x1b[H") {
+		t.Fatal("SetTheme should redraw the whole screen")
+	}
+}
+
+func TestColorSchemeChangeSwapsTheme(t *testing.T) {
+	t.Cleanup(func() { style.SetTerminalBackground("") })
+	term := &schemeTerminal{}
+	tui := NewTUI(term, false)
+	comp := &themedComponent{concurrencyLineComponent: concurrencyLineComponent{lines: []string{"x"}}}
+	tui.AddChild(comp)
+	tui.Start()
+	defer tui.Stop()
+
+	dark, _ := style.LoadTheme("dark", style.WithColorMode(style.ColorModeTruecolor))
+	light, _ := style.LoadTheme("light", style.WithColorMode(style.ColorModeTruecolor))
+	tui.SetTheme(dark)
+
+	style.SetTerminalBackground("light")
+	term.onChange("light")
+	if got := tui.Theme(); got == nil || got.FgANSI(style.ColorAccent) != light.FgANSI(style.ColorAccent) {
+		t.Fatal("light scheme should switch to the light theme")
+	}
+	if comp.theme.Load() != tui.Theme() {
+		t.Fatal("components should receive the switched theme")
+	}
+}
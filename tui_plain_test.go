@@ -0,0 +1,74 @@
+package fasttui
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type plainLogComponent struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *plainLogComponent) Render(width int) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.lines...)
+}
+func (c *plainLogComponent) HandleInput(string)    {}
+func (c *plainLogComponent) WantsKeyRelease() bool { return false }
+func (c *plainLogComponent) Invalidate()           {}
+
+func (c *plainLogComponent) set(lines ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = lines
+}
+
+// TestPlainModeWritesOnlyCommittedLines verifies that plain mode appends lines once
+// they stop changing, strips ANSI and never emits cursor movement.
+func TestPlainModeWritesOnlyCommittedLines(t *testing.T) {
+	term := &recordingTerminal{}
+	tui := NewTUI(term, true, WithPlainMode(true))
+	log := &plainLogComponent{}
+	log.set("\x1b[1mfirst\x1b[0m   ", "working 1")
+	tui.AddChild(log)
+	tui.Start()
+
+	time.Sleep(15 * time.Millisecond)
+	log.set("\x1b[1mfirst\x1b[0m   ", "working 2")
+	tui.TriggerRender()
+	time.Sleep(15 * time.Millisecond)
+
+	if got := term.String(); got != "first\n" {
+		t.Fatalf("expected only the committed first line, got %q", got)
+	}
+
+	log.set("\x1b[1mfirst\x1b[0m   ", "second", "done")
+	tui.TriggerRender()
+	time.Sleep(15 * time.Millisecond)
+	tui.Stop()
+
+	got := term.String()
+	if got != "first\nsecond\ndone\n" {
+		t.Fatalf("unexpected plain output %q", got)
+	}
+	if strings.Contains(got, "\x1b") {
+		t.Fatalf("plain output should not contain escape sequences: %q", got)
+	}
+}
+
+func TestPlainModeKeepAnsi(t *testing.T) {
+	term := &recordingTerminal{}
+	tui := NewTUI(term, false, WithPlainMode(true), WithPlainKeepAnsi(true))
+	tui.AddChild(&plainLogComponent{lines: []string{"\x1b[31mred\x1b[39m"}})
+	tui.Start()
+	time.Sleep(15 * time.Millisecond)
+	tui.Stop()
+
+	if got := term.String(); got != "\x1b[31mred\x1b[39m\x1b[0m\n" {
+		t.Fatalf("expected styled line with reset, got %q", got)
+	}
+}
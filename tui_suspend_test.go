@@ -0,0 +1,70 @@
+package fasttui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type suspendableTerminal struct {
+	recordingTerminal
+}
+
+func (s *suspendableTerminal) Suspend() error {
+	s.Write("<suspend>")
+	return nil
+}
+
+func (s *suspendableTerminal) Resume() error {
+	s.Write("<resume>")
+	return nil
+}
+
+// TestReleaseTerminalPausesRenderingAndRedraws verifies that nothing is drawn while
+// the terminal belongs to someone else and that the screen is repainted afterwards.
+func TestReleaseTerminalPausesRenderingAndRedraws(t *testing.T) {
+	term := &suspendableTerminal{}
+	tui := NewTUI(term, false)
+	log := &plainLogComponent{lines: []string{"before"}}
+	tui.AddChild(log)
+	tui.Start()
+	defer tui.Stop()
+	time.Sleep(15 * time.Millisecond)
+
+	err := tui.ReleaseTerminal(func() error {
+		log.set("after")
+		tui.TriggerRender()
+		time.Sleep(15 * time.Millisecond)
+		if strings.Contains(term.String(), "after") {
+			t.Errorf("rendered while the terminal was released: %q", term.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReleaseTerminal: %v", err)
+	}
+	time.Sleep(15 * time.Millisecond)
+
+	out := term.String()
+	resume := strings.Index(out, "<resume>")
+	if strings.Index(out, "<suspend>") == -1 || resume == -1 {
+		t.Fatalf("expected suspend and resume calls, got %q", out)
+	}
+	tail := out[resume:]
+	if !strings.Contains(tail, "\x1b[2J\x1b[H") || !strings.Contains(tail, "after") {
+		t.Fatalf("expected a full redraw after resume, got %q", tail)
+	}
+}
+
+func TestReleaseTerminalUnsupported(t *testing.T) {
+	tui := NewTUI(&recordingTerminal{}, false)
+	called := false
+	err := tui.ReleaseTerminal(func() error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrSuspendUnsupported) || called {
+		t.Fatalf("expected ErrSuspendUnsupported without running fn, got %v (called=%v)", err, called)
+	}
+}
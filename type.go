@@ -1,5 +1,10 @@
 package fasttui
 
+import (
+	"github.com/yeeaiclub/fasttui/keys"
+	"github.com/yeeaiclub/fasttui/style"
+)
+
 // Component: render + keyboard input.
 type Component interface {
 	// Render returns terminal lines for the given width.
@@ -27,6 +32,47 @@ type Terminal interface {
 	SetTitle(title string)
 }
 
+// PlainTerminal is optionally implemented by terminals that cannot address the cursor
+// (pipes, CI logs, TERM=dumb). When IsPlain reports true the TUI only appends committed lines.
+type PlainTerminal interface {
+	IsPlain() bool
+}
+
+// SuspendableTerminal is optionally implemented by terminals that can hand the TTY
+// back to the shell or a child process and reclaim it later.
+type SuspendableTerminal interface {
+	// Suspend restores cooked mode and stops reading input.
+	Suspend() error
+	// Resume re-enables raw mode and input after Suspend.
+	Resume() error
+}
+
+// KeyboardTerminal is optionally implemented by terminals that negotiate an
+// enhanced keyboard protocol (Kitty or xterm modifyOtherKeys).
+type KeyboardTerminal interface {
+	KeyboardProtocol() keys.KeyboardProtocol
+}
+
+// CapabilityTerminal is optionally implemented by terminals that probe the
+// emulator's features at Start. The TUI leaves out sequences it doesn't support.
+type CapabilityTerminal interface {
+	// SupportsSynchronizedOutput reports whether DEC mode 2026 may be used.
+	SupportsSynchronizedOutput() bool
+}
+
+// ColorSchemeTerminal is optionally implemented by terminals that report light/dark
+// colour scheme changes (DEC mode 2031).
+type ColorSchemeTerminal interface {
+	// OnColorSchemeChange registers fn to run with "dark" or "light" on each change.
+	OnColorSchemeChange(fn func(background string))
+}
+
+// ThemeAware is implemented by components that derive their styles from a theme.
+// TUI.SetTheme calls SetTheme on every ThemeAware component in the tree.
+type ThemeAware interface {
+	SetTheme(theme *style.Theme)
+}
+
 type Focusable interface {
 	Component
 	SetFocused(bool)
@@ -41,6 +87,7 @@ const (
 	eventInput
 	eventFocus
 	eventQuery
+	eventCall
 )
 
 type tuiEvent struct {
@@ -48,4 +95,5 @@ type tuiEvent struct {
 	data      string
 	component Component
 	response  chan any
+	fn        func()
 }